@@ -0,0 +1,33 @@
+// Package keygen generates the BLS keypairs builders and relays sign with.
+package keygen
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+)
+
+// Keypair is 0x prefixed hex.
+type Keypair struct {
+	SecretKey string
+	PublicKey string
+}
+
+// Generate returns a new random keypair.
+func Generate() (*Keypair, error) {
+	sk, pk, err := bls.GenerateNewKeypair()
+	if err != nil {
+		return nil, err
+	}
+
+	skBytes := sk.Bytes()
+	pkBytes := pk.Bytes()
+
+	// Make sure the secret key can be read back
+	if _, err := bls.SecretKeyFromBytes(skBytes[:]); err != nil {
+		return nil, err
+	}
+	return &Keypair{
+		SecretKey: hexutil.Encode(skBytes[:]),
+		PublicKey: hexutil.Encode(pkBytes[:]),
+	}, nil
+}
@@ -0,0 +1,172 @@
+// Package anr discovers the custom chains of an avalanche-network-runner
+// cluster and the nodes serving them.
+package anr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/client"
+	"github.com/ava-labs/avalanche-network-runner/rpcpb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+// DefaultEndpoint is where avalanche-network-runner serves its control API.
+const DefaultEndpoint = "0.0.0.0:12352"
+
+var ErrNoChains = errors.New("no node tracks a custom chain")
+
+type Chain struct {
+	ID       ids.ID
+	Name     string
+	SubnetID ids.ID
+}
+
+// Endpoint is a node serving a chain.
+type Endpoint struct {
+	Node    string
+	NodeURL string
+	ChainID ids.ID
+	// URI is the chain's API root on the node
+	URI string
+}
+
+type Cluster struct {
+	// Chains are sorted by name, then ID
+	Chains []*Chain
+	// Endpoints are sorted by node name, then chain
+	Endpoints []*Endpoint
+}
+
+// Load fetches the cluster status from the control server at [endpoint].
+func Load(ctx context.Context, endpoint string) (*Cluster, error) {
+	cli, err := client.New(client.Config{
+		Endpoint:    endpoint,
+		DialTimeout: 10 * time.Second,
+	}, logging.NoLog{})
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	status, err := cli.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return FromClusterInfo(status.ClusterInfo)
+}
+
+// FromClusterInfo lists every custom chain in [info] and the nodes that
+// track its subnet. Node order doesn't depend on map iteration, so the same
+// cluster always produces the same endpoints.
+func FromClusterInfo(info *rpcpb.ClusterInfo) (*Cluster, error) {
+	if info == nil {
+		return nil, ErrNoChains
+	}
+	cluster := &Cluster{}
+	subnets := map[ids.ID][]*Chain{}
+	for chain, chainInfo := range info.CustomChains {
+		chainID, err := ids.FromString(chain)
+		if err != nil {
+			return nil, err
+		}
+		subnetID, err := ids.FromString(chainInfo.SubnetId)
+		if err != nil {
+			return nil, err
+		}
+		c := &Chain{ID: chainID, Name: chainInfo.ChainName, SubnetID: subnetID}
+		cluster.Chains = append(cluster.Chains, c)
+		subnets[subnetID] = append(subnets[subnetID], c)
+	}
+	sort.Slice(cluster.Chains, func(i, j int) bool {
+		a, b := cluster.Chains[i], cluster.Chains[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	order := make(map[ids.ID]int, len(cluster.Chains))
+	for i, c := range cluster.Chains {
+		order[c.ID] = i
+	}
+
+	names := make([]string, 0, len(info.NodeInfos))
+	for name := range info.NodeInfos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		nodeInfo := info.NodeInfos[name]
+		if len(nodeInfo.WhitelistedSubnets) == 0 {
+			continue
+		}
+		var chains []*Chain
+		for _, subnet := range strings.Split(nodeInfo.WhitelistedSubnets, ",") {
+			subnetID, err := ids.FromString(subnet)
+			if err != nil {
+				return nil, err
+			}
+			chains = append(chains, subnets[subnetID]...)
+		}
+		sort.Slice(chains, func(i, j int) bool { return order[chains[i].ID] < order[chains[j].ID] })
+		for _, c := range chains {
+			cluster.Endpoints = append(cluster.Endpoints, &Endpoint{
+				Node:    name,
+				NodeURL: nodeInfo.Uri,
+				ChainID: c.ID,
+				URI:     fmt.Sprintf("%s/ext/bc/%s", nodeInfo.Uri, c.ID),
+			})
+		}
+	}
+	if len(cluster.Endpoints) == 0 {
+		return nil, ErrNoChains
+	}
+	return cluster, nil
+}
+
+// Chain returns the chain named or identified by [selector]. An empty
+// selector picks the only chain, and fails if there are several.
+func (c *Cluster) Chain(selector string) (*Chain, error) {
+	if len(selector) == 0 {
+		if len(c.Chains) != 1 {
+			names := make([]string, len(c.Chains))
+			for i, chain := range c.Chains {
+				names[i] = fmt.Sprintf("%s (%s)", chain.Name, chain.ID)
+			}
+			return nil, fmt.Errorf("cluster has %d custom chains, pick one of: %s", len(c.Chains), strings.Join(names, ", "))
+		}
+		return c.Chains[0], nil
+	}
+	for _, chain := range c.Chains {
+		if chain.Name == selector || chain.ID.String() == selector {
+			return chain, nil
+		}
+	}
+	return nil, fmt.Errorf("cluster has no custom chain %q", selector)
+}
+
+// Serving returns the endpoints of every node serving [chainID].
+func (c *Cluster) Serving(chainID ids.ID) []*Endpoint {
+	var endpoints []*Endpoint
+	for _, e := range c.Endpoints {
+		if e.ChainID == chainID {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// URIs returns the chain URI of every node serving [chainID].
+func (c *Cluster) URIs(chainID ids.ID) []string {
+	endpoints := c.Serving(chainID)
+	uris := make([]string, len(endpoints))
+	for i, e := range endpoints {
+		uris[i] = e.URI
+	}
+	return uris
+}
@@ -0,0 +1,147 @@
+// Package config is the seq-tools configuration file: named networks, and
+// profiles that say which network to talk to and which key to sign with.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AnomalyFi/tools/common/network"
+)
+
+// PathEnv overrides where the config file is looked up.
+const PathEnv = "SEQ_TOOLS_CONFIG"
+
+type Profile struct {
+	// Network names a network of the config or a built-in one
+	Network string `json:"network,omitempty"`
+	// Key is where the ed25519 key to sign with is loaded from, see
+	// credentials.Load
+	Key string `json:"key,omitempty"`
+}
+
+type Config struct {
+	// Profile is used when none is asked for
+	Profile  string              `json:"profile,omitempty"`
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+	// Networks add to, or replace, network.Builtin
+	Networks map[string]*network.Profile `json:"networks,omitempty"`
+}
+
+// DefaultPath is $SEQ_TOOLS_CONFIG, or seq-tools/config.json in the user's
+// config directory.
+func DefaultPath() string {
+	if p := os.Getenv(PathEnv); len(p) > 0 {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "seq-tools.json"
+	}
+	return filepath.Join(dir, "seq-tools", "config.json")
+}
+
+// Load reads the config at [path]. A missing file is an empty config.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Select returns the profile called [name], or the default profile if
+// [name] is empty. Without either the profile is empty.
+func (c *Config) Select(name string) (*Profile, error) {
+	if len(name) == 0 {
+		name = c.Profile
+	}
+	if len(name) == 0 {
+		return &Profile{}, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("no profile %q, the config has none", name)
+		}
+		return nil, fmt.Errorf("no profile %q, have: %s", name, strings.Join(c.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Names returns the profile names, sorted.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AllNetworks returns the built-in networks and those of the config.
+func (c *Config) AllNetworks() map[string]*network.Profile {
+	networks := make(map[string]*network.Profile, len(network.Builtin)+len(c.Networks))
+	for name, n := range network.Builtin {
+		networks[name] = n
+	}
+	for name, n := range c.Networks {
+		networks[name] = n
+	}
+	return networks
+}
+
+// Network returns a copy of the network called [name].
+func (c *Config) Network(name string) (*network.Profile, error) {
+	networks := c.AllNetworks()
+	n, ok := networks[name]
+	if !ok {
+		return nil, fmt.Errorf("no network %q, have: %s", name, strings.Join(network.Names(networks), ", "))
+	}
+	cp := *n
+	return &cp, nil
+}
+
+// NetworkFlag registers --network, which every tool that talks to a chain
+// takes, on [fs].
+func NetworkFlag(fs *flag.FlagSet) *string {
+	return fs.String("network", "", "named network from the config file ($"+PathEnv+") or built in: local, devnet")
+}
+
+// LoadNetwork returns the network called [name] in the config at
+// [DefaultPath].
+func LoadNetwork(name string) (*network.Profile, error) {
+	c, err := Load(DefaultPath())
+	if err != nil {
+		return nil, err
+	}
+	return c.Network(name)
+}
+
+// ResolveNetwork finds the nodes of the network called [name] in the
+// config at [DefaultPath].
+func ResolveNetwork(ctx context.Context, name string) (*network.Target, error) {
+	n, err := LoadNetwork(name)
+	if err != nil {
+		return nil, err
+	}
+	t, err := n.Resolve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("network %s: %w", name, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,263 @@
+// Package credentials loads the keys tools sign with from the environment,
+// a file, an encrypted keystore or stdin, so that no tool has to embed one.
+package credentials
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AnomalyFi/hypersdk/crypto/bls"
+	"github.com/AnomalyFi/hypersdk/crypto/ed25519"
+	"golang.org/x/term"
+)
+
+type Type string
+
+const (
+	ED25519 Type = "ed25519"
+	BLS     Type = "bls"
+	// Secp256k1 keys are Ethereum keys, e.g. the relayers' EigenDA keys
+	Secp256k1 Type = "secp256k1"
+)
+
+// secp256k1KeyLen is the length of a raw secp256k1 private key.
+const secp256k1KeyLen = 32
+
+const (
+	// KeyEnv is the key spec used when a tool is given none.
+	KeyEnv = "SEQ_TOOLS_KEY"
+	// Dev is the spec of the well known dev key.
+	Dev = "dev"
+)
+
+// devKey is funded at genesis by local networks and mock-seq. It is public,
+// so anything it holds on another network is anyone's.
+const devKey = "323b1d8f4eed5f0da9da93071b034f2dce9d2d22692c172f3cb252a64ddfafd01b057de320297c29ad0c1f589ea216869cf1938d88c9fbd70d6748323dbf2fa7" //nolint:lll
+
+var ErrWrongType = errors.New("wrong key type")
+
+// Key is a private key. Printing it only shows where it came from.
+type Key struct {
+	Type Type
+	// Source describes where the key was loaded from
+	Source string
+	// Dev is set for the well known dev key
+	Dev bool
+
+	bytes []byte
+}
+
+// Flags registers --key and --allow-dev-key, which every tool that signs
+// takes, on [fs].
+func Flags(fs *flag.FlagSet) (spec *string, allowDev *bool) {
+	spec, allowDev = new(string), new(bool)
+	FlagsVar(fs, spec, allowDev)
+	return spec, allowDev
+}
+
+// FlagsVar is [Flags] storing into [spec] and [allowDev], whose values are
+// the defaults.
+func FlagsVar(fs *flag.FlagSet, spec *string, allowDev *bool) {
+	fs.StringVar(spec, "key", *spec, "key to sign with: env:NAME, file:PATH or a path, keystore:PATH, stdin or dev (default $"+KeyEnv+", else dev)")
+	fs.BoolVar(allowDev, "allow-dev-key", *allowDev, "sign with the well known dev key even if the network isn't local")
+}
+
+// Load reads a key of type [typ] from [spec]:
+//
+//	env:NAME       hex in the environment variable NAME
+//	file:PATH      hex in a file, PATH alone works too
+//	keystore:PATH  a keystore written by [Encrypt]
+//	stdin          hex on stdin, read without echo from a terminal
+//	dev            the well known dev key, ed25519 only
+//
+// An empty spec is $SEQ_TOOLS_KEY, or dev for ed25519 keys if that is unset
+// too.
+func Load(spec string, typ Type) (*Key, error) {
+	if len(spec) == 0 {
+		spec = os.Getenv(KeyEnv)
+	}
+	if len(spec) == 0 && typ == ED25519 {
+		spec = Dev
+	}
+	kind, arg, ok := strings.Cut(spec, ":")
+	if !ok {
+		kind, arg = "file", spec
+		if spec == Dev || spec == "stdin" || spec == "-" {
+			kind = spec
+		}
+	}
+
+	var (
+		s   string
+		err error
+	)
+	switch kind {
+	case Dev:
+		if typ != ED25519 {
+			return nil, fmt.Errorf("%w: the dev key is %s, not %s", ErrWrongType, ED25519, typ)
+		}
+		s = devKey
+	case "env":
+		var ok bool
+		if s, ok = os.LookupEnv(arg); !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", arg)
+		}
+	case "file":
+		if len(arg) == 0 {
+			return nil, fmt.Errorf("no %s key given, see --key", typ)
+		}
+		var b []byte
+		b, err = os.ReadFile(arg)
+		s = string(b)
+	case "keystore":
+		return loadKeystore(arg, typ)
+	case "stdin", "-":
+		s, err = readSecret(typ.String() + " key: ")
+		spec = "stdin"
+	default:
+		return nil, fmt.Errorf("unknown key source %q, want env:, file:, keystore:, stdin or dev", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b, err := decode(s, typ)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", spec, err)
+	}
+	return &Key{Type: typ, Source: spec, Dev: isDev(typ, b), bytes: b}, nil
+}
+
+// Parse wraps the hex key [s] of type [typ], e.g. one a config embeds, so it
+// gets the checks of a loaded key. [source] names where it came from.
+func Parse(s string, typ Type, source string) (*Key, error) {
+	b, err := decode(s, typ)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return &Key{Type: typ, Source: source, Dev: isDev(typ, b), bytes: b}, nil
+}
+
+// DevKey returns the well known dev key.
+func DevKey() ed25519.PrivateKey {
+	b, err := decode(devKey, ED25519)
+	if err != nil {
+		panic(err)
+	}
+	return ed25519.PrivateKey(b)
+}
+
+// isDev reports whether [b] is the dev key, however it was given.
+func isDev(typ Type, b []byte) bool {
+	return typ == ED25519 && hex.EncodeToString(b) == devKey
+}
+
+// New wraps [b], e.g. a key just generated, so it can be encrypted.
+func New(typ Type, b []byte, source string) *Key {
+	return &Key{Type: typ, Source: source, bytes: b}
+}
+
+func (t Type) String() string {
+	return string(t)
+}
+
+func (t Type) size() int {
+	switch t {
+	case BLS:
+		return bls.SecretKeyLen
+	case Secp256k1:
+		return secp256k1KeyLen
+	default:
+		return ed25519.PrivateKeyLen
+	}
+}
+
+func decode(s string, typ Type) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		// Don't echo the input, it may be a mangled key
+		return nil, fmt.Errorf("%s key is not valid hex", typ)
+	}
+	if len(b) != typ.size() {
+		return nil, fmt.Errorf("%w: %s keys are %d bytes, got %d", ErrWrongType, typ, typ.size(), len(b))
+	}
+	return b, nil
+}
+
+// Bytes returns the raw key.
+func (k *Key) Bytes() []byte {
+	return k.bytes
+}
+
+// Hex returns the key hex encoded, for configs that embed it.
+func (k *Key) Hex() string {
+	return hex.EncodeToString(k.bytes)
+}
+
+func (k *Key) ED25519() (ed25519.PrivateKey, error) {
+	if k.Type != ED25519 {
+		return ed25519.EmptyPrivateKey, fmt.Errorf("%w: %s is a %s key", ErrWrongType, k.Source, k.Type)
+	}
+	return ed25519.PrivateKey(k.bytes), nil
+}
+
+func (k *Key) BLS() (*bls.PrivateKey, error) {
+	if k.Type != BLS {
+		return nil, fmt.Errorf("%w: %s is a %s key", ErrWrongType, k.Source, k.Type)
+	}
+	return bls.PrivateKeyFromBytes(k.bytes)
+}
+
+func (k *Key) String() string {
+	return fmt.Sprintf("%s key from %s", k.Type, k.Source)
+}
+
+func (k *Key) GoString() string {
+	return k.String()
+}
+
+func (k *Key) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
+
+// Secret reads a secret that isn't a key, e.g. an API token, from [spec]:
+// env:NAME, file:PATH or stdin.
+func Secret(spec string, name string) (string, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "env":
+		s, ok := os.LookupEnv(arg)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", arg)
+		}
+		return s, nil
+	case "file":
+		b, err := os.ReadFile(arg)
+		return strings.TrimSpace(string(b)), err
+	case "stdin", "-":
+		return readSecret(name + ": ")
+	default:
+		return "", fmt.Errorf("unknown source %q of the %s, want env:, file: or stdin", kind, name)
+	}
+}
+
+// readSecret reads a line from stdin, prompting without echo if it is a
+// terminal.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if len(line) > 0 {
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
@@ -0,0 +1,139 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PasswordEnv is the password of encrypted keystores. Without it the
+// password is read from stdin.
+const PasswordEnv = "SEQ_TOOLS_KEYSTORE_PASSWORD"
+
+const (
+	keystoreVersion = 1
+	scryptN         = 1 << 18
+	scryptR         = 8
+	scryptP         = 1
+)
+
+var ErrWrongPassword = errors.New("wrong keystore password")
+
+// keystore is a key encrypted with AES-GCM under a key derived from a
+// password with scrypt.
+type keystore struct {
+	Version    int    `json:"version"`
+	Type       Type   `json:"type"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Encrypt returns [k] as a keystore protected by [password].
+func Encrypt(k *Key, password []byte) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(password, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&keystore{
+		Version:    keystoreVersion,
+		Type:       k.Type,
+		KDF:        "scrypt",
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Salt:       hex.EncodeToString(salt),
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, k.bytes, []byte(k.Type))),
+	}, "", "  ")
+}
+
+// Password returns $SEQ_TOOLS_KEYSTORE_PASSWORD, or reads the password from
+// stdin.
+func Password(prompt string) ([]byte, error) {
+	if p, ok := os.LookupEnv(PasswordEnv); ok {
+		return []byte(p), nil
+	}
+	p, err := readSecret(prompt)
+	return []byte(p), err
+}
+
+func loadKeystore(path string, typ Type) (*Key, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ks keystore
+	if err := json.Unmarshal(b, &ks); err != nil {
+		return nil, fmt.Errorf("unable to parse keystore %s: %w", path, err)
+	}
+	if ks.Version != keystoreVersion || ks.KDF != "scrypt" {
+		return nil, fmt.Errorf("keystore %s: unsupported version %d or kdf %q", path, ks.Version, ks.KDF)
+	}
+	if ks.Type != typ {
+		return nil, fmt.Errorf("%w: keystore %s holds a %s key, not %s", ErrWrongType, path, ks.Type, typ)
+	}
+	salt, err := hex.DecodeString(ks.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+
+	password, err := Password("password of " + path + ": ")
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(password, salt, ks.N, ks.R, ks.P)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("keystore %s: bad nonce", path)
+	}
+	key, err := aead.Open(nil, nonce, ciphertext, []byte(ks.Type))
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s", ErrWrongPassword, path)
+	}
+	if len(key) != typ.size() {
+		return nil, fmt.Errorf("%w: keystore %s holds %d bytes", ErrWrongType, path, len(key))
+	}
+	return &Key{Type: typ, Source: "keystore:" + path, Dev: isDev(typ, key), bytes: key}, nil
+}
+
+func newAEAD(password []byte, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	derived, err := scrypt.Key(password, salt, n, r, p, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
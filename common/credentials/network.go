@@ -0,0 +1,55 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+var ErrRemoteDevKey = errors.New("refusing to sign with the well known dev key on a network that isn't local, pass --allow-dev-key to do it anyway")
+
+// Remote returns the hosts of [uris] that aren't local: localhost, loopback
+// or private addresses.
+func Remote(uris []string) []string {
+	var remote []string
+	for _, uri := range uris {
+		host := uri
+		if u, err := url.Parse(uri); err == nil && len(u.Host) > 0 {
+			host = u.Hostname()
+		} else if h, _, err := net.SplitHostPort(uri); err == nil {
+			host = h
+		}
+		if !local(host) {
+			remote = append(remote, host)
+		}
+	}
+	return remote
+}
+
+func local(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified())
+}
+
+// CheckNetwork refuses the dev key for [uris] that aren't local unless
+// [allowDev], and warns whenever it is used.
+func (k *Key) CheckNetwork(uris []string, allowDev bool) error {
+	if !k.Dev {
+		return nil
+	}
+	if remote := Remote(uris); len(remote) > 0 {
+		if !allowDev {
+			return fmt.Errorf("%w (%s)", ErrRemoteDevKey, strings.Join(remote, ", "))
+		}
+		fmt.Fprintf(os.Stderr, "warning: signing with the well known dev key on %s, anyone can spend what it holds\n", strings.Join(remote, ", "))
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, "warning: signing with the well known dev key, pass --key to use your own")
+	return nil
+}
@@ -0,0 +1,13 @@
+package credentials
+
+// Redacted replaces secrets in printed configs and logs.
+const Redacted = "<redacted>"
+
+// Redact hides [secret], leaving empty secrets empty so it still shows
+// whether one is set.
+func Redact(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return Redacted
+}
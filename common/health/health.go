@@ -0,0 +1,281 @@
+// Package health checks that the nodes of a SEQ chain are up and agree with
+// each other: on the network and chain, on the last accepted block and on
+// the unit and namespace prices.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/fees"
+	hrpc "github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// DefaultNamespace is the namespace whose price is compared when none is
+// asked for.
+const DefaultNamespace = "nkit"
+
+type Options struct {
+	// NetworkID and ChainID are what every node must report. Zero values
+	// make the nodes agree with the majority instead.
+	NetworkID uint32
+	ChainID   ids.ID
+
+	// MaxLag is how many blocks a node may be behind the highest one
+	MaxLag uint64
+	// MaxAge is how old the last accepted block of the highest node may be
+	// before the chain counts as stalled, 0 to not check
+	MaxAge time.Duration
+
+	Namespaces []string
+	// Timeout bounds the queries to a single node
+	Timeout time.Duration
+}
+
+// Node is what one node reported, and what is wrong with it.
+type Node struct {
+	URI string `json:"uri"`
+
+	NetworkID       uint32            `json:"networkId"`
+	ChainID         ids.ID            `json:"chainId"`
+	Height          uint64            `json:"height"`
+	BlockID         ids.ID            `json:"blockId"`
+	Timestamp       time.Time         `json:"timestamp"`
+	UnitPrices      fees.Dimensions   `json:"unitPrices"`
+	NamespacePrices map[string]uint64 `json:"namespacePrices"`
+	Latency         time.Duration     `json:"latency"`
+
+	Err      string   `json:"error,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+func (n *Node) OK() bool {
+	return len(n.Err) == 0 && len(n.Problems) == 0
+}
+
+func (n *Node) problem(format string, args ...any) {
+	n.Problems = append(n.Problems, fmt.Sprintf(format, args...))
+}
+
+type Report struct {
+	Time  time.Time `json:"time"`
+	Nodes []*Node   `json:"nodes"`
+	// Problems are those of the chain rather than of a node
+	Problems []string `json:"problems,omitempty"`
+	OK       bool     `json:"ok"`
+}
+
+// Check queries every node in [uris] and compares their answers.
+func Check(ctx context.Context, uris []string, opts Options) *Report {
+	if len(opts.Namespaces) == 0 {
+		opts.Namespaces = []string{DefaultNamespace}
+	}
+	r := &Report{Time: time.Now(), Nodes: make([]*Node, len(uris))}
+	var wg sync.WaitGroup
+	for i, uri := range uris {
+		r.Nodes[i] = &Node{URI: uri, NamespacePrices: map[string]uint64{}}
+		wg.Add(1)
+		go func(n *Node) {
+			defer wg.Done()
+			query(ctx, n, opts)
+		}(r.Nodes[i])
+	}
+	wg.Wait()
+
+	r.compare(opts)
+	r.OK = len(r.Problems) == 0
+	for _, n := range r.Nodes {
+		r.OK = r.OK && n.OK()
+	}
+	return r
+}
+
+func query(ctx context.Context, n *Node, opts Options) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	defer func() { n.Latency = time.Since(start) }()
+
+	cli := hrpc.NewJSONRPCClient(n.URI)
+	var (
+		timestamp int64
+		err       error
+	)
+	if n.NetworkID, _, n.ChainID, err = cli.Network(ctx); err != nil {
+		n.Err = fmt.Sprintf("network: %v", err)
+		return
+	}
+	if n.BlockID, n.Height, timestamp, err = cli.LastAccepted(ctx); err != nil {
+		n.Err = fmt.Sprintf("last accepted: %v", err)
+		return
+	}
+	n.Timestamp = time.UnixMilli(timestamp)
+	if n.UnitPrices, err = cli.UnitPrices(ctx, false); err != nil {
+		n.Err = fmt.Sprintf("unit prices: %v", err)
+		return
+	}
+	for _, ns := range opts.Namespaces {
+		price, err := cli.NameSpacePrice(ctx, ns)
+		if err != nil {
+			n.Err = fmt.Sprintf("namespace price %s: %v", ns, err)
+			return
+		}
+		n.NamespacePrices[ns] = price
+	}
+}
+
+func (r *Report) compare(opts Options) {
+	var up []*Node
+	for _, n := range r.Nodes {
+		if len(n.Err) == 0 {
+			up = append(up, n)
+		}
+	}
+	if len(up) == 0 {
+		r.Problems = append(r.Problems, "no node answered")
+		return
+	}
+
+	// IDs
+	networkID := opts.NetworkID
+	if networkID == 0 {
+		networkID = majority(up, func(n *Node) uint32 { return n.NetworkID })
+	}
+	chainID := opts.ChainID
+	if chainID == ids.Empty {
+		chainID = majority(up, func(n *Node) ids.ID { return n.ChainID })
+	}
+	for _, n := range up {
+		if n.NetworkID != networkID {
+			n.problem("network ID %d, expected %d", n.NetworkID, networkID)
+		}
+		if n.ChainID != chainID {
+			n.problem("chain ID %s, expected %s", n.ChainID, chainID)
+		}
+	}
+
+	// Progress
+	var highest *Node
+	for _, n := range up {
+		if highest == nil || n.Height > highest.Height {
+			highest = n
+		}
+	}
+	for _, n := range up {
+		if lag := highest.Height - n.Height; lag > opts.MaxLag {
+			n.problem("%d blocks behind", lag)
+		}
+	}
+	if age := r.Time.Sub(highest.Timestamp); opts.MaxAge > 0 && age > opts.MaxAge {
+		r.Problems = append(r.Problems, fmt.Sprintf("last block is %s old", age.Round(time.Second)))
+	}
+
+	// Prices move with every block, so only nodes at the same height have to
+	// agree on them
+	heights := map[uint64][]*Node{}
+	for _, n := range up {
+		heights[n.Height] = append(heights[n.Height], n)
+	}
+	for _, same := range heights {
+		prices := majority(same, func(n *Node) fees.Dimensions { return n.UnitPrices })
+		for _, n := range same {
+			if n.UnitPrices != prices {
+				n.problem("unit prices %v, others at height %d have %v", n.UnitPrices, n.Height, prices)
+			}
+		}
+		for _, ns := range opts.Namespaces {
+			price := majority(same, func(n *Node) uint64 { return n.NamespacePrices[ns] })
+			for _, n := range same {
+				if n.NamespacePrices[ns] != price {
+					n.problem("%s price %d, others at height %d have %d", ns, n.NamespacePrices[ns], n.Height, price)
+				}
+			}
+		}
+	}
+}
+
+// majority is the most common value of [nodes], the first node's on ties.
+func majority[T comparable](nodes []*Node, value func(*Node) T) T {
+	counts := map[T]int{}
+	best := value(nodes[0])
+	for _, n := range nodes {
+		v := value(n)
+		counts[v]++
+		if counts[v] > counts[best] {
+			best = v
+		}
+	}
+	return best
+}
+
+// Print writes [r] as a table.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "%-4s %-60s %-8s %-10s %-12s %-20s %s\n", "node", "uri", "network", "height", "block age", "namespace prices", "result")
+	for i, n := range r.Nodes {
+		if len(n.Err) > 0 {
+			fmt.Fprintf(w, "%-4d %-60s %-8s %-10s %-12s %-20s FAIL %s\n", i, n.URI, "-", "-", "-", "-", n.Err)
+			continue
+		}
+		result := "OK"
+		if !n.OK() {
+			result = "FAIL"
+		}
+		fmt.Fprintf(w, "%-4d %-60s %-8d %-10d %-12s %-20s %s\n",
+			i, n.URI, n.NetworkID, n.Height, r.Time.Sub(n.Timestamp).Round(time.Millisecond), namespacePrices(n), result)
+		for _, p := range n.Problems {
+			fmt.Fprintf(w, "     - %s\n", p)
+		}
+	}
+	if n := firstUp(r.Nodes); n != nil {
+		fmt.Fprintf(w, "chain %s, unit prices %v\n", n.ChainID, n.UnitPrices)
+	}
+	for _, p := range r.Problems {
+		fmt.Fprintf(w, "FAIL %s\n", p)
+	}
+	if r.OK {
+		fmt.Fprintln(w, "PASS")
+	} else {
+		fmt.Fprintln(w, "FAIL")
+	}
+}
+
+// WriteJSON writes [r] as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func namespacePrices(n *Node) string {
+	names := make([]string, 0, len(n.NamespacePrices))
+	for ns := range n.NamespacePrices {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	s := ""
+	for i, ns := range names {
+		if i > 0 {
+			s += ","
+		}
+		s += fmt.Sprintf("%s=%d", ns, n.NamespacePrices[ns])
+	}
+	return s
+}
+
+func firstUp(nodes []*Node) *Node {
+	for _, n := range nodes {
+		if len(n.Err) == 0 {
+			return n
+		}
+	}
+	return nil
+}
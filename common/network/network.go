@@ -0,0 +1,107 @@
+// Package network resolves which SEQ chain a tool talks to: chain URIs given
+// on the command line, or the nodes of an ANR cluster serving it.
+package network
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strings"
+
+	hrpc "github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/tools/common/anr"
+)
+
+var ErrNoURIs = errors.New("no node serves the chain")
+
+// Selection is what the user asked for. URIs take precedence over ANR.
+type Selection struct {
+	ANR   string
+	Chain string
+	URIs  []string
+}
+
+// AddFlags registers --anr, --chain and --uris on [fs].
+func (s *Selection) AddFlags(fs *flag.FlagSet) {
+	if len(s.ANR) == 0 {
+		s.ANR = anr.DefaultEndpoint
+	}
+	fs.StringVar(&s.ANR, "anr", s.ANR, "avalanche-network-runner control server")
+	fs.StringVar(&s.Chain, "chain", s.Chain, "name or ID of the custom chain to use (required if the cluster has several)")
+	fs.Var((*uriList)(&s.URIs), "uris", "comma separated chain URIs to use instead of the ones in ANR, e.g. the ones printed by mock-seq")
+}
+
+// Target is the chain a selection resolved to.
+type Target struct {
+	URIs      []string
+	NetworkID uint32
+	SubnetID  ids.ID
+	ChainID   ids.ID
+	// HRP is only set if the target came from a profile that has one
+	HRP string
+
+	// Cluster and Chain are only set if the URIs came from ANR
+	Cluster *anr.Cluster
+	Chain   *anr.Chain
+}
+
+// Discover finds the chain URIs without asking the nodes anything.
+func (s Selection) Discover(ctx context.Context) (*Target, error) {
+	t := &Target{URIs: s.URIs}
+	if len(t.URIs) == 0 {
+		cluster, err := anr.Load(ctx, s.ANR)
+		if err != nil {
+			return nil, err
+		}
+		chain, err := cluster.Chain(s.Chain)
+		if err != nil {
+			return nil, err
+		}
+		t.Cluster, t.Chain, t.URIs = cluster, chain, cluster.URIs(chain.ID)
+	}
+	if len(t.URIs) == 0 {
+		return nil, ErrNoURIs
+	}
+	return t, nil
+}
+
+// Resolve finds the chain URIs, then asks the first node which network and
+// chain they belong to.
+func (s Selection) Resolve(ctx context.Context) (*Target, error) {
+	t, err := s.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.NetworkID, t.SubnetID, t.ChainID, err = hrpc.NewJSONRPCClient(t.URIs[0]).Network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SplitURIs splits a comma separated list, dropping empty entries.
+func SplitURIs(s string) []string {
+	var uris []string
+	for _, uri := range strings.Split(s, ",") {
+		if uri = strings.TrimSpace(uri); len(uri) > 0 {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+type uriList []string
+
+func (l *uriList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *uriList) Set(s string) error {
+	*l = SplitURIs(s)
+	return nil
+}
@@ -0,0 +1,199 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	hrpc "github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/tools/common/anr"
+)
+
+var (
+	ErrNoDiscovery = errors.New("network has no anr, uris or node")
+	ErrMismatch    = errors.New("node reports a different network")
+)
+
+// Profile is a named network: how to find its nodes and what they should
+// report.
+type Profile struct {
+	// Discovery is one of: the ANR control server, a static list of chain
+	// URIs, or a single node whose chain is named by Chain
+	ANR  string   `json:"anr,omitempty"`
+	URIs []string `json:"uris,omitempty"`
+	Node string   `json:"node,omitempty"`
+	// Chain is the name or ID of the chain in the ANR cluster, or its alias
+	// or ID on Node
+	Chain string `json:"chain,omitempty"`
+
+	// ChainID and NetworkID are checked against what the nodes report,
+	// unless empty
+	ChainID   string `json:"chainId,omitempty"`
+	NetworkID uint32 `json:"networkId,omitempty"`
+	// HRP is the human readable part of addresses on the network
+	HRP string `json:"hrp,omitempty"`
+}
+
+// Builtin are the networks every tool knows. A profiles file may redefine
+// them.
+var Builtin = map[string]*Profile{
+	"local": {
+		ANR: anr.DefaultEndpoint,
+		HRP: "seq",
+	},
+	"devnet": {
+		URIs: []string{
+			"http://devnet.nodekit.xyz/avax-0/ext/bc/cKA3rhvogANuQV6y8hXX9282tVVvDZQBoVUyRgBoXUZhnPjN7",
+			"http://devnet.nodekit.xyz/avax-1/ext/bc/cKA3rhvogANuQV6y8hXX9282tVVvDZQBoVUyRgBoXUZhnPjN7",
+			"http://devnet.nodekit.xyz/avax-2/ext/bc/cKA3rhvogANuQV6y8hXX9282tVVvDZQBoVUyRgBoXUZhnPjN7",
+			"http://devnet.nodekit.xyz/avax-3/ext/bc/cKA3rhvogANuQV6y8hXX9282tVVvDZQBoVUyRgBoXUZhnPjN7",
+			"http://devnet.nodekit.xyz/avax-4/ext/bc/cKA3rhvogANuQV6y8hXX9282tVVvDZQBoVUyRgBoXUZhnPjN7",
+		},
+		ChainID: "cKA3rhvogANuQV6y8hXX9282tVVvDZQBoVUyRgBoXUZhnPjN7",
+		HRP:     "token",
+	},
+}
+
+// Discovery names how the nodes of [p] are found.
+func (p *Profile) Discovery() string {
+	switch {
+	case len(p.URIs) > 0:
+		return "uris"
+	case len(p.Node) > 0:
+		return "node"
+	case len(p.ANR) > 0:
+		return "anr"
+	default:
+		return "none"
+	}
+}
+
+// Selection is how [p]'s nodes are found.
+func (p *Profile) Selection() (Selection, error) {
+	switch p.Discovery() {
+	case "uris":
+		return Selection{URIs: p.URIs}, nil
+	case "node":
+		if len(p.Chain) == 0 {
+			return Selection{}, errors.New("a node network needs the chain alias or ID")
+		}
+		return Selection{URIs: []string{strings.TrimSuffix(p.Node, "/") + "/ext/bc/" + p.Chain}}, nil
+	case "anr":
+		return Selection{ANR: p.ANR, Chain: p.Chain}, nil
+	default:
+		return Selection{}, ErrNoDiscovery
+	}
+}
+
+// Discover finds the nodes of [p] without asking them anything.
+func (p *Profile) Discover(ctx context.Context) (*Target, error) {
+	s, err := p.Selection()
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.HRP = p.HRP
+	return t, nil
+}
+
+// Resolve finds the nodes of [p] and checks that the first one is on the
+// expected network and chain.
+func (p *Profile) Resolve(ctx context.Context) (*Target, error) {
+	s, err := p.Selection()
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.check(t.NetworkID, t.ChainID); err != nil {
+		return nil, fmt.Errorf("%s: %w", t.URIs[0], err)
+	}
+	t.HRP = p.HRP
+	return t, nil
+}
+
+func (p *Profile) check(networkID uint32, chainID ids.ID) error {
+	if p.NetworkID != 0 && p.NetworkID != networkID {
+		return fmt.Errorf("%w: network ID %d, expected %d", ErrMismatch, networkID, p.NetworkID)
+	}
+	if len(p.ChainID) > 0 && p.ChainID != chainID.String() {
+		return fmt.Errorf("%w: chain ID %s, expected %s", ErrMismatch, chainID, p.ChainID)
+	}
+	return nil
+}
+
+// NodeCheck is what one node of a verified network reported.
+type NodeCheck struct {
+	URI       string
+	NetworkID uint32
+	ChainID   ids.ID
+	Latency   time.Duration
+	Err       error
+}
+
+// Verify asks every node of [p] for its network and chain ID and compares
+// them with the expected ones, or with the first node's if [p] expects
+// none. The error is only set if the nodes could not be found.
+func (p *Profile) Verify(ctx context.Context, timeout time.Duration) ([]*NodeCheck, error) {
+	s, err := p.Selection()
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]*NodeCheck, len(t.URIs))
+	var wg sync.WaitGroup
+	for i, uri := range t.URIs {
+		checks[i] = &NodeCheck{URI: uri}
+		wg.Add(1)
+		go func(c *NodeCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			c.NetworkID, _, c.ChainID, c.Err = hrpc.NewJSONRPCClient(c.URI).Network(ctx)
+			c.Latency = time.Since(start)
+		}(checks[i])
+	}
+	wg.Wait()
+
+	expected := *p
+	for _, c := range checks {
+		if c.Err != nil {
+			continue
+		}
+		// Without expectations the nodes have to agree with each other
+		if expected.NetworkID == 0 {
+			expected.NetworkID = c.NetworkID
+		}
+		if len(expected.ChainID) == 0 {
+			expected.ChainID = c.ChainID.String()
+		}
+		c.Err = expected.check(c.NetworkID, c.ChainID)
+	}
+	return checks, nil
+}
+
+// Names returns the names of [profiles], sorted.
+func Names(profiles map[string]*Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,45 @@
+// Package addr derives the SEQ address that receives the rewards of a BLS
+// key.
+package addr
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/crypto/bls"
+	"github.com/AnomalyFi/nodekit-seq/auth"
+)
+
+// HRP is the human readable part of SEQ addresses.
+const HRP = "seq"
+
+type Info struct {
+	SecretKey []byte
+	PublicKey []byte
+	Address   string
+}
+
+// FromBLSKey derives the public key and address of the hex encoded secret
+// key [blsKeyHex], with or without 0x prefix.
+func FromBLSKey(blsKeyHex string, hrp string) (*Info, error) {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(blsKeyHex), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode bls key hex: %w", err)
+	}
+	sk, err := bls.PrivateKeyFromBytes(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse bls key: %w", err)
+	}
+	pk := bls.PublicFromPrivateKey(sk)
+	addr, err := codec.AddressBech32(hrp, auth.NewBLSAddress(pk))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create address: %w", err)
+	}
+	return &Info{
+		SecretKey: keyBytes,
+		PublicKey: pk.Compress(),
+		Address:   addr,
+	}, nil
+}
@@ -1,41 +1,48 @@
 package main
 
 import (
-	"encoding/hex"
+	"flag"
 	"fmt"
 	"os"
-	"strings"
 
-	"github.com/AnomalyFi/hypersdk/codec"
-	"github.com/AnomalyFi/hypersdk/crypto/bls"
-	"github.com/AnomalyFi/nodekit-seq/auth"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/AnomalyFi/key2seqaddr/addr"
+	"github.com/AnomalyFi/tools/common/credentials"
 )
 
-func main() {
-	argsWithoutProg := os.Args[1:]
-	blsKeyHex := argsWithoutProg[0]
+var keySpec = flag.String("key", "", "BLS key instead of the argument: env:NAME, file:PATH or a path, keystore:PATH or stdin")
 
-	keyBytes, err := hex.DecodeString(strings.Trim(blsKeyHex, "0x"))
-	if err != nil {
-		fmt.Println("unable to decode bls key hex")
-		os.Exit(1)
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s <bls-secret-key-hex>\n       %s --key <spec>\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
 	}
-	sk, err := bls.PrivateKeyFromBytes(keyBytes)
-	if err != nil {
-		fmt.Println("unable to parse bls key")
-		os.Exit(1)
+	flag.Parse()
+
+	var keyHex string
+	switch {
+	case flag.NArg() == 1 && len(*keySpec) == 0:
+		keyHex = flag.Arg(0)
+	case flag.NArg() == 0 && len(*keySpec) > 0:
+		key, err := credentials.Load(*keySpec, credentials.BLS)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		keyHex = key.Hex()
+	default:
+		flag.Usage()
+		os.Exit(2)
 	}
-	pk := bls.PublicFromPrivateKey(sk)
-	addr := auth.NewBLSAddress(pk)
 
-	benAddr, err := codec.AddressBech32("seq", addr)
+	info, err := addr.FromBLSKey(keyHex, addr.HRP)
 	if err != nil {
-		fmt.Println("unable to create ben address")
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("%-15s %-100s\n", "BLS secretkey:", hexutil.Encode(keyBytes))
-	fmt.Printf("%-15s %-100s\n", "BLS pubkey:", hexutil.Encode(pk.Compress()))
-	fmt.Printf("%-15s %-100s\n", "SEQ addr:", benAddr)
+	fmt.Printf("%-15s %-100s\n", "BLS secretkey:", hexutil.Encode(info.SecretKey))
+	fmt.Printf("%-15s %-100s\n", "BLS pubkey:", hexutil.Encode(info.PublicKey))
+	fmt.Printf("%-15s %-100s\n", "SEQ addr:", info.Address)
 }
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	oracle "github.com/AnomalyFi/nodekit-tools/oracle-tools/config"
+	relayer "github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+	"github.com/AnomalyFi/tools/common/anr"
+	"github.com/AnomalyFi/tools/mock-anr/server"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files instead of comparing")
+
+const (
+	// The generators ask the nodes for these, which only serve the control
+	// API here
+	networkID           = 1337
+	firstMessageNetPort = 2173
+	// Serve RPC ports are kept from an earlier manifest rather than picked
+	// among the free ones
+	firstServeRPCPort = 12500
+)
+
+type goldenCase struct {
+	name    string
+	fixture string
+	// chain selects the custom chain, like the generators' --chain flag
+	chain string
+	// da assigns the relayers' DA backends, like relayer-tools' --da flag
+	da     string
+	server server.Config
+}
+
+var cases = []goldenCase{
+	{name: "one-node", fixture: "one-node.json"},
+	{name: "three-nodes", fixture: "three-nodes.json"},
+	{name: "five-nodes", fixture: "five-nodes.json"},
+	{name: "five-nodes-mixed-da", fixture: "five-nodes.json", da: "celestia,avail,0=eigenda"},
+	{name: "two-chains-seq", fixture: "five-nodes-two-chains.json", chain: "seq"},
+	{name: "two-chains-second", fixture: "five-nodes-two-chains.json", chain: "second"},
+	{name: "two-chains-unselected", fixture: "five-nodes-two-chains.json"},
+	{name: "no-chains", fixture: "no-chains.json"},
+	{name: "unavailable", fixture: "five-nodes.json", server: server.Config{FailCalls: 1}},
+}
+
+// TestGolden runs the config generators against the stand-in ANR server for
+// every fixture and compares what they produce with testdata/golden; -update
+// rewrites the golden files.
+func TestGolden(t *testing.T) {
+	oracleTemplate, err := oracle.Load(filepath.Join("testdata", "templates", "oracle.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	relayerTemplate, err := relayer.Load(filepath.Join("testdata", "templates", "relayer.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files, err := generate(c, oracleTemplate, relayerTemplate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join("testdata", "golden", c.name)
+			if *update {
+				if err := write(dir, files); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			for _, d := range compare(dir, files) {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+// generate serves the case's fixture and returns the files the generators
+// produce, or error.txt if discovery fails.
+func generate(c goldenCase, oracleTemplate *oracle.Config, relayerTemplate *relayer.Config) (map[string][]byte, error) {
+	info, err := server.LoadFixture(filepath.Join("fixtures", c.fixture))
+	if err != nil {
+		return nil, err
+	}
+	s, err := server.New("127.0.0.1:0", info, c.server)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_ = s.Serve()
+	}()
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	files := map[string][]byte{}
+	cluster, err := anr.Load(ctx, s.Addr())
+	if err != nil {
+		files["error.txt"] = []byte(err.Error() + "\n")
+		return files, nil
+	}
+	chain, err := cluster.Chain(c.chain)
+	if err != nil {
+		files["error.txt"] = []byte(err.Error() + "\n")
+		return files, nil
+	}
+
+	// What oracle.Discover does past asking the node for the network ID
+	endpoint, err := oracle.Pick(cluster, chain, oracle.DefaultNode)
+	if err != nil {
+		return nil, err
+	}
+	if files["oracle.json"], err = marshal(oracle.Generate(*oracleTemplate, endpoint, networkID)); err != nil {
+		return nil, err
+	}
+
+	// What relayer.Discover returns, and a manifest holding the ports
+	endpoints := cluster.Serving(chain.ID)
+	nodes := make([]relayer.Node, len(endpoints))
+	previous := &relayer.Manifest{}
+	for i, e := range endpoints {
+		nodes[i] = relayer.Node{Endpoint: e, MessageNetPort: ":" + strconv.Itoa(firstMessageNetPort+i)}
+		previous.Relayers = append(previous.Relayers, relayer.ManifestRelayer{Node: e.Node, ServeRPCPort: firstServeRPCPort + i})
+	}
+	da := c.da
+	if len(da) == 0 {
+		da = string(relayer.EigenDA)
+	}
+	configs, err := relayer.Build(ctx, *relayerTemplate, networkID, nodes, relayer.Options{
+		Ports:    relayer.DefaultPorts,
+		Previous: previous,
+		DA:       da,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, rc := range configs {
+		if files["relayer"+strconv.Itoa(i)+".json"], err = marshal(rc); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func marshal(v any) ([]byte, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func write(dir string, files map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare returns a description of every file in [dir] that differs from
+// [files], is missing or is unexpected.
+func compare(dir string, files map[string][]byte) []string {
+	var diffs []string
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	expected := map[string]bool{}
+	for _, e := range entries {
+		expected[e.Name()] = true
+		got, ok := files[e.Name()]
+		if !ok {
+			diffs = append(diffs, e.Name()+": not generated")
+			continue
+		}
+		want, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			diffs = append(diffs, err.Error())
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			diffs = append(diffs, e.Name()+": "+firstDiff(want, got))
+		}
+	}
+	for name := range files {
+		if !expected[name] {
+			diffs = append(diffs, name+": generated but not expected")
+		}
+	}
+	return diffs
+}
+
+func firstDiff(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d: want %q, got %q", i+1, strings.TrimSpace(w), strings.TrimSpace(g))
+		}
+	}
+	return "files differ"
+}
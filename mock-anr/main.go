@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/AnomalyFi/tools/common/anr"
+	"github.com/AnomalyFi/tools/mock-anr/server"
+)
+
+var (
+	fixture   = flag.String("fixture", "fixtures/five-nodes.json", "cluster to serve, as a ClusterInfo in protobuf JSON")
+	listen    = flag.String("listen", anr.DefaultEndpoint, "address to serve the control API on")
+	delay     = flag.Duration("delay", 0, "delay added to every call")
+	failCalls = flag.Int("fail-calls", 0, "number of calls that fail before any succeeds")
+	failRate  = flag.Float64("fail-rate", 0, "share of calls that fail")
+	failCode  = flag.String("fail-code", "UNAVAILABLE", "gRPC status of failed calls, e.g. UNAVAILABLE or DEADLINE_EXCEEDED")
+)
+
+func main() {
+	flag.Parse()
+
+	info, err := server.LoadFixture(*fixture)
+	if err != nil {
+		panic(err)
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(*failCode)))); err != nil {
+		panic(err)
+	}
+	s, err := server.New(*listen, info, server.Config{
+		Delay:     *delay,
+		FailCalls: *failCalls,
+		FailRate:  *failRate,
+		FailCode:  code,
+	})
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		s.Stop()
+	}()
+	fmt.Println("serving", *fixture, "on", s.Addr(), "at", time.Now().Format(time.RFC3339))
+	if err := s.Serve(); err != nil {
+		panic(err)
+	}
+}
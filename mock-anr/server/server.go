@@ -0,0 +1,143 @@
+// Package server is a stand-in for the avalanche-network-runner control
+// server. It answers Status with a scripted cluster and can be told to fail.
+package server
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/rpcpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type Config struct {
+	// Delay is added to every call.
+	Delay time.Duration
+	// FailCalls is the number of calls that fail before any succeeds.
+	FailCalls int
+	// FailRate is the share of the remaining calls that fail.
+	FailRate float64
+	// FailCode is the status returned by failed calls.
+	FailCode codes.Code
+}
+
+// LoadFixture reads a ClusterInfo in its protobuf JSON encoding.
+func LoadFixture(path string) (*rpcpb.ClusterInfo, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info := &rpcpb.ClusterInfo{}
+	if err := protojson.Unmarshal(b, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+type Server struct {
+	rpcpb.UnimplementedControlServiceServer
+
+	cfg      Config
+	listener net.Listener
+	grpc     *grpc.Server
+
+	l     sync.Mutex
+	rng   *rand.Rand
+	info  *rpcpb.ClusterInfo
+	calls int
+}
+
+// New listens on [addr] and serves [info] once Serve is called.
+func New(addr string, info *rpcpb.ClusterInfo, cfg Config) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.FailCode == codes.OK {
+		cfg.FailCode = codes.Unavailable
+	}
+	s := &Server{
+		cfg:      cfg,
+		listener: listener,
+		grpc:     grpc.NewServer(),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		info:     info,
+	}
+	rpcpb.RegisterControlServiceServer(s.grpc, s)
+	rpcpb.RegisterPingServiceServer(s.grpc, &pingServer{})
+	return s, nil
+}
+
+// Addr is the address clients should dial.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Serve blocks until Stop is called.
+func (s *Server) Serve() error {
+	return s.grpc.Serve(s.listener)
+}
+
+func (s *Server) Stop() {
+	s.grpc.Stop()
+}
+
+// SetClusterInfo replaces the cluster returned by later calls.
+func (s *Server) SetClusterInfo(info *rpcpb.ClusterInfo) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.info = info
+}
+
+// Calls is the number of calls served, including failed ones.
+func (s *Server) Calls() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	return s.calls
+}
+
+func (s *Server) Status(ctx context.Context, _ *rpcpb.StatusRequest) (*rpcpb.StatusResponse, error) {
+	info, err := s.call(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcpb.StatusResponse{ClusterInfo: info}, nil
+}
+
+// call applies the configured delay and failures.
+func (s *Server) call(ctx context.Context) (*rpcpb.ClusterInfo, error) {
+	if s.cfg.Delay > 0 {
+		t := time.NewTimer(s.cfg.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.calls++
+	if s.calls <= s.cfg.FailCalls || (s.cfg.FailRate > 0 && s.rng.Float64() < s.cfg.FailRate) {
+		return nil, status.Error(s.cfg.FailCode, "injected failure")
+	}
+	return s.info, nil
+}
+
+type pingServer struct {
+	rpcpb.UnimplementedPingServiceServer
+}
+
+func (*pingServer) Ping(context.Context, *rpcpb.PingRequest) (*rpcpb.PingResponse, error) {
+	return &rpcpb.PingResponse{Pid: int32(os.Getpid())}, nil
+}
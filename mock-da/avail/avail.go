@@ -0,0 +1,256 @@
+// Package avail stands in for the v2 API of an Avail light client in app
+// mode: data submitted over HTTP or its websocket is included at once, in a
+// block of its own. It is the light client's submit API, not the Substrate
+// RPC of an Avail node, so only clients submitting through a light client
+// can post to it.
+package avail
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/AnomalyFi/tools/mock-da/store"
+)
+
+// Version is the light client version the server reports.
+const Version = "v1.7.10-mock"
+
+// MaxDataSize is the most data one submission takes.
+const MaxDataSize = 512 << 10
+
+// Submitted is where submitted data was included.
+type Submitted struct {
+	BlockNumber uint64 `json:"block_number"`
+	BlockHash   string `json:"block_hash"`
+	Hash        string `json:"hash"`
+	Index       int    `json:"index"`
+}
+
+type Server struct {
+	store *store.Store
+	appID uint32
+	ln    net.Listener
+	srv   *http.Server
+}
+
+// New listens on [addr] as a light client of app [appID].
+func New(addr string, s *store.Store, appID uint32) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	a := &Server{store: s, appID: appID, ln: ln}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/version", a.handle(func(*http.Request) (any, error) { return a.version(), nil }))
+	mux.HandleFunc("GET /v2/status", a.handle(func(*http.Request) (any, error) { return a.status(), nil }))
+	mux.HandleFunc("POST /v2/submit", a.handle(a.submitHTTP))
+	mux.HandleFunc("GET /v2/blocks/{block_number}/data", a.handle(a.blockData))
+	mux.HandleFunc("GET /v2/ws", a.ws)
+	a.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return a, nil
+}
+
+// URL is the HTTP API of the server.
+func (a *Server) URL() string {
+	return "http://" + a.ln.Addr().String() + "/v2"
+}
+
+// WSURL is the websocket API of the server, the apiURL of a relayer
+// submitting to it.
+func (a *Server) WSURL() string {
+	return "ws://" + a.ln.Addr().String() + "/v2/ws"
+}
+
+// AppID is the app the server submits data for.
+func (a *Server) AppID() uint32 {
+	return a.appID
+}
+
+func (a *Server) Serve() error {
+	if err := a.srv.Serve(a.ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (a *Server) Close() error {
+	return a.srv.Close()
+}
+
+// badRequest is an error in what the client sent.
+type badRequest struct{ error }
+
+func (a *Server) handle(f func(*http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, err := f(r)
+		var bad badRequest
+		switch {
+		case errors.As(err, &bad):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, errNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(v)
+		}
+	}
+}
+
+var errNotFound = errors.New("not found")
+
+func (a *Server) version() any {
+	return map[string]string{"version": Version, "network_version": "mock-da"}
+}
+
+func (a *Server) status() any {
+	latest := a.store.Height(store.Avail)
+	blocks := map[string]any{"latest": latest}
+	if latest > 0 {
+		blocks["available"] = map[string]uint64{"first": 1, "last": latest}
+	}
+	return map[string]any{
+		"modes":        []string{"light", "app"},
+		"app_id":       a.appID,
+		"genesis_hash": "0x" + hex.EncodeToString(hash("genesis", nil)),
+		"network":      "mock-da",
+		"blocks":       blocks,
+	}
+}
+
+type submitRequest struct {
+	Data []byte `json:"data"`
+}
+
+func (a *Server) submitHTTP(r *http.Request) (any, error) {
+	var req submitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, badRequest{err}
+	}
+	return a.submit(req.Data)
+}
+
+func (a *Server) submit(data []byte) (*Submitted, error) {
+	switch {
+	case len(data) == 0:
+		return nil, badRequest{errors.New("data is empty")}
+	case len(data) > MaxDataSize:
+		return nil, badRequest{fmt.Errorf("data is %d bytes, more than %d", len(data), MaxDataSize)}
+	}
+	b := &store.Blob{
+		Namespace: strconv.FormatUint(uint64(a.appID), 10),
+		Data:      data,
+	}
+	// Unique like an extrinsic hash, even for the same data
+	b.Ref = "0x" + hex.EncodeToString(hash("extrinsic-"+strconv.FormatInt(time.Now().UnixNano(), 10), data))
+	height, err := a.store.AddBlock(store.Avail, []*store.Blob{b})
+	if err != nil {
+		return nil, err
+	}
+	return &Submitted{
+		BlockNumber: height,
+		BlockHash:   blockHash(height),
+		Hash:        b.Ref,
+		Index:       b.Index,
+	}, nil
+}
+
+type dataTransaction struct {
+	Data []byte `json:"data"`
+}
+
+func (a *Server) blockData(r *http.Request) (any, error) {
+	height, err := strconv.ParseUint(r.PathValue("block_number"), 10, 64)
+	if err != nil {
+		return nil, badRequest{fmt.Errorf("block number: %w", err)}
+	}
+	if height == 0 || height > a.store.Height(store.Avail) {
+		return nil, fmt.Errorf("block %d: %w", height, errNotFound)
+	}
+	txs := []dataTransaction{}
+	for _, b := range a.store.At(store.Avail, height) {
+		txs = append(txs, dataTransaction{Data: b.Data})
+	}
+	return map[string]any{"block_number": height, "data_transactions": txs}, nil
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+type wsRequest struct {
+	Type      string          `json:"type"`
+	RequestID string          `json:"request_id"`
+	Message   json.RawMessage `json:"message"`
+}
+
+type wsResponse struct {
+	Topic     string `json:"topic"`
+	RequestID string `json:"request_id"`
+	Code      string `json:"code,omitempty"`
+	Message   any    `json:"message"`
+}
+
+// ws answers version, status and submit requests on the websocket.
+func (a *Server) ws(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for {
+		var req wsRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		resp := wsResponse{Topic: req.Type, RequestID: req.RequestID}
+		switch req.Type {
+		case "version":
+			resp.Message = a.version()
+		case "status":
+			resp.Message = a.status()
+		case "submit":
+			var s submitRequest
+			if err := json.Unmarshal(req.Message, &s); err != nil {
+				resp.Topic, resp.Code, resp.Message = "error", "bad-request", err.Error()
+				break
+			}
+			submitted, err := a.submit(s.Data)
+			if err != nil {
+				resp.Topic, resp.Code, resp.Message = "error", "bad-request", err.Error()
+				var bad badRequest
+				if !errors.As(err, &bad) {
+					resp.Code = "internal-server-error"
+				}
+				break
+			}
+			resp.Topic, resp.Message = "data-transaction-submitted", submitted
+		default:
+			resp.Topic, resp.Code, resp.Message = "error", "bad-request", fmt.Sprintf("unknown request type %q", req.Type)
+		}
+		if err := conn.WriteJSON(resp); err != nil {
+			return
+		}
+	}
+}
+
+func blockHash(height uint64) string {
+	return "0x" + hex.EncodeToString(hash("block-"+strconv.FormatUint(height, 10), nil))
+}
+
+func hash(domain string, data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("avail-" + domain))
+	h.Write(data)
+	return h.Sum(nil)
+}
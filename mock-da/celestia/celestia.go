@@ -0,0 +1,246 @@
+// Package celestia stands in for the blob API of a Celestia light node:
+// blob.Submit, blob.Get and blob.GetAll over JSON-RPC, and the heads a
+// client waits on.
+package celestia
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnomalyFi/tools/mock-da/store"
+)
+
+// NamespaceSize is the size of a namespace: a version byte and 28 bytes of
+// ID, the first 18 of which are zero in version 0.
+const NamespaceSize = 29
+
+// Blob is a blob as the node's JSON-RPC API encodes it.
+type Blob struct {
+	Namespace    []byte `json:"namespace"`
+	Data         []byte `json:"data"`
+	ShareVersion uint32 `json:"share_version"`
+	Commitment   []byte `json:"commitment"`
+	Index        int    `json:"index"`
+}
+
+// ErrNotFound is what the node answers for a blob it doesn't have.
+var ErrNotFound = errors.New("blob: not found")
+
+type Server struct {
+	store *store.Store
+	token string
+	ln    net.Listener
+	srv   *http.Server
+}
+
+// New listens on [addr]. Unless [token] is empty, requests need it as bearer
+// token, like the node's auth token.
+func New(addr string, s *store.Store, token string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Server{store: s, token: token, ln: ln}
+	c.srv = &http.Server{Handler: c, ReadHeaderTimeout: 10 * time.Second}
+	return c, nil
+}
+
+// URL is the rpcAddress of a relayer posting to the server.
+func (c *Server) URL() string {
+	return "http://" + c.ln.Addr().String()
+}
+
+func (c *Server) Serve() error {
+	if err := c.srv.Serve(c.ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (c *Server) Close() error {
+	return c.srv.Close()
+}
+
+type request struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	ID     json.RawMessage   `json:"id"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func (c *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if len(c.token) > 0 && r.Header.Get("Authorization") != "Bearer "+c.token {
+		http.Error(w, "missing or wrong auth token", http.StatusUnauthorized)
+		return
+	}
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	result, err := c.call(req.Method, req.Params)
+	if err != nil {
+		resp.Error = &rpcError{Code: 1, Message: err.Error()}
+	} else {
+		resp.Result = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Server) call(method string, params []json.RawMessage) (any, error) {
+	switch method {
+	case "blob.Submit":
+		var blobs []*Blob
+		if err := param(params, 0, &blobs); err != nil {
+			return nil, err
+		}
+		return c.submit(blobs)
+	case "blob.Get":
+		var (
+			height     uint64
+			namespace  []byte
+			commitment []byte
+		)
+		if err := param(params, 0, &height); err != nil {
+			return nil, err
+		}
+		if err := param(params, 1, &namespace); err != nil {
+			return nil, err
+		}
+		if err := param(params, 2, &commitment); err != nil {
+			return nil, err
+		}
+		for _, b := range c.store.At(store.Celestia, height) {
+			if b.Namespace == hex.EncodeToString(namespace) && b.Ref == hex.EncodeToString(commitment) {
+				return toBlob(b), nil
+			}
+		}
+		return nil, ErrNotFound
+	case "blob.GetAll":
+		var (
+			height     uint64
+			namespaces [][]byte
+		)
+		if err := param(params, 0, &height); err != nil {
+			return nil, err
+		}
+		if err := param(params, 1, &namespaces); err != nil {
+			return nil, err
+		}
+		var blobs []*Blob
+		for _, b := range c.store.At(store.Celestia, height) {
+			for _, ns := range namespaces {
+				if b.Namespace == hex.EncodeToString(ns) {
+					blobs = append(blobs, toBlob(b))
+				}
+			}
+		}
+		if len(blobs) == 0 {
+			return nil, ErrNotFound
+		}
+		return blobs, nil
+	case "header.LocalHead", "header.NetworkHead":
+		return map[string]any{
+			"header": map[string]string{
+				"chain_id": "mock-da",
+				"height":   strconv.FormatUint(c.store.Height(store.Celestia), 10),
+				"time":     time.Now().UTC().Format(time.RFC3339Nano),
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("method %s not found", method)
+	}
+}
+
+// submit stores [blobs] in a new block and returns its height.
+func (c *Server) submit(blobs []*Blob) (uint64, error) {
+	if len(blobs) == 0 {
+		return 0, errors.New("no blobs to submit")
+	}
+	stored := make([]*store.Blob, len(blobs))
+	for i, b := range blobs {
+		if err := ValidateNamespace(b.Namespace); err != nil {
+			return 0, fmt.Errorf("blob %d: %w", i, err)
+		}
+		if len(b.Data) == 0 {
+			return 0, fmt.Errorf("blob %d: no data", i)
+		}
+		stored[i] = &store.Blob{
+			Namespace: hex.EncodeToString(b.Namespace),
+			Ref:       hex.EncodeToString(Commitment(b.Namespace, b.Data)),
+			Data:      b.Data,
+		}
+	}
+	return c.store.AddBlock(store.Celestia, stored)
+}
+
+// ValidateNamespace checks that [ns] is a version 0 namespace.
+func ValidateNamespace(ns []byte) error {
+	if len(ns) != NamespaceSize {
+		return fmt.Errorf("namespace is %d bytes, not %d", len(ns), NamespaceSize)
+	}
+	if ns[0] != 0 {
+		return fmt.Errorf("namespace version %d isn't supported", ns[0])
+	}
+	if !bytes.Equal(ns[1:19], make([]byte, 18)) {
+		return errors.New("a version 0 namespace ID starts with 18 zero bytes")
+	}
+	return nil
+}
+
+// NamespaceV0 is the version 0 namespace of [id], e.g. a relayer's
+// nameSpaceID.
+func NamespaceV0(id []byte) []byte {
+	ns := make([]byte, NamespaceSize)
+	copy(ns[NamespaceSize-len(id):], id)
+	return ns
+}
+
+// Commitment stands in for the blob's share commitment.
+func Commitment(namespace []byte, data []byte) []byte {
+	h := sha256.New()
+	h.Write(namespace)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func toBlob(b *store.Blob) *Blob {
+	ns, _ := hex.DecodeString(b.Namespace)
+	commitment, _ := hex.DecodeString(b.Ref)
+	return &Blob{Namespace: ns, Data: b.Data, Commitment: commitment, Index: b.Index}
+}
+
+func param(params []json.RawMessage, i int, v any) error {
+	if i >= len(params) {
+		return fmt.Errorf("missing param %d", i)
+	}
+	if err := json.Unmarshal(params[i], v); err != nil {
+		return fmt.Errorf("param %d: %w", i, err)
+	}
+	return nil
+}
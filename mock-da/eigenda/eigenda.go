@@ -0,0 +1,267 @@
+// Package eigenda stands in for the EigenDA disperser: blobs dispersed over
+// its gRPC API are finalized at once, in a batch of their own.
+package eigenda
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/AnomalyFi/tools/mock-da/store"
+)
+
+// BlobStatus values of the disperser API.
+const (
+	Processing = 1
+	Finalized  = 4
+)
+
+// MaxBlobSize is the largest blob the disperser takes.
+const MaxBlobSize = 16 << 20
+
+// quorums are the quorums every blob is said to be attested by, whatever
+// the request asked for.
+var quorums = []uint32{0, 1}
+
+type Server struct {
+	store *store.Store
+	ln    net.Listener
+	srv   *grpc.Server
+}
+
+// New listens on [addr] with plaintext gRPC, relayers must dial it
+// without TLS.
+func New(addr string, s *store.Store) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	e := &Server{store: s, ln: ln}
+	e.srv = grpc.NewServer(grpc.ForceServerCodec(codec{}))
+	e.srv.RegisterService(&serviceDesc, e)
+	return e, nil
+}
+
+// Target is the disperser target of a relayer dispersing to the server.
+func (e *Server) Target() string {
+	return e.ln.Addr().String()
+}
+
+func (e *Server) Serve() error {
+	return e.srv.Serve(e.ln)
+}
+
+func (e *Server) Close() error {
+	e.srv.Stop()
+	return nil
+}
+
+// disperser is implemented by [Server], grpc checks it against the service.
+type disperser interface {
+	disperse(req []byte) (*message, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "disperser.Disperser",
+	HandlerType: (*disperser)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "DisperseBlob", Handler: unary((*Server).disperse)},
+		{MethodName: "GetBlobStatus", Handler: unary((*Server).blobStatus)},
+		{MethodName: "RetrieveBlob", Handler: unary((*Server).retrieve)},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DisperseBlobAuthenticated",
+			Handler:       disperseAuthenticated,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "disperser/disperser.proto",
+}
+
+func unary(method func(*Server, []byte) (*message, error)) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		var req message
+		if err := dec(&req); err != nil {
+			return nil, err
+		}
+		return method(srv.(*Server), req)
+	}
+}
+
+// disperse answers DisperseBlobRequest{data = 1, custom_quorum_numbers = 2,
+// account_id = 3} with DisperseBlobReply{result = 1, request_id = 2}.
+func (e *Server) disperse(req []byte) (*message, error) {
+	fields, err := decode(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "DisperseBlobRequest: %v", err)
+	}
+	data := bytesField(fields, 1)
+	switch {
+	case len(data) == 0:
+		return nil, status.Error(codes.InvalidArgument, "blob is empty")
+	case len(data) > MaxBlobSize:
+		return nil, status.Errorf(codes.InvalidArgument, "blob is %d bytes, more than %d", len(data), MaxBlobSize)
+	}
+	if _, err := uints(fields, 2); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "custom_quorum_numbers: %v", err)
+	}
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	b := &store.Blob{
+		Namespace: string(bytesField(fields, 3)),
+		Ref:       hex.EncodeToString(id),
+		Data:      data,
+	}
+	if _, err := e.store.AddBlock(store.EigenDA, []*store.Blob{b}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return new(builder).uint(1, Processing).bytes(2, id).done(), nil
+}
+
+// blobStatus answers BlobStatusRequest{request_id = 1} with
+// BlobStatusReply{status = 1, info = 2}.
+func (e *Server) blobStatus(req []byte) (*message, error) {
+	fields, err := decode(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "BlobStatusRequest: %v", err)
+	}
+	id := hex.EncodeToString(bytesField(fields, 1))
+	b, ok := e.store.Find(store.EigenDA, id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no blob with request ID %s", id)
+	}
+	return new(builder).uint(1, Finalized).message(2, blobInfo(b)).done(), nil
+}
+
+// retrieve answers RetrieveBlobRequest{batch_header_hash = 1,
+// blob_index = 2} with RetrieveBlobReply{data = 1}.
+func (e *Server) retrieve(req []byte) (*message, error) {
+	fields, err := decode(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "RetrieveBlobRequest: %v", err)
+	}
+	hash := bytesField(fields, 1)
+	var index uint64
+	if f := fields[2]; len(f) > 0 {
+		index = f[len(f)-1].v
+	}
+	for height := e.store.Height(store.EigenDA); height > 0; height-- {
+		if string(batchHeaderHash(height)) != string(hash) {
+			continue
+		}
+		for _, b := range e.store.At(store.EigenDA, height) {
+			if uint64(b.Index) == index {
+				return new(builder).bytes(1, b.Data).done(), nil
+			}
+		}
+		break
+	}
+	return nil, status.Errorf(codes.NotFound, "no blob %d in batch %x", index, hash)
+}
+
+// disperseAuthenticated takes AuthenticatedRequest{disperse_request = 1},
+// challenges with AuthenticatedReply{blob_auth_header = 1}, takes any
+// AuthenticatedRequest{authentication_data = 2} and answers
+// AuthenticatedReply{disperse_reply = 2}. The signature isn't checked.
+func disperseAuthenticated(srv any, stream grpc.ServerStream) error {
+	e := srv.(*Server)
+	var req message
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	fields, err := decode(req)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "AuthenticatedRequest: %v", err)
+	}
+	disperseReq := bytesField(fields, 1)
+	if disperseReq == nil {
+		return status.Error(codes.InvalidArgument, "expected a disperse_request first")
+	}
+	var challenge [4]byte
+	if _, err := rand.Read(challenge[:]); err != nil {
+		return err
+	}
+	header := new(builder).uint(1, uint64(binary.BigEndian.Uint32(challenge[:])))
+	if err := stream.SendMsg(new(builder).message(1, header).done()); err != nil {
+		return err
+	}
+	var auth message
+	if err := stream.RecvMsg(&auth); err != nil {
+		return err
+	}
+	if fields, err = decode(auth); err != nil {
+		return status.Errorf(codes.InvalidArgument, "AuthenticatedRequest: %v", err)
+	}
+	if len(bytesField(fields, 2)) == 0 {
+		return status.Error(codes.InvalidArgument, "expected authentication_data")
+	}
+	reply, err := e.disperse(disperseReq)
+	if err != nil {
+		return err
+	}
+	return stream.SendMsg(new(builder).message(2, (*builder)(reply)).done())
+}
+
+// blobInfo is the BlobInfo of [b]: its header and the proof of the batch it
+// is in. The commitment and hashes stand in for real ones, they are only
+// consistent with each other.
+func blobInfo(b *store.Blob) *builder {
+	commitment := new(builder).
+		bytes(1, hash("x", b.Data)).
+		bytes(2, hash("y", b.Data))
+	header := new(builder).
+		message(1, commitment).
+		// in 32 byte symbols
+		uint(2, uint64(len(b.Data)+31)/32)
+	quorumNumbers := make([]byte, len(quorums))
+	signed := make([]byte, len(quorums))
+	for i, q := range quorums {
+		param := new(builder).
+			uint(1, uint64(q)).
+			uint(2, 33).
+			uint(3, 55).
+			uint(4, 1)
+		header.message(3, param)
+		quorumNumbers[i] = byte(q)
+		signed[i] = 100
+	}
+	batchHeader := new(builder).
+		bytes(1, hash("root", b.Data)).
+		bytes(2, quorumNumbers).
+		bytes(3, signed).
+		uint(4, b.Height)
+	metadata := new(builder).
+		message(1, batchHeader).
+		bytes(2, hash("signatories", nil)).
+		uint(4, b.Height).
+		bytes(5, batchHeaderHash(b.Height))
+	proof := new(builder).
+		uint(1, b.Height).
+		uint(2, uint64(b.Index)).
+		message(3, metadata).
+		bytes(5, quorumNumbers)
+	return new(builder).message(1, header).message(2, proof)
+}
+
+func batchHeaderHash(height uint64) []byte {
+	return hash("batch-"+strconv.FormatUint(height, 10), nil)
+}
+
+func hash(domain string, data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("eigenda-" + domain))
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,127 @@
+package eigenda
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// message is an encoded protobuf message. The server's codec passes
+// messages through as they are, so the disperser API needs no generated
+// code.
+type message []byte
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(*message)
+	if !ok {
+		return nil, fmt.Errorf("can't marshal %T", v)
+	}
+	return *m, nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*message)
+	if !ok {
+		return fmt.Errorf("can't unmarshal into %T", v)
+	}
+	*m = append((*m)[:0], data...)
+	return nil
+}
+
+// Name is the codec replaced, clients send application/grpc+proto.
+func (codec) Name() string {
+	return "proto"
+}
+
+// field is a decoded field: a varint, or bytes which are also how strings,
+// packed repeated fields and nested messages are encoded.
+type field struct {
+	v uint64
+	b []byte
+}
+
+// decode returns the fields of [m] by number, in order.
+func decode(m []byte) (map[protowire.Number][]field, error) {
+	fields := map[protowire.Number][]field{}
+	for len(m) > 0 {
+		num, typ, n := protowire.ConsumeTag(m)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		m = m[n:]
+		var f field
+		switch typ {
+		case protowire.VarintType:
+			f.v, n = protowire.ConsumeVarint(m)
+		case protowire.BytesType:
+			f.b, n = protowire.ConsumeBytes(m)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, m)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		m = m[n:]
+		fields[num] = append(fields[num], f)
+	}
+	return fields, nil
+}
+
+// bytesField is the last value of field [num], as protobuf does.
+func bytesField(fields map[protowire.Number][]field, num protowire.Number) []byte {
+	f := fields[num]
+	if len(f) == 0 {
+		return nil
+	}
+	return f[len(f)-1].b
+}
+
+// uints are the values of the repeated uint32 field [num], packed or not.
+func uints(fields map[protowire.Number][]field, num protowire.Number) ([]uint32, error) {
+	var vs []uint32
+	for _, f := range fields[num] {
+		if f.b == nil {
+			vs = append(vs, uint32(f.v))
+			continue
+		}
+		for b := f.b; len(b) > 0; {
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			vs = append(vs, uint32(v))
+			b = b[n:]
+		}
+	}
+	return vs, nil
+}
+
+// builder encodes a message, leaving out fields with default values.
+type builder []byte
+
+func (b *builder) uint(num protowire.Number, v uint64) *builder {
+	if v != 0 {
+		*b = protowire.AppendVarint(protowire.AppendTag(*b, num, protowire.VarintType), v)
+	}
+	return b
+}
+
+func (b *builder) bytes(num protowire.Number, v []byte) *builder {
+	if len(v) > 0 {
+		*b = protowire.AppendBytes(protowire.AppendTag(*b, num, protowire.BytesType), v)
+	}
+	return b
+}
+
+// message adds a nested message, even an empty one.
+func (b *builder) message(num protowire.Number, v *builder) *builder {
+	*b = protowire.AppendBytes(protowire.AppendTag(*b, num, protowire.BytesType), *v)
+	return b
+}
+
+func (b *builder) done() *message {
+	m := message(*b)
+	return &m
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/AnomalyFi/tools/common/credentials"
+	"github.com/AnomalyFi/tools/mock-da/avail"
+	"github.com/AnomalyFi/tools/mock-da/celestia"
+	"github.com/AnomalyFi/tools/mock-da/eigenda"
+	"github.com/AnomalyFi/tools/mock-da/store"
+)
+
+var (
+	celestiaAddr  = flag.String("celestia", "127.0.0.1:26658", "address of the Celestia node JSON-RPC API (empty to disable)")
+	celestiaToken = flag.String("celestia-token", "", "where to read the auth token Celestia requests need from: env:NAME, file:PATH or stdin (default: no auth)")
+	eigendaAddr   = flag.String("eigenda", "127.0.0.1:32001", "address of the EigenDA disperser gRPC API (empty to disable)")
+	availAddr     = flag.String("avail", "127.0.0.1:7007", "address of the Avail light client API (empty to disable)")
+	availAppID    = flag.Uint("avail-app-id", 0, "app ID of the Avail light client")
+	apiAddr       = flag.String("api", "127.0.0.1:26650", "address serving /blobs and /endpoints")
+	dir           = flag.String("dir", "", "directory to keep the blobs in across restarts (default: memory only)")
+)
+
+// Endpoints are the endpoints served, with the field names of the relayer
+// config sections.
+type Endpoints struct {
+	Celestia *CelestiaEndpoint `json:"celestia,omitempty"`
+	EigenDA  *EigenDAEndpoint  `json:"eigenda,omitempty"`
+	Avail    *AvailEndpoint    `json:"avail,omitempty"`
+}
+
+type CelestiaEndpoint struct {
+	RPCAddress  string `json:"rpcAddress"`
+	AccessToken string `json:"accessToken"`
+}
+
+type EigenDAEndpoint struct {
+	Target string `json:"target"`
+}
+
+type AvailEndpoint struct {
+	ApiURL string `json:"apiURL"`
+	AppID  uint32 `json:"appID"`
+}
+
+// server is one of the DA stand-ins.
+type server interface {
+	Serve() error
+	Close() error
+}
+
+func main() {
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if *availAppID > 1<<32-1 {
+		panic(fmt.Sprintf("--avail-app-id %d is out of range", *availAppID))
+	}
+	var token string
+	if len(*celestiaToken) > 0 {
+		var err error
+		if token, err = credentials.Secret(*celestiaToken, "celestia auth token"); err != nil {
+			panic(err)
+		}
+	}
+	s, err := store.Open(*dir)
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	var (
+		endpoints Endpoints
+		servers   []server
+	)
+	if len(*celestiaAddr) > 0 {
+		c, err := celestia.New(*celestiaAddr, s, token)
+		if err != nil {
+			panic(err)
+		}
+		servers = append(servers, c)
+		endpoints.Celestia = &CelestiaEndpoint{RPCAddress: c.URL(), AccessToken: token}
+		fmt.Println("celestia", c.URL())
+	}
+	if len(*eigendaAddr) > 0 {
+		e, err := eigenda.New(*eigendaAddr, s)
+		if err != nil {
+			panic(err)
+		}
+		servers = append(servers, e)
+		endpoints.EigenDA = &EigenDAEndpoint{Target: e.Target()}
+		fmt.Println("eigenda", e.Target())
+	}
+	if len(*availAddr) > 0 {
+		a, err := avail.New(*availAddr, s, uint32(*availAppID))
+		if err != nil {
+			panic(err)
+		}
+		servers = append(servers, a)
+		endpoints.Avail = &AvailEndpoint{ApiURL: a.WSURL(), AppID: a.AppID()}
+		fmt.Println("avail", a.WSURL(), "app id", a.AppID())
+	}
+	if len(servers) == 0 {
+		panic("every DA stand-in is disabled")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/blobs", s)
+	mux.HandleFunc("/endpoints", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(endpoints)
+	})
+	ln, err := net.Listen("tcp", *apiAddr)
+	if err != nil {
+		panic(err)
+	}
+	api := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	fmt.Println("api", "http://"+ln.Addr().String())
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		if err := api.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	for _, srv := range servers {
+		g.Go(srv.Serve)
+	}
+	g.Go(func() error {
+		<-gctx.Done()
+		for _, srv := range servers {
+			srv.Close()
+		}
+		return api.Close()
+	})
+	if err := g.Wait(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,181 @@
+// Package store keeps the blobs posted to the DA stand-ins, in memory and
+// optionally in a file so they survive a restart.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	Celestia = "celestia"
+	EigenDA  = "eigenda"
+	Avail    = "avail"
+)
+
+// File is where a store kept on disk appends its blobs.
+const File = "blobs.jsonl"
+
+// Blob is a blob posted to a backend.
+type Blob struct {
+	Backend string `json:"backend"`
+	// Namespace is the Celestia namespace in hex, the EigenDA account or the
+	// Avail app ID
+	Namespace string `json:"namespace"`
+	// Ref is how the backend's API refers to the blob: the Celestia
+	// commitment or the EigenDA request ID in hex, or the Avail extrinsic
+	// hash
+	Ref string `json:"ref"`
+	// Height is the block, or EigenDA batch, the blob is in, and Index its
+	// position in it
+	Height uint64    `json:"height"`
+	Index  int       `json:"index"`
+	Data   []byte    `json:"data"`
+	Time   time.Time `json:"time"`
+}
+
+type Store struct {
+	mu      sync.Mutex
+	blobs   []*Blob
+	heights map[string]uint64
+	f       *os.File
+}
+
+// Open returns a store kept in memory, or appended to the blobs file in
+// [dir] if it isn't empty, after loading the blobs already there.
+func Open(dir string) (*Store, error) {
+	s := &Store{heights: map[string]uint64{}}
+	if len(dir) == 0 {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, File)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 64<<20)
+	for line := 1; sc.Scan(); line++ {
+		var b Blob
+		if err := json.Unmarshal(sc.Bytes(), &b); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		s.blobs = append(s.blobs, &b)
+		s.heights[b.Backend] = max(s.heights[b.Backend], b.Height)
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s.f = f
+	return s, nil
+}
+
+// AddBlock stores [blobs] of [backend] in a new block and returns its
+// height.
+func (s *Store) AddBlock(backend string, blobs []*Blob) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	height := s.heights[backend] + 1
+	now := time.Now()
+	for i, b := range blobs {
+		b.Backend, b.Height, b.Index, b.Time = backend, height, i, now
+		if s.f != nil {
+			d, err := json.Marshal(b)
+			if err != nil {
+				return 0, err
+			}
+			if _, err := s.f.Write(append(d, '\n')); err != nil {
+				return 0, err
+			}
+		}
+	}
+	s.heights[backend] = height
+	s.blobs = append(s.blobs, blobs...)
+	return height, nil
+}
+
+// Height is the last block of [backend].
+func (s *Store) Height(backend string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.heights[backend]
+}
+
+// Find returns the blob of [backend] with [ref].
+func (s *Store) Find(backend string, ref string) (*Blob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range s.blobs {
+		if b.Backend == backend && b.Ref == ref {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// At returns the blobs of [backend] in block [height].
+func (s *Store) At(backend string, height uint64) []*Blob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var blobs []*Blob
+	for _, b := range s.blobs {
+		if b.Backend == backend && b.Height == height {
+			blobs = append(blobs, b)
+		}
+	}
+	return blobs
+}
+
+// List returns the blobs of [backend], or of every backend if it is empty,
+// stored after [since].
+func (s *Store) List(backend string, since time.Time) []*Blob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blobs := []*Blob{}
+	for _, b := range s.blobs {
+		if (len(backend) == 0 || b.Backend == backend) && b.Time.After(since) {
+			blobs = append(blobs, b)
+		}
+	}
+	return blobs
+}
+
+func (s *Store) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	return s.f.Close()
+}
+
+// ServeHTTP answers GET /blobs?backend=<name>&since=<unix ms> with the
+// blobs stored, as JSON.
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var since time.Time
+	if v := r.URL.Query().Get("since"); len(v) > 0 {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "since should be a unix time in milliseconds", http.StatusBadRequest)
+			return
+		}
+		since = time.UnixMilli(ms)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.List(r.URL.Query().Get("backend"), since))
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/nodekit-seq/auth"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/poll"
+	"github.com/AnomalyFi/tools/common/credentials"
+	"github.com/AnomalyFi/tools/mock-seq/server"
+	"github.com/AnomalyFi/tools/spam/common/runner"
+	"github.com/AnomalyFi/tools/spam/transfer/spammer"
+)
+
+const (
+	e2eNodes          = 2
+	e2eNamespacePrice = 7
+	// e2eTxs at the transfer spammer's 1 tx per second
+	e2eTxs = 3
+)
+
+// e2eFunds is the dev key's balance at genesis
+const e2eFunds = 10_000_000_000_000
+
+// startChain runs an in-process mock-seq chain funding the dev key, served
+// by [nodes] nodes, until the test ends. It returns the chain and the URIs
+// of its nodes.
+func startChain(t *testing.T, nodes int) (*server.Chain, []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	c, err := server.NewChain(server.Config{
+		NetworkID:          1337,
+		ChainID:            ids.GenerateTestID(),
+		BlockInterval:      200 * time.Millisecond,
+		UnitPrices:         fees.Dimensions{1, 1, 1, 1, 1},
+		NamespaceBasePrice: e2eNamespacePrice,
+		Funds:              map[codec.Address]uint64{devAddress(): e2eFunds},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go c.Run(ctx)
+	uris := make([]string, nodes)
+	for i := range uris {
+		n, err := server.NewNode(c, fmt.Sprintf("node%d", i+1), "127.0.0.1:0", fmt.Sprintf(":%d", 9560+i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go n.Serve()
+		t.Cleanup(func() { n.Close() })
+		uris[i] = n.URI()
+	}
+	return c, uris
+}
+
+func devAddress() codec.Address {
+	return auth.NewED25519Address(credentials.DevKey().PublicKey())
+}
+
+// TestE2E spams a few transfers at an in-process mock-seq and polls its
+// namespace prices once, as a local run would.
+func TestE2E(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, uris := startChain(t, e2eNodes)
+
+	sum, err := runner.Run(ctx, spammer.Generator, []string{
+		"--uris=" + strings.Join(uris, ","),
+		"--key=" + credentials.Dev,
+		fmt.Sprintf("--max-txs=%d", e2eTxs),
+		"--duration=30s",
+		"--drain-timeout=10s",
+		"--results=",
+		"--slo-max-failures=0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sum.Passed {
+		t.Fatalf("run failed its SLOs: %v", sum.Violations)
+	}
+	if sum.Issued != e2eTxs || sum.Confirmed != sum.Issued {
+		t.Fatalf("issued %d and confirmed %d txs, want %d", sum.Issued, sum.Confirmed, e2eTxs)
+	}
+	if balance := c.Ledger().Balance(devAddress()); balance >= e2eFunds {
+		t.Fatalf("dev key balance %d didn't pay for the run", balance)
+	}
+
+	pctx, pcancel := context.WithTimeout(ctx, 10*time.Second)
+	defer pcancel()
+	var round *poll.Round
+	poll.Rounds(pctx, uris, []string{"nkit", "everest"}, time.Second, 5*time.Second, func(r *poll.Round) {
+		round = r
+		pcancel()
+	})
+	if round == nil {
+		t.Fatal("no poll round")
+	}
+	if len(round.Readings) != 2*e2eNodes {
+		t.Fatalf("got %d readings, want %d", len(round.Readings), 2*e2eNodes)
+	}
+	for _, r := range round.Readings {
+		switch {
+		case r.Err != nil:
+			t.Errorf("node %d %s: %v", r.Node, r.Namespace, r.Err)
+		case r.Price != e2eNamespacePrice:
+			t.Errorf("node %d %s: price %d, want %d", r.Node, r.Namespace, r.Price, e2eNamespacePrice)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/nodekit-seq/auth"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/AnomalyFi/tools/common/credentials"
+	"github.com/AnomalyFi/tools/mock-seq/server"
+)
+
+var (
+	nodes          = flag.Int("nodes", 5, "number of nodes to serve")
+	host           = flag.String("host", "127.0.0.1", "address to listen on")
+	port           = flag.Int("port", 9650, "port of the first node; node i listens on port+i")
+	messagePort    = flag.Int("message-port", 9560, "message net port reported by the first node; node i reports message-port+i")
+	networkID      = flag.Uint("network-id", 1337, "network ID")
+	chainIDFlag    = flag.String("chain-id", "tEpDFmDWyU4C7FCUYLg7YNudkJsRo6AQyvksmLAaAJ14yM1cs", "chain ID")
+	subnetIDFlag   = flag.String("subnet-id", "ua23zVDSnBJMFS5vfw4vCQAopBjG2SAi6CKMNVrQyviS7c21h", "subnet ID")
+	blockInterval  = flag.Duration("block-interval", time.Second, "time between blocks")
+	maxBlockTxs    = flag.Int("max-block-txs", 0, "max txs per block (0 for no limit)")
+	maxPending     = flag.Int("max-pending", 0, "max txs waiting to be included (0 for no limit)")
+	unitPricesFlag = flag.String("unit-prices", "100,100,100,100,100", "comma separated price of each fee dimension")
+	namespacePrice = flag.Uint64("namespace-price", 1, "price of a namespace no one posts to")
+	latency        = flag.Duration("latency", 0, "delay added to every API call and websocket message")
+	failRate       = flag.Float64("fail-rate", 0, "share of API calls and websocket txs that return an error")
+	dropRate       = flag.Float64("drop-rate", 0, "share of txs that are never included and expire")
+	rejectRate     = flag.Float64("reject-rate", 0, "share of txs that are removed before execution")
+	fund           = flag.String("fund", "", "comma separated address=amount initial balances, in addition to the dev key")
+	devKeyBalance  = flag.Uint64("dev-key-balance", 10_000_000_000_000_000, "initial balance of the well known dev key")
+)
+
+func main() {
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	chainID, err := ids.FromString(*chainIDFlag)
+	if err != nil {
+		panic(err)
+	}
+	subnetID, err := ids.FromString(*subnetIDFlag)
+	if err != nil {
+		panic(err)
+	}
+	unitPrices, err := parseUnitPrices(*unitPricesFlag)
+	if err != nil {
+		panic(err)
+	}
+	funds, err := parseFunds(*fund)
+	if err != nil {
+		panic(err)
+	}
+	funds[auth.NewED25519Address(credentials.DevKey().PublicKey())] += *devKeyBalance
+
+	c, err := server.NewChain(server.Config{
+		NetworkID:          uint32(*networkID),
+		SubnetID:           subnetID,
+		ChainID:            chainID,
+		BlockInterval:      *blockInterval,
+		MaxBlockTxs:        *maxBlockTxs,
+		MaxPending:         *maxPending,
+		UnitPrices:         unitPrices,
+		NamespaceBasePrice: *namespacePrice,
+		Latency:            *latency,
+		FailRate:           *failRate,
+		DropRate:           *dropRate,
+		RejectRate:         *rejectRate,
+		Funds:              funds,
+		Log:                logging.NewLogger("mock-seq", logging.NewWrappedCore(logging.Info, os.Stdout, logging.Colors.ConsoleEncoder())),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		c.Run(gctx)
+		return nil
+	})
+	uris := make([]string, 0, *nodes)
+	for i := 0; i < *nodes; i++ {
+		n, err := server.NewNode(
+			c,
+			fmt.Sprintf("node%d", i+1),
+			fmt.Sprintf("%s:%d", *host, *port+i),
+			fmt.Sprintf(":%d", *messagePort+i),
+		)
+		if err != nil {
+			panic(err)
+		}
+		g.Go(n.Serve)
+		g.Go(func() error {
+			<-gctx.Done()
+			return n.Close()
+		})
+		uris = append(uris, n.URI())
+		fmt.Println(n.Name(), n.URI())
+	}
+	fmt.Println("chain id", chainID)
+	fmt.Println("uris", strings.Join(uris, ","))
+
+	if err := g.Wait(); err != nil {
+		panic(err)
+	}
+}
+
+func parseUnitPrices(s string) (fees.Dimensions, error) {
+	var d fees.Dimensions
+	parts := strings.Split(s, ",")
+	if len(parts) != fees.FeeDimensions {
+		return d, fmt.Errorf("expected %d unit prices, got %d", fees.FeeDimensions, len(parts))
+	}
+	for i, p := range parts {
+		v, err := strconv.ParseUint(strings.TrimSpace(p), 10, 64)
+		if err != nil {
+			return d, err
+		}
+		d[i] = v
+	}
+	return d, nil
+}
+
+func parseFunds(s string) (map[codec.Address]uint64, error) {
+	funds := map[codec.Address]uint64{}
+	if len(s) == 0 {
+		return funds, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		addrStr, amountStr, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fund %q: expected address=amount", entry)
+		}
+		_, b, err := address.ParseBech32(addrStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid address in fund %q", err, entry)
+		}
+		if len(b) != codec.AddressLen {
+			return nil, fmt.Errorf("invalid fund %q: address is %d bytes", entry, len(b))
+		}
+		amount, err := strconv.ParseUint(amountStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid amount in fund %q", err, entry)
+		}
+		funds[codec.Address(b)] += amount
+	}
+	return funds, nil
+}
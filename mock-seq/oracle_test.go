@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/rpc"
+
+	oracle "github.com/AnomalyFi/nodekit-tools/oracle-tools/config"
+	"github.com/AnomalyFi/tools/common/anr"
+)
+
+// TestOracleConfig generates an oracle config from the repo's template for a
+// cluster of mock-seq nodes, and checks the node it names serves the chain.
+func TestOracleConfig(t *testing.T) {
+	c, uris := startChain(t, 3)
+	chainID := c.Config().ChainID
+	cluster := &anr.Cluster{Chains: []*anr.Chain{{ID: chainID, Name: "seq"}}}
+	for i, uri := range uris {
+		cluster.Endpoints = append(cluster.Endpoints, &anr.Endpoint{
+			Node:    fmt.Sprintf("node%d", i+1),
+			NodeURL: strings.TrimSuffix(uri, "/ext/bc/"+chainID.String()),
+			ChainID: chainID,
+			URI:     uri,
+		})
+	}
+	template, err := oracle.Load("../oracle-tools/config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	endpoint, networkID, err := oracle.Discover(ctx, cluster, cluster.Chains[0], oracle.DefaultNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint.Node != "node3" {
+		t.Errorf("picked %s, want the third node", endpoint.Node)
+	}
+	cfg := oracle.Generate(*template, endpoint, networkID)
+	if cfg.SEQConfig.SEQNodeUri != uris[2] {
+		t.Errorf("seq_node_uri is %s, want %s", cfg.SEQConfig.SEQNodeUri, uris[2])
+	}
+
+	gotNetwork, _, gotChain, err := rpc.NewJSONRPCClient(cfg.SEQConfig.SEQNodeUri).Network(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotNetwork != cfg.SEQConfig.NetworkID || gotChain.String() != cfg.SEQConfig.ChainIDStr {
+		t.Fatalf("the node serves network %d chain %s, the config says network %d chain %s",
+			gotNetwork, gotChain, cfg.SEQConfig.NetworkID, cfg.SEQConfig.ChainIDStr)
+	}
+}
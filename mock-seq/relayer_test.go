@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/e2e"
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+// TestRelayerSubmit submits the SequencerMsg txs of a relayer-tools e2e run
+// through the chain URI of its manifest, which is what the relayers' node
+// serves.
+func TestRelayerSubmit(t *testing.T) {
+	c, uris := startChain(t, 1)
+	m := &config.Manifest{
+		NetworkID: c.Config().NetworkID,
+		Relayers: []config.ManifestRelayer{
+			{Index: 0, ChainURI: uris[0], ChainID: c.Config().ChainID.String(), Backend: config.EigenDA},
+			{Index: 1, ChainURI: uris[0], ChainID: c.Config().ChainID.String(), Backend: config.Celestia},
+		},
+	}
+	opts := e2e.DefaultOptions
+	opts.Messages = 2
+	msgs, err := e2e.Plan(m, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e2e.Submit(ctx, m, credentials.DevKey(), msgs); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		if msg.TxID == ids.Empty || msg.Submitted.IsZero() {
+			t.Errorf("message %d wasn't submitted", msg.ID)
+		}
+		if msg.TxError != "" {
+			t.Errorf("message %d: %s", msg.ID, msg.TxError)
+		}
+	}
+	if balance := c.Ledger().Balance(devAddress()); balance >= e2eFunds {
+		t.Fatalf("dev key balance %d didn't pay for the messages", balance)
+	}
+
+	// The node root isn't a chain: it's what the manifest used to give
+	m.Relayers[0].ChainURI = strings.TrimSuffix(uris[0], "/ext/bc/"+m.Relayers[0].ChainID)
+	if err := e2e.Submit(ctx, m, credentials.DevKey(), msgs); err == nil {
+		t.Fatal("submitted through the node root")
+	}
+}
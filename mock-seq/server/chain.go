@@ -0,0 +1,288 @@
+// Package server is an in-memory stand-in for a SEQ chain. It serves the
+// subset of the hypersdk and nodekit-seq JSON-RPC and websocket APIs the
+// tools in this repo use, so they can be run without a cluster.
+package server
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/consts"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/AnomalyFi/hypersdk/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInjected       = errors.New("injected failure")
+	ErrDuplicateTx    = errors.New("duplicate tx")
+	ErrInvalidChainID = errors.New("invalid chain id")
+	ErrMempoolFull    = errors.New("mempool full")
+)
+
+type Config struct {
+	NetworkID uint32
+	SubnetID  ids.ID
+	ChainID   ids.ID
+
+	// BlockInterval is how often pending txs are included in a block.
+	BlockInterval time.Duration
+	// MaxBlockTxs caps how many txs are included per block. 0 means no cap.
+	MaxBlockTxs int
+	// MaxPending caps the mempool size. 0 means no cap.
+	MaxPending int
+
+	UnitPrices fees.Dimensions
+	// NamespaceBasePrice is the price of a namespace no one posts to.
+	NamespaceBasePrice uint64
+
+	// Latency is added to every JSON-RPC call and websocket message.
+	Latency time.Duration
+	// FailRate is the share of JSON-RPC calls and websocket txs that return
+	// an error.
+	FailRate float64
+	// DropRate is the share of txs that are never included and expire.
+	DropRate float64
+	// RejectRate is the share of txs that are removed before execution.
+	RejectRate float64
+
+	// Funds are the initial balances of the native asset.
+	Funds map[codec.Address]uint64
+
+	// Log receives the errors no client can be told about. Nothing is logged
+	// if it is nil.
+	Log logging.Logger
+}
+
+// Block is a produced block and the results of its txs.
+type Block struct {
+	Block   *chain.StatefulBlock
+	ID      ids.ID
+	Bytes   []byte
+	Results []*chain.Result
+	Prices  fees.Dimensions
+}
+
+// Outcome is the fate of a single tx.
+type Outcome struct {
+	TxID   ids.ID
+	Result *chain.Result
+	Err    error
+}
+
+type Subscriber interface {
+	Accepted(blk *Block, outcomes []*Outcome)
+}
+
+// Chain is shared by every node of the mock network.
+type Chain struct {
+	cfg    Config
+	ledger *Ledger
+
+	l           sync.Mutex
+	rng         *rand.Rand
+	pending     []*chain.Transaction
+	seen        map[ids.ID]struct{}
+	dropped     map[ids.ID]struct{}
+	subscribers []Subscriber
+	last        *Block
+	nsPrices    map[string]uint64
+}
+
+func NewChain(cfg Config) (*Chain, error) {
+	genesis := &chain.StatefulBlock{
+		Tmstmp: time.Now().UnixMilli(),
+	}
+	blk, err := newBlock(genesis, nil, cfg.UnitPrices)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Log == nil {
+		cfg.Log = logging.NoLog{}
+	}
+	return &Chain{
+		cfg:      cfg,
+		ledger:   NewLedger(cfg.Funds),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		seen:     map[ids.ID]struct{}{},
+		dropped:  map[ids.ID]struct{}{},
+		last:     blk,
+		nsPrices: map[string]uint64{},
+	}, nil
+}
+
+func newBlock(b *chain.StatefulBlock, results []*chain.Result, prices fees.Dimensions) (*Block, error) {
+	bytes, err := b.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &Block{
+		Block:   b,
+		ID:      utils.ToID(bytes),
+		Bytes:   bytes,
+		Results: results,
+		Prices:  prices,
+	}, nil
+}
+
+func (c *Chain) Config() Config {
+	return c.cfg
+}
+
+func (c *Chain) Ledger() *Ledger {
+	return c.ledger
+}
+
+func (c *Chain) Subscribe(s Subscriber) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.subscribers = append(c.subscribers, s)
+}
+
+func (c *Chain) LastAccepted() *Block {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.last
+}
+
+// roll returns true with probability [p].
+func (c *Chain) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.rng.Float64() < p
+}
+
+// NamespacePrice is the current price to post to [namespace].
+func (c *Chain) NamespacePrice(namespace string) uint64 {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return max(c.nsPrices[namespace], c.cfg.NamespaceBasePrice)
+}
+
+// Submit adds [tx] to the mempool.
+func (c *Chain) Submit(tx *chain.Transaction) error {
+	if tx.Base.ChainID != c.cfg.ChainID {
+		return ErrInvalidChainID
+	}
+	now := time.Now().UnixMilli()
+	if tx.Base.Timestamp < now {
+		return rpc.ErrExpired
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if _, ok := c.seen[tx.ID()]; ok {
+		return ErrDuplicateTx
+	}
+	if c.cfg.MaxPending > 0 && len(c.pending) >= c.cfg.MaxPending {
+		return ErrMempoolFull
+	}
+	c.seen[tx.ID()] = struct{}{}
+	if c.cfg.DropRate > 0 && c.rng.Float64() < c.cfg.DropRate {
+		c.dropped[tx.ID()] = struct{}{}
+	}
+	c.pending = append(c.pending, tx)
+	return nil
+}
+
+// Run produces a block every [BlockInterval] until [ctx] is done.
+func (c *Chain) Run(ctx context.Context) {
+	t := time.NewTicker(c.cfg.BlockInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := c.produce(); err != nil {
+				c.cfg.Log.Error("failed to produce block", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Chain) produce() error {
+	c.l.Lock()
+	now := time.Now().UnixMilli()
+	var (
+		txs      []*chain.Transaction
+		results  []*chain.Result
+		outcomes []*Outcome
+		keep     []*chain.Transaction
+	)
+	for _, tx := range c.pending {
+		id := tx.ID()
+		switch {
+		case tx.Base.Timestamp < now:
+			outcomes = append(outcomes, &Outcome{TxID: id, Err: rpc.ErrExpired})
+			delete(c.dropped, id)
+			continue
+		case hasKey(c.dropped, id):
+			keep = append(keep, tx)
+			continue
+		case c.cfg.MaxBlockTxs > 0 && len(txs) >= c.cfg.MaxBlockTxs:
+			keep = append(keep, tx)
+			continue
+		case c.cfg.RejectRate > 0 && c.rng.Float64() < c.cfg.RejectRate:
+			outcomes = append(outcomes, &Outcome{TxID: id, Err: ErrInjected})
+			continue
+		}
+		result, err := c.ledger.Execute(tx, c.cfg.UnitPrices)
+		if err != nil {
+			outcomes = append(outcomes, &Outcome{TxID: id, Err: err})
+			continue
+		}
+		txs = append(txs, tx)
+		results = append(results, result)
+		outcomes = append(outcomes, &Outcome{TxID: id, Result: result})
+	}
+	c.pending = keep
+
+	// Namespace prices halve every block and rise with the bytes posted.
+	for ns, price := range c.nsPrices {
+		c.nsPrices[ns] = price / 2
+	}
+	for ns, size := range c.ledger.NamespaceUsage() {
+		c.nsPrices[ns] += c.cfg.NamespaceBasePrice * (1 + size/consts.KiB)
+	}
+
+	parent := c.last
+	blk, err := newBlock(&chain.StatefulBlock{
+		Prnt:   parent.ID,
+		Tmstmp: now,
+		Hght:   parent.Block.Hght + 1,
+		Txs:    txs,
+	}, results, c.cfg.UnitPrices)
+	if err != nil {
+		c.l.Unlock()
+		return err
+	}
+	c.last = blk
+	subscribers := append([]Subscriber(nil), c.subscribers...)
+	c.l.Unlock()
+
+	for _, s := range subscribers {
+		s.Accepted(blk, outcomes)
+	}
+	return nil
+}
+
+func hasKey[K comparable, V any](m map[K]V, k K) bool {
+	_, ok := m[k]
+	return ok
+}
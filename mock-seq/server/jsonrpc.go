@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// method handles a single JSON-RPC method. [params] is the raw params object
+// sent by the client.
+type method func(ctx context.Context, params json.RawMessage) (any, error)
+
+// jsonrpcHandler serves JSON-RPC 2.0 requests for methods in [service], the
+// way avalanchego's gorilla rpc services are exposed: "<service>.<method>",
+// with the first letter of the method case-insensitive.
+type jsonrpcHandler struct {
+	node    *Node
+	service string
+	methods map[string]method
+}
+
+type jsonrpcRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func (h *jsonrpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req jsonrpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := &jsonrpcResponse{JSONRPC: "2.0", ID: req.ID}
+	result, err := h.call(r.Context(), req.Method, req.Params)
+	if err != nil {
+		resp.Error = &jsonrpcError{Code: -32000, Message: err.Error()}
+	} else {
+		resp.Result = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *jsonrpcHandler) call(ctx context.Context, name string, params json.RawMessage) (any, error) {
+	service, methodName, ok := strings.Cut(name, ".")
+	if !ok || service != h.service {
+		return nil, &unknownMethodError{name}
+	}
+	m, ok := h.methods[strings.ToLower(methodName)]
+	if !ok {
+		return nil, &unknownMethodError{name}
+	}
+	if err := h.node.inject(ctx); err != nil {
+		return nil, err
+	}
+	return m(ctx, params)
+}
+
+type unknownMethodError struct {
+	method string
+}
+
+func (e *unknownMethodError) Error() string {
+	return "rpc: can't find method " + e.method
+}
+
+// decode unmarshals [params] into a new T. avalanchego clients send params
+// as a single object, but an array holding one object is accepted too.
+func decode[T any](params json.RawMessage) (*T, error) {
+	v := new(T)
+	if len(params) == 0 || string(params) == "null" {
+		return v, nil
+	}
+	if params[0] == '[' {
+		var arr []json.RawMessage
+		if err := json.Unmarshal(params, &arr); err != nil {
+			return nil, err
+		}
+		if len(arr) == 0 {
+			return v, nil
+		}
+		params = arr[0]
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
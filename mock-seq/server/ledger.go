@@ -0,0 +1,161 @@
+package server
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrUnknownContract   = errors.New("unknown contract")
+	ErrUnsupportedAction = errors.New("unsupported action")
+)
+
+// Ledger is the in-memory state of the mock chain. Only the native asset is
+// tracked.
+type Ledger struct {
+	l         sync.RWMutex
+	balances  map[codec.Address]uint64
+	contracts map[ids.ID][]byte
+	// namespaces counts the bytes posted to each namespace since the last
+	// block.
+	namespaces map[string]uint64
+}
+
+func NewLedger(funds map[codec.Address]uint64) *Ledger {
+	balances := make(map[codec.Address]uint64, len(funds))
+	for addr, amount := range funds {
+		balances[addr] = amount
+	}
+	return &Ledger{
+		balances:   balances,
+		contracts:  map[ids.ID][]byte{},
+		namespaces: map[string]uint64{},
+	}
+}
+
+func (l *Ledger) Balance(addr codec.Address) uint64 {
+	l.l.RLock()
+	defer l.l.RUnlock()
+
+	return l.balances[addr]
+}
+
+func (l *Ledger) Contract(id ids.ID) ([]byte, bool) {
+	l.l.RLock()
+	defer l.l.RUnlock()
+
+	code, ok := l.contracts[id]
+	return code, ok
+}
+
+// Units is a rough estimate of the resources [tx] consumes. It is always at
+// most what the client estimated, so the max fee covers it.
+func Units(tx *chain.Transaction) fees.Dimensions {
+	var units fees.Dimensions
+	units[fees.Bandwidth] = uint64(tx.Size())
+	units[fees.Compute] = 1 + uint64(len(tx.Actions))
+	units[fees.StorageRead] = 2 * uint64(len(tx.Actions))
+	units[fees.StorageWrite] = 2 * uint64(len(tx.Actions))
+	return units
+}
+
+// Execute charges the fee for [tx] and applies its actions. It returns an
+// error if [tx] can't pay its fee, in which case it is not included.
+// Actions are applied all or nothing.
+func (l *Ledger) Execute(tx *chain.Transaction, prices fees.Dimensions) (*chain.Result, error) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	units := Units(tx)
+	fee, err := fees.MulSum(prices, units)
+	if err != nil {
+		return nil, err
+	}
+	fee = min(fee, tx.Base.MaxFee)
+	sponsor := tx.Auth.Sponsor()
+	if l.balances[sponsor] < fee {
+		return nil, ErrInsufficientFunds
+	}
+	l.balances[sponsor] -= fee
+	result := &chain.Result{
+		Success: true,
+		Outputs: make([][][]byte, len(tx.Actions)),
+		Units:   units,
+		Fee:     fee,
+	}
+
+	// Stage changes so a failed action doesn't leave partial state behind.
+	balances := map[codec.Address]uint64{}
+	balance := func(addr codec.Address) uint64 {
+		if b, ok := balances[addr]; ok {
+			return b
+		}
+		return l.balances[addr]
+	}
+	contracts := map[ids.ID][]byte{}
+	namespaces := map[string]uint64{}
+	actor := tx.Auth.Actor()
+	for i, act := range tx.Actions {
+		var err error
+		switch a := act.(type) {
+		case *actions.Transfer:
+			if a.Asset != ids.Empty {
+				err = ErrUnsupportedAction
+				break
+			}
+			if balance(actor) < a.Value {
+				err = ErrInsufficientFunds
+				break
+			}
+			balances[actor] = balance(actor) - a.Value
+			balances[a.To] = balance(a.To) + a.Value
+		case *actions.SequencerMsg:
+			namespaces[string(a.ChainId)] += uint64(len(a.Data))
+		case *actions.Deploy:
+			id := chain.CreateActionID(tx.ID(), uint8(i))
+			contracts[id] = a.ContractCode
+			result.Outputs[i] = [][]byte{id[:]}
+		case *actions.Transact:
+			_, staged := contracts[a.ContractAddress]
+			if _, ok := l.contracts[a.ContractAddress]; !ok && !staged {
+				err = ErrUnknownContract
+			}
+		default:
+			err = ErrUnsupportedAction
+		}
+		if err != nil {
+			result.Success = false
+			result.Error = []byte(err.Error())
+			result.Outputs = nil
+			return result, nil
+		}
+	}
+	for addr, b := range balances {
+		l.balances[addr] = b
+	}
+	for id, code := range contracts {
+		l.contracts[id] = code
+	}
+	for ns, size := range namespaces {
+		l.namespaces[ns] += size
+	}
+	return result, nil
+}
+
+// NamespaceUsage returns the bytes posted to each namespace since the last
+// call and resets the counters.
+func (l *Ledger) NamespaceUsage() map[string]uint64 {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	usage := l.namespaces
+	l.namespaces = map[string]uint64{}
+	return usage
+}
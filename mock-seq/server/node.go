@@ -0,0 +1,407 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/codec"
+	hconsts "github.com/AnomalyFi/hypersdk/consts"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/pubsub"
+	"github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/AnomalyFi/hypersdk/utils"
+	"github.com/AnomalyFi/nodekit-seq/consts"
+	"github.com/AnomalyFi/nodekit-seq/genesis"
+	_ "github.com/AnomalyFi/nodekit-seq/registry" // fills the action and auth registries
+	trpc "github.com/AnomalyFi/nodekit-seq/rpc"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"go.uber.org/zap"
+)
+
+// Node serves the APIs of one node of the mock network. Every node shares
+// the same [Chain], so a tx sent to one is seen by all.
+type Node struct {
+	chain *Chain
+	name  string
+
+	listener net.Listener
+	server   *http.Server
+	ws       *pubsub.Server
+
+	// MessageNetPort is returned by the messageNetPort API.
+	messageNetPort string
+
+	l      sync.Mutex
+	txs    map[ids.ID]*pubsub.Connection
+	blocks *pubsub.Connections
+}
+
+// NewNode listens on [addr]. [messageNetPort] is what the node reports as
+// its message net port, e.g. ":9560".
+func NewNode(c *Chain, name, addr, messageNetPort string) (*Node, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{
+		chain:          c,
+		name:           name,
+		listener:       listener,
+		messageNetPort: messageNetPort,
+		txs:            map[ids.ID]*pubsub.Connection{},
+		blocks:         pubsub.NewConnections(),
+	}
+	n.ws = pubsub.New(c.cfg.Log, pubsub.NewDefaultServerConfig(), n.onMessage)
+
+	hypersdk := &jsonrpcHandler{node: n, service: hconsts.Name, methods: map[string]method{
+		"ping":           n.ping,
+		"network":        n.network,
+		"lastaccepted":   n.lastAccepted,
+		"unitprices":     n.unitPrices,
+		"submittx":       n.submitTx,
+		"namespaceprice": n.nameSpacePrice,
+	}}
+	seq := &jsonrpcHandler{node: n, service: consts.Name, methods: map[string]method{
+		"genesis":        n.genesis,
+		"balance":        n.balance,
+		"messagenetport": n.getMessageNetPort,
+		"getcontract":    n.getContract,
+	}}
+	info := &jsonrpcHandler{node: n, service: "info", methods: map[string]method{
+		"getnodeversion": n.getNodeVersion,
+	}}
+	base := "/ext/bc/" + c.cfg.ChainID.String()
+	mux := http.NewServeMux()
+	mux.Handle("/ext/info", info)
+	mux.Handle(base+rpc.JSONRPCEndpoint, hypersdk)
+	mux.Handle(base+trpc.JSONRPCEndpoint, seq)
+	mux.Handle(base+rpc.WebSocketEndpoint, n.ws)
+	n.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	c.Subscribe(n)
+	return n, nil
+}
+
+func (n *Node) Name() string {
+	return n.name
+}
+
+// URI is the chain URI clients should use, like the ones ANR reports.
+func (n *Node) URI() string {
+	return fmt.Sprintf("http://%s/ext/bc/%s", n.listener.Addr(), n.chain.cfg.ChainID)
+}
+
+// Serve blocks until the node is closed.
+func (n *Node) Serve() error {
+	err := n.server.Serve(n.listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (n *Node) Close() error {
+	return n.server.Close()
+}
+
+// inject applies the configured latency and failure rate to a call.
+func (n *Node) inject(ctx context.Context) error {
+	if d := n.chain.cfg.Latency; d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if n.chain.roll(n.chain.cfg.FailRate) {
+		return ErrInjected
+	}
+	return nil
+}
+
+type pingReply struct {
+	Success bool `json:"success"`
+}
+
+func (*Node) ping(context.Context, json.RawMessage) (any, error) {
+	return &pingReply{Success: true}, nil
+}
+
+type networkReply struct {
+	NetworkID uint32 `json:"networkId"`
+	SubnetID  ids.ID `json:"subnetId"`
+	ChainID   ids.ID `json:"chainId"`
+}
+
+func (n *Node) network(context.Context, json.RawMessage) (any, error) {
+	return &networkReply{
+		NetworkID: n.chain.cfg.NetworkID,
+		SubnetID:  n.chain.cfg.SubnetID,
+		ChainID:   n.chain.cfg.ChainID,
+	}, nil
+}
+
+type nodeVersionReply struct {
+	Version            string            `json:"version"`
+	DatabaseVersion    string            `json:"databaseVersion"`
+	RPCProtocolVersion string            `json:"rpcProtocolVersion"`
+	GitCommit          string            `json:"gitCommit"`
+	VMVersions         map[string]string `json:"vmVersions"`
+}
+
+// getNodeVersion answers the avalanchego info API, so tools that record the
+// node version see the mock for what it is.
+func (n *Node) getNodeVersion(context.Context, json.RawMessage) (any, error) {
+	return &nodeVersionReply{
+		Version:            "avalanchego/mock",
+		DatabaseVersion:    "mock",
+		RPCProtocolVersion: "0",
+		GitCommit:          "mock",
+		VMVersions:         map[string]string{consts.Name: "mock"},
+	}, nil
+}
+
+type lastAcceptedReply struct {
+	Height    uint64 `json:"height"`
+	BlockID   ids.ID `json:"blockId"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+func (n *Node) lastAccepted(context.Context, json.RawMessage) (any, error) {
+	blk := n.chain.LastAccepted()
+	return &lastAcceptedReply{
+		Height:    blk.Block.Hght,
+		BlockID:   blk.ID,
+		Timestamp: blk.Block.Tmstmp,
+	}, nil
+}
+
+type unitPricesReply struct {
+	UnitPrices fees.Dimensions `json:"unitPrices"`
+}
+
+func (n *Node) unitPrices(context.Context, json.RawMessage) (any, error) {
+	return &unitPricesReply{UnitPrices: n.chain.cfg.UnitPrices}, nil
+}
+
+type submitTxArgs struct {
+	Tx []byte `json:"tx"`
+}
+
+type submitTxReply struct {
+	TxID ids.ID `json:"txId"`
+}
+
+func (n *Node) submitTx(_ context.Context, params json.RawMessage) (any, error) {
+	args, err := decode[submitTxArgs](params)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := parseTx(args.Tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.chain.Submit(tx); err != nil {
+		return nil, err
+	}
+	return &submitTxReply{TxID: tx.ID()}, nil
+}
+
+type nameSpacePriceArgs struct {
+	NameSpace string `json:"namespace"`
+}
+
+type nameSpacePriceReply struct {
+	Price uint64 `json:"price"`
+}
+
+func (n *Node) nameSpacePrice(_ context.Context, params json.RawMessage) (any, error) {
+	args, err := decode[nameSpacePriceArgs](params)
+	if err != nil {
+		return nil, err
+	}
+	return &nameSpacePriceReply{Price: n.chain.NamespacePrice(args.NameSpace)}, nil
+}
+
+func (n *Node) genesis(context.Context, json.RawMessage) (any, error) {
+	return &trpc.GenesisReply{Genesis: genesis.Default()}, nil
+}
+
+func (n *Node) balance(_ context.Context, params json.RawMessage) (any, error) {
+	args, err := decode[trpc.BalanceArgs](params)
+	if err != nil {
+		return nil, err
+	}
+	if args.Asset != ids.Empty {
+		return &trpc.BalanceReply{}, nil
+	}
+	addr, err := parseAddress(args.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &trpc.BalanceReply{Amount: n.chain.ledger.Balance(addr)}, nil
+}
+
+type messageNetPortReply struct {
+	Port string `json:"port"`
+}
+
+func (n *Node) getMessageNetPort(context.Context, json.RawMessage) (any, error) {
+	return &messageNetPortReply{Port: n.messageNetPort}, nil
+}
+
+type getContractArgs struct {
+	ContractAddress ids.ID `json:"contractAddress"`
+}
+
+type getContractReply struct {
+	Code []byte `json:"code"`
+}
+
+func (n *Node) getContract(_ context.Context, params json.RawMessage) (any, error) {
+	args, err := decode[getContractArgs](params)
+	if err != nil {
+		return nil, err
+	}
+	code, ok := n.chain.ledger.Contract(args.ContractAddress)
+	if !ok {
+		return nil, ErrUnknownContract
+	}
+	return &getContractReply{Code: code}, nil
+}
+
+// parseAddress accepts an address with any HRP, since the tools don't agree
+// on one.
+func parseAddress(s string) (codec.Address, error) {
+	_, b, err := address.ParseBech32(s)
+	if err != nil {
+		return codec.EmptyAddress, err
+	}
+	if len(b) != codec.AddressLen {
+		return codec.EmptyAddress, fmt.Errorf("invalid address length %d", len(b))
+	}
+	return codec.Address(b), nil
+}
+
+// parseTx decodes a tx. Signatures are not verified.
+func parseTx(b []byte) (*chain.Transaction, error) {
+	p := codec.NewReader(b, hconsts.NetworkSizeLimit)
+	tx, err := chain.UnmarshalTx(p, consts.ActionRegistry, consts.AuthRegistry)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Empty() {
+		return nil, errors.New("tx has trailing bytes")
+	}
+	return tx, nil
+}
+
+// onMessage handles a message sent over the websocket: either a block
+// subscription or a tx to issue and report the result of.
+func (n *Node) onMessage(msg []byte, c *pubsub.Connection) {
+	if len(msg) == 0 {
+		return
+	}
+	switch msg[0] {
+	case rpc.BlockMode:
+		n.blocks.Add(c)
+	case rpc.TxMode:
+		tx, err := parseTx(msg[1:])
+		if err != nil {
+			// The client knows the tx by the ID of the bytes it sent
+			n.send(c, &Outcome{TxID: utils.ToID(msg[1:]), Err: err})
+			return
+		}
+		if n.chain.roll(n.chain.cfg.FailRate) {
+			n.send(c, &Outcome{TxID: tx.ID(), Err: ErrInjected})
+			return
+		}
+		n.l.Lock()
+		n.txs[tx.ID()] = c
+		n.l.Unlock()
+		if err := n.chain.Submit(tx); err != nil {
+			n.sendTx(&Outcome{TxID: tx.ID(), Err: err})
+		}
+	}
+}
+
+// Accepted sends the block to block subscribers and tx results to whoever
+// issued them through this node.
+func (n *Node) Accepted(blk *Block, outcomes []*Outcome) {
+	send := func() {
+		for _, o := range outcomes {
+			n.sendTx(o)
+		}
+		if len(n.blocks.Conns()) == 0 {
+			return
+		}
+		msg, err := packBlockMessage(blk)
+		if err != nil {
+			n.chain.cfg.Log.Error("failed to pack block", zap.Stringer("blkID", blk.ID), zap.Error(err))
+			return
+		}
+		for _, c := range n.ws.Publish(append([]byte{rpc.BlockMode}, msg...), n.blocks) {
+			n.blocks.Remove(c)
+		}
+	}
+	if d := n.chain.cfg.Latency; d > 0 {
+		time.AfterFunc(d, send)
+		return
+	}
+	send()
+}
+
+func (n *Node) sendTx(o *Outcome) {
+	n.l.Lock()
+	c, ok := n.txs[o.TxID]
+	delete(n.txs, o.TxID)
+	n.l.Unlock()
+	if !ok {
+		return
+	}
+	n.send(c, o)
+}
+
+// send reports [o] to the client that issued it over [c].
+func (n *Node) send(c *pubsub.Connection, o *Outcome) {
+	var (
+		msg []byte
+		err error
+	)
+	if o.Err != nil {
+		msg, err = rpc.PackRemovedTxMessage(o.TxID, o.Err)
+	} else {
+		msg, err = rpc.PackAcceptedTxMessage(o.TxID, o.Result)
+	}
+	if err != nil {
+		n.chain.cfg.Log.Error("failed to pack tx result", zap.Stringer("txID", o.TxID), zap.Error(err))
+		return
+	}
+	c.Send(append([]byte{rpc.TxMode}, msg...))
+}
+
+// packBlockMessage encodes a block the way rpc.UnpackBlockMessage expects.
+func packBlockMessage(blk *Block) ([]byte, error) {
+	results, err := chain.MarshalResults(blk.Results)
+	if err != nil {
+		return nil, err
+	}
+	size := codec.BytesLen(blk.Bytes) + codec.BytesLen(results) + fees.DimensionsLen
+	p := codec.NewWriter(size, hconsts.MaxInt)
+	p.PackBytes(blk.Bytes)
+	p.PackBytes(results)
+	p.PackFixedBytes(blk.Prices.Bytes())
+	return p.Bytes(), p.Err()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/tools/common/credentials"
+	"github.com/AnomalyFi/tools/mock-seq/server"
+	"github.com/AnomalyFi/tools/seq-wasm-tools/contract"
+)
+
+// outcomes collects the outcome of every tx the chain accepts.
+type outcomes struct {
+	l sync.Mutex
+	m map[ids.ID]*server.Outcome
+}
+
+func (o *outcomes) Accepted(_ *server.Block, accepted []*server.Outcome) {
+	o.l.Lock()
+	defer o.l.Unlock()
+
+	for _, a := range accepted {
+		o.m[a.TxID] = a
+	}
+}
+
+// wait returns the outcome of [txID] once a block has it.
+func (o *outcomes) wait(t *testing.T, txID ids.ID) *server.Outcome {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		o.l.Lock()
+		a, ok := o.m[txID]
+		o.l.Unlock()
+		if ok {
+			return a
+		}
+	}
+	t.Fatalf("tx %s wasn't accepted", txID)
+	return nil
+}
+
+// failure is why the tx of [o] failed, empty if it succeeded.
+func failure(o *server.Outcome) string {
+	switch {
+	case o.Err != nil:
+		return o.Err.Error()
+	case !o.Result.Success:
+		return string(o.Result.Error)
+	}
+	return ""
+}
+
+// TestWasmContract deploys the blobstream contract with seq-wasm-tools and
+// calls it, as its deploy and commit_header mains do.
+func TestWasmContract(t *testing.T) {
+	c, uris := startChain(t, 1)
+	accepted := &outcomes{m: map[ids.ID]*server.Outcome{}}
+	c.Subscribe(accepted)
+	code, err := os.ReadFile("../seq-wasm-tools/blobstream.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cli, err := contract.New(ctx, uris[0], c.Config().NetworkID, c.Config().ChainID, credentials.DevKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployTx, err := cli.Deploy(ctx, code, "initializer", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := failure(accepted.wait(t, deployTx)); len(f) > 0 {
+		t.Fatalf("deploy failed: %s", f)
+	}
+	address := contract.Address(deployTx)
+	if got, ok := c.Ledger().Contract(address); !ok || len(got) != len(code) {
+		t.Fatalf("no contract of %d bytes at %s", len(code), address)
+	}
+
+	callTx, err := cli.Call(ctx, address, "commit_header_range", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := failure(accepted.wait(t, callTx)); len(f) > 0 {
+		t.Fatalf("call failed: %s", f)
+	}
+	// A contract that was never deployed can't be called
+	callTx, err = cli.Call(ctx, ids.GenerateTestID(), "commit_header_range", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failure(accepted.wait(t, callTx))) == 0 {
+		t.Fatal("called a contract that doesn't exist")
+	}
+}
@@ -4,20 +4,21 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/AnomalyFi/hypersdk/utils"
-	"github.com/ava-labs/avalanchego/ids"
+	"github.com/AnomalyFi/nodeid2port/port"
 )
 
 func main() {
 	argsWithoutProg := os.Args[1:]
+	if len(argsWithoutProg) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s <node-id>\n", os.Args[0])
+		os.Exit(2)
+	}
 
-	nodeIDString := argsWithoutProg[0]
 	// starting val server for node(NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg) at port: 33549
-	id, err := ids.NodeIDFromString(nodeIDString)
+	p, err := port.FromNodeID(argsWithoutProg[0])
 	if err != nil {
-		fmt.Printf("unable to parse nodeID: %s\n", err.Error())
+		fmt.Println(err)
 		os.Exit(1)
 	}
-	port := utils.GetPortFromNodeID(id)
-	fmt.Printf("%d", port)
+	fmt.Printf("%d", p)
 }
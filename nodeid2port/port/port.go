@@ -0,0 +1,20 @@
+// Package port derives the port the message net of a node listens on from
+// its node ID.
+package port
+
+import (
+	"fmt"
+
+	"github.com/AnomalyFi/hypersdk/utils"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// FromNodeID returns the port of the node with ID [nodeID], e.g.
+// NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg.
+func FromNodeID(nodeID string) (uint16, error) {
+	id, err := ids.NodeIDFromString(nodeID)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse nodeID: %w", err)
+	}
+	return utils.GetPortFromNodeID(id), nil
+}
@@ -0,0 +1,96 @@
+// Package config generates oracle configs for the SEQ chain of an ANR
+// cluster.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	hrpc "github.com/AnomalyFi/hypersdk/rpc"
+
+	"github.com/AnomalyFi/tools/common/anr"
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+// DefaultNode picks the node an oracle talks to when none is asked for: the
+// third serving the chain, or the last one of smaller clusters.
+const DefaultNode = -1
+
+// preferredNode is the node [DefaultNode] picks if the chain has it.
+const preferredNode = 2
+
+type Config struct {
+	SEQConfig SEQConfig `json:"seq_config"`
+	LogConfig LogConfig `json:"log_config"`
+}
+
+type SEQConfig struct {
+	SEQNodeUri           string `json:"seq_node_uri"`
+	ChainIDStr           string `json:"chain_id_str"`
+	NetworkID            uint32 `json:"network_id"`
+	Ed25519PrivateKeyHex string `json:"ed25519_private_key_hex"`
+}
+
+type LogConfig struct {
+	LoggingLevel string `json:"log_level"`
+	ToConsole    bool   `json:"to_console"`
+	LogFile      string `json:"log_file"`
+}
+
+// Load reads a config template.
+func Load(path string) (*Config, error) {
+	configBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %s", path)
+	}
+	var config Config
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		// The template may hold secrets, so it isn't echoed
+		return nil, fmt.Errorf("unable to parse config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Generate points [template] at [endpoint].
+func Generate(template Config, endpoint *anr.Endpoint, networkID uint32) Config {
+	config := template
+	config.SEQConfig.ChainIDStr = endpoint.ChainID.String()
+	config.SEQConfig.NetworkID = networkID
+	config.SEQConfig.SEQNodeUri = endpoint.URI
+	return config
+}
+
+// Redacted returns a copy of [c] that is safe to print.
+func (c Config) Redacted() Config {
+	c.SEQConfig.Ed25519PrivateKeyHex = credentials.Redact(c.SEQConfig.Ed25519PrivateKeyHex)
+	return c
+}
+
+// Discover picks the [i]th node serving [chain], see [Pick], and asks it for
+// the network ID; [Generate] makes the config from both.
+func Discover(ctx context.Context, cluster *anr.Cluster, chain *anr.Chain, i int) (*anr.Endpoint, uint32, error) {
+	endpoint, err := Pick(cluster, chain, i)
+	if err != nil {
+		return nil, 0, err
+	}
+	networkID, _, _, err := hrpc.NewJSONRPCClient(endpoint.URI).Network(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return endpoint, networkID, nil
+}
+
+// Pick returns the [i]th endpoint serving [chain], or the one
+// [DefaultNode] picks.
+func Pick(cluster *anr.Cluster, chain *anr.Chain, i int) (*anr.Endpoint, error) {
+	endpoints := cluster.Serving(chain.ID)
+	if i == DefaultNode && len(endpoints) > 0 {
+		i = min(preferredNode, len(endpoints)-1)
+	}
+	if i < 0 || i >= len(endpoints) {
+		return nil, fmt.Errorf("node %d requested but only %d nodes serve chain %s", i, len(endpoints), chain.ID)
+	}
+	return endpoints[i], nil
+}
@@ -1,3 +1,7 @@
 module github.com/AnomalyFi/nodekit-tools/oracle-tools
 
 go 1.22.2
+
+require github.com/AnomalyFi/tools/common v0.0.0-00010101000000-000000000000
+
+replace github.com/AnomalyFi/tools/common => ../common
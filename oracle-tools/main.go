@@ -3,116 +3,92 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
-	"strings"
-	"time"
 
-	hrpc "github.com/AnomalyFi/hypersdk/rpc"
-	"github.com/ava-labs/avalanche-network-runner/client"
-	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/AnomalyFi/nodekit-tools/oracle-tools/config"
+	"github.com/AnomalyFi/tools/common/anr"
+	commonconfig "github.com/AnomalyFi/tools/common/config"
+	"github.com/AnomalyFi/tools/common/credentials"
 )
 
-type Config struct {
-	SEQConfig SEQConfig `json:"seq_config"`
-	LogConfig LogConfig `json:"log_config"`
-}
-
-type SEQConfig struct {
-	SEQNodeUri           string `json:"seq_node_uri"`
-	ChainIDStr           string `json:"chain_id_str"`
-	NetworkID            uint32 `json:"network_id"`
-	Ed25519PrivateKeyHex string `json:"ed25519_private_key_hex"`
-}
+var (
+	anrEndpoint = flag.String("anr", anr.DefaultEndpoint, "avalanche-network-runner control server")
+	chain       = flag.String("chain", "", "name or ID of the custom chain to use (required if the cluster has several)")
+	node        = flag.Int("node", config.DefaultNode, "index of the node, among those serving the chain, the oracle talks to (-1: the third, or the last of smaller clusters)")
+	networkName = commonconfig.NetworkFlag(flag.CommandLine)
 
-type LogConfig struct {
-	LoggingLevel string `json:"log_level"`
-	ToConsole    bool   `json:"to_console"`
-	LogFile      string `json:"log_file"`
-}
+	keySpec, allowDevKey = credentials.Flags(flag.CommandLine)
+)
 
 func main() {
+	flag.Parse()
 	ctx := context.Background()
 
 	// read config file tempelate
-	args := os.Args[1:]
+	args := flag.Args()
 	if len(args) < 1 {
 		panic("Please specify config file path")
 	}
 	fmt.Println(args)
 
-	configBytes, err := os.ReadFile(args[0])
+	template, err := config.Load(args[0])
 	if err != nil {
-		panic(fmt.Sprintf("unable to open file %s\n", args[0]))
+		panic(err)
 	}
-
-	var config Config
-	if err := json.Unmarshal(configBytes, &config); err != nil {
-		panic(fmt.Sprintf("unable to parse config:\n %s\n", string(configBytes)))
+	// A named network replaces --anr and --chain
+	if len(*networkName) > 0 {
+		n, err := commonconfig.LoadNetwork(*networkName)
+		if err != nil {
+			panic(err)
+		}
+		if n.Discovery() != "anr" {
+			panic(fmt.Sprintf("network %s isn't discovered through ANR", *networkName))
+		}
+		*anrEndpoint, *chain = n.ANR, n.Chain
 	}
 	// Load new items from ANR
-	anrCli, err := client.New(client.Config{
-		Endpoint:    "0.0.0.0:12352",
-		DialTimeout: 10 * time.Second,
-	}, logging.NoLog{})
+	cluster, err := anr.Load(ctx, *anrEndpoint)
 	if err != nil {
 		panic(err)
 	}
-	status, err := anrCli.Status(ctx)
+	seqChain, err := cluster.Chain(*chain)
 	if err != nil {
 		panic(err)
 	}
-	subnets := map[ids.ID][]ids.ID{}
-	uris := []string{}
-	for chain, chainInfo := range status.ClusterInfo.CustomChains {
-		chainID, err := ids.FromString(chain)
-		if err != nil {
-			panic(err)
-		}
-		subnetID, err := ids.FromString(chainInfo.SubnetId)
-		if err != nil {
-			panic(err)
-		}
-		chainIDs, ok := subnets[subnetID]
-		if !ok {
-			chainIDs = []ids.ID{}
-		}
-		chainIDs = append(chainIDs, chainID)
-		subnets[subnetID] = chainIDs
+	// pick the node and fetch network id
+	endpoint, networkID, err := config.Discover(ctx, cluster, seqChain, *node)
+	if err != nil {
+		panic(err)
 	}
-	var filledChainID ids.ID
-	for _, nodeInfo := range status.ClusterInfo.NodeInfos {
-		if len(nodeInfo.WhitelistedSubnets) == 0 {
-			continue
-		}
-		trackedSubnets := strings.Split(nodeInfo.WhitelistedSubnets, ",")
-		for _, subnet := range trackedSubnets {
-			subnetID, err := ids.FromString(subnet)
-			if err != nil {
-				panic(err)
-			}
-			for _, chainID := range subnets[subnetID] {
-				uri := fmt.Sprintf("%s/ext/bc/%s", nodeInfo.Uri, chainID)
-				uris = append(uris, uri)
-				filledChainID = chainID
-			}
-		}
+	// update config
+	c := config.Generate(*template, endpoint, networkID)
+	// a key in the template is kept unless --key asks for another, either
+	// way the dev key is refused on networks that aren't local
+	var key *credentials.Key
+	if len(c.SEQConfig.Ed25519PrivateKeyHex) == 0 || len(*keySpec) > 0 {
+		key, err = credentials.Load(*keySpec, credentials.ED25519)
+	} else {
+		key, err = credentials.Parse(c.SEQConfig.Ed25519PrivateKeyHex, credentials.ED25519, args[0])
 	}
-	// fetch network id
-	hcli := hrpc.NewJSONRPCClient(uris[2])
-	networkID, _, _, err := hcli.Network(ctx)
 	if err != nil {
 		panic(err)
 	}
-	// update config
-	config.SEQConfig.ChainIDStr = filledChainID.String()
-	config.SEQConfig.NetworkID = networkID
-	config.SEQConfig.SEQNodeUri = uris[2]
+	if err := key.CheckNetwork([]string{endpoint.URI}, *allowDevKey); err != nil {
+		panic(err)
+	}
+	c.SEQConfig.Ed25519PrivateKeyHex = key.Hex()
 	// write to config file
-	d, err := json.Marshal(config)
+	d, err := json.Marshal(c)
 	if err != nil {
 		panic(err)
 	}
-	fmt.Println(os.WriteFile("config"+".json", d, 0644))
+	if err := os.WriteFile("config"+".json", d, 0o600); err != nil {
+		panic(err)
+	}
+	if d, err = json.Marshal(c.Redacted()); err != nil {
+		panic(err)
+	}
+	fmt.Println("config.json", string(d))
 }
@@ -2,113 +2,173 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
-	hrpc "github.com/AnomalyFi/hypersdk/rpc"
-	"github.com/ava-labs/avalanche-network-runner/client"
-	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/poll"
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/sim"
+	"github.com/AnomalyFi/tools/common/anr"
+	"github.com/AnomalyFi/tools/common/config"
+)
+
+var (
+	urisOverride = flag.String("uris", "", "comma separated chain URIs to poll instead of the ones in ANR, e.g. the ones printed by mock-seq")
+	anrEndpoint  = flag.String("anr", anr.DefaultEndpoint, "avalanche-network-runner control server")
+	chain        = flag.String("chain", "", "name or ID of the custom chain to poll (required if the cluster has several)")
+	networkName  = config.NetworkFlag(flag.CommandLine)
+	namespaces   = flag.String("namespaces", poll.DefaultNamespace, "comma separated namespaces whose price is polled")
+	interval     = flag.Duration("interval", 500*time.Millisecond, "how often every node is asked")
+	timeout      = flag.Duration("timeout", 0, "how long the nodes have to answer in a round (default: the interval)")
+	record       = flag.String("record", "", "append every reading to this CSV file, or SQLite database if it ends in .db, .sqlite or .sqlite3")
+	changes      = flag.Bool("changes", false, "only print prices that move instead of every round")
+	watch        = flag.Bool("watch", false, "instead of polling every interval, ask for the prices whenever an accepted block posts to the namespaces")
+	traffic      = flag.String("traffic", "", "with --watch, append the SequencerMsg volume of every block that posts to the namespaces to this CSV file")
 )
 
 func main() {
-	ctx := context.Background()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "summary":
+			summary(os.Args[2:])
+			return
+		case "simulate":
+			simulate(os.Args[2:])
+			return
+		}
+	}
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var uris []string
+	switch {
+	case len(*urisOverride) > 0:
+		uris = strings.Split(*urisOverride, ",")
+	case len(*networkName) > 0:
+		target, err := config.ResolveNetwork(ctx, *networkName)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("chain id", target.ChainID)
+		uris = target.URIs
+	default:
+		// Load new items from ANR
+		cluster, err := anr.Load(ctx, *anrEndpoint)
+		if err != nil {
+			panic(err)
+		}
+		seqChain, err := cluster.Chain(*chain)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("chain id", seqChain.ID)
+		uris = cluster.URIs(seqChain.ID)
+	}
+	opts := poll.Options{
+		Namespaces: poll.SplitNamespaces(*namespaces),
+		Interval:   *interval,
+		Timeout:    *timeout,
+		Record:     *record,
+		Changes:    *changes,
+		Traffic:    *traffic,
+	}
+	run := poll.Poll
+	if *watch {
+		run = poll.Watch
+	}
+	if err := run(ctx, uris, opts, os.Stdout); err != nil {
+		panic(err)
+	}
+}
+
+// summary prints the summary of a recorded poll.
+func summary(args []string) {
+	fs := flag.NewFlagSet("summary", flag.ExitOnError)
+	since := fs.String("since", "", "start of the window: RFC 3339 time, or duration before now (default: the first round)")
+	until := fs.String("until", "", "end of the window: RFC 3339 time, or duration before now (default: the last round)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s summary [flags] <recording>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
 
-	// Load new items from ANR
-	anrCli, err := client.New(client.Config{
-		Endpoint:    "0.0.0.0:12352",
-		DialTimeout: 10 * time.Second,
-	}, logging.NoLog{})
+	now := time.Now()
+	var from, to time.Time
+	var err error
+	if len(*since) > 0 {
+		if from, err = poll.ParseTime(*since, now); err != nil {
+			panic(err)
+		}
+	}
+	if len(*until) > 0 {
+		if to, err = poll.ParseTime(*until, now); err != nil {
+			panic(err)
+		}
+	}
+	rounds, err := poll.Load(fs.Arg(0))
 	if err != nil {
 		panic(err)
 	}
-	status, err := anrCli.Status(ctx)
+	poll.Summarize(rounds, from, to).Print(os.Stdout)
+}
+
+// simulate predicts namespace prices from recorded traffic.
+func simulate(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	modelPath := fs.String("model", "", "JSON file of the pricing model, or mock-seq for mock-seq's (default: hypersdk's fee window)")
+	out := fs.String("out", "", "write the predicted price after every block to this CSV file")
+	validate := fs.String("validate", "", "compare the predictions with the prices in this recording, e.g. of poll-namespace --watch --record")
+	width := fs.Int("width", 60, "width of the printed price curves")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s simulate [flags] <traffic>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	model := &sim.DefaultModel
+	if len(*modelPath) > 0 {
+		var err error
+		if model, err = sim.LoadModel(*modelPath); err != nil {
+			panic(err)
+		}
+	}
+	blocks, err := poll.LoadTraffic(fs.Arg(0))
 	if err != nil {
 		panic(err)
 	}
-	subnets := map[ids.ID][]ids.ID{}
-	uris := []string{}
-	for chain, chainInfo := range status.ClusterInfo.CustomChains {
-		chainID, err := ids.FromString(chain)
+	points := sim.Simulate(model, blocks)
+	sim.PrintCurves(os.Stdout, points, *width)
+	if len(*out) > 0 {
+		f, err := os.Create(*out)
 		if err != nil {
 			panic(err)
 		}
-		subnetID, err := ids.FromString(chainInfo.SubnetId)
-		if err != nil {
+		if err := sim.WriteCSV(f, points); err != nil {
 			panic(err)
 		}
-		chainIDs, ok := subnets[subnetID]
-		if !ok {
-			chainIDs = []ids.ID{}
+		if err := f.Close(); err != nil {
+			panic(err)
 		}
-		chainIDs = append(chainIDs, chainID)
-		subnets[subnetID] = chainIDs
 	}
-	var filledChainID ids.ID
-	for _, nodeInfo := range status.ClusterInfo.NodeInfos {
-		if len(nodeInfo.WhitelistedSubnets) == 0 {
-			continue
-		}
-		trackedSubnets := strings.Split(nodeInfo.WhitelistedSubnets, ",")
-		for _, subnet := range trackedSubnets {
-			subnetID, err := ids.FromString(subnet)
-			if err != nil {
-				panic(err)
-			}
-			for _, chainID := range subnets[subnetID] {
-				uri := fmt.Sprintf("%s/ext/bc/%s", nodeInfo.Uri, chainID)
-				uris = append(uris, uri)
-				filledChainID = chainID
-			}
+	if len(*validate) > 0 {
+		rounds, err := poll.Load(*validate)
+		if err != nil {
+			panic(err)
 		}
-	}
-
-	fmt.Println("chain id", filledChainID)
-	chainID := string([]byte("nkit"))
-	cli0 := hrpc.NewJSONRPCClient(uris[0])
-	cli1 := hrpc.NewJSONRPCClient(uris[1])
-	cli2 := hrpc.NewJSONRPCClient(uris[2])
-	cli3 := hrpc.NewJSONRPCClient(uris[3])
-	cli4 := hrpc.NewJSONRPCClient(uris[4])
-
-	for {
-
-		go func() {
-			price, err := cli0.NameSpacePrice(ctx, chainID)
-			if err != nil {
-				fmt.Println("error getting namespace price", err)
-			}
-			fmt.Println("namespace price 0", price)
-		}()
-		go func() {
-			price, err := cli1.NameSpacePrice(ctx, chainID)
-			if err != nil {
-				fmt.Println("error getting namespace price", err)
-			}
-			fmt.Println("namespace price 1", price)
-		}()
-		go func() {
-			price, err := cli2.NameSpacePrice(ctx, chainID)
-			if err != nil {
-				fmt.Println("error getting namespace price", err)
-			}
-			fmt.Println("namespace price 2", price)
-		}()
-		go func() {
-			price, err := cli3.NameSpacePrice(ctx, chainID)
-			if err != nil {
-				fmt.Println("error getting namespace price", err)
-			}
-			fmt.Println("namespace price 3", price)
-		}()
-		go func() {
-			price, err := cli4.NameSpacePrice(ctx, chainID)
-			if err != nil {
-				fmt.Println("error getting namespace price", err)
-			}
-			fmt.Println("namespace price 4", price)
-		}()
-		time.Sleep(500 * time.Millisecond)
+		fmt.Println()
+		sim.PrintFits(os.Stdout, sim.Validate(points, rounds))
 	}
 }
@@ -0,0 +1,49 @@
+package poll
+
+import (
+	"fmt"
+	"io"
+)
+
+type key struct {
+	node      int
+	namespace string
+}
+
+// Changes prints a line whenever the price a node reports for a namespace
+// moves, or the node starts or stops failing, and nothing otherwise.
+type Changes struct {
+	out    io.Writer
+	prices map[key]uint64
+	failed map[key]bool
+}
+
+func NewChanges(out io.Writer) *Changes {
+	return &Changes{out: out, prices: map[key]uint64{}, failed: map[key]bool{}}
+}
+
+func (c *Changes) Round(r *Round) {
+	at := r.Time.Format("15:04:05.000")
+	for _, reading := range r.Readings {
+		k := key{reading.Node, reading.Namespace}
+		if reading.Err != nil {
+			if !c.failed[k] {
+				fmt.Fprintf(c.out, "%s %s node %d: error: %v\n", at, reading.Namespace, reading.Node, reading.Err)
+				c.failed[k] = true
+			}
+			continue
+		}
+		if c.failed[k] {
+			fmt.Fprintf(c.out, "%s %s node %d: answering again\n", at, reading.Namespace, reading.Node)
+			delete(c.failed, k)
+		}
+		price, seen := c.prices[k]
+		switch {
+		case !seen:
+			fmt.Fprintf(c.out, "%s %s node %d: %d\n", at, reading.Namespace, reading.Node, reading.Price)
+		case price != reading.Price:
+			fmt.Fprintf(c.out, "%s %s node %d: %d -> %d\n", at, reading.Namespace, reading.Node, price, reading.Price)
+		}
+		c.prices[k] = reading.Price
+	}
+}
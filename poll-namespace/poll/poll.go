@@ -0,0 +1,307 @@
+// Package poll asks every node of a chain for the price of namespaces, round
+// after round, and reports when the nodes disagree.
+package poll
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	hrpc "github.com/AnomalyFi/hypersdk/rpc"
+)
+
+// DefaultNamespace is the namespace of the NodeKit rollups.
+const DefaultNamespace = "nkit"
+
+// SplitNamespaces splits a comma separated list of namespaces.
+func SplitNamespaces(s string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(s, ",") {
+		if ns = strings.TrimSpace(ns); len(ns) > 0 {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// Reading is the price one node reported for one namespace.
+type Reading struct {
+	Node      int
+	URI       string
+	Namespace string
+	Price     uint64
+	Err       error
+}
+
+// Round is one reading per node and namespace, ordered by node then
+// namespace.
+type Round struct {
+	N        int
+	Time     time.Time
+	Readings []*Reading
+}
+
+// Prices returns the price every node that answered reported for
+// [namespace], by node.
+func (r *Round) Prices(namespace string) map[int]uint64 {
+	prices := map[int]uint64{}
+	for _, reading := range r.Readings {
+		if reading.Namespace == namespace && reading.Err == nil {
+			prices[reading.Node] = reading.Price
+		}
+	}
+	return prices
+}
+
+// Rounds asks every node in [uris] for the price of every namespace each
+// [interval] and passes the round to [handle] once all answered or
+// [timeout] passed, until [ctx] is done.
+func Rounds(ctx context.Context, uris []string, namespaces []string, interval time.Duration, timeout time.Duration, handle func(*Round)) {
+	clients := make([]*hrpc.JSONRPCClient, len(uris))
+	for i, uri := range uris {
+		clients[i] = hrpc.NewJSONRPCClient(uri)
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for n := 0; ; n++ {
+		round := ask(ctx, clients, uris, namespaces, timeout)
+		if ctx.Err() != nil {
+			return
+		}
+		round.N = n
+		handle(round)
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ask asks every node for the price of every namespace, giving them
+// [timeout] to answer.
+func ask(ctx context.Context, clients []*hrpc.JSONRPCClient, uris []string, namespaces []string, timeout time.Duration) *Round {
+	round := &Round{Time: time.Now()}
+	for i := range clients {
+		for _, ns := range namespaces {
+			round.Readings = append(round.Readings, &Reading{Node: i, URI: uris[i], Namespace: ns})
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, reading := range round.Readings {
+		wg.Add(1)
+		go func(r *Reading) {
+			defer wg.Done()
+			r.Price, r.Err = clients[r.Node].NameSpacePrice(ctx, r.Namespace)
+		}(reading)
+	}
+	wg.Wait()
+	return round
+}
+
+// divergence is an ongoing disagreement on a namespace's price.
+type divergence struct {
+	start  time.Time
+	rounds int
+}
+
+// Printer writes every round as a table and tracks how long the nodes
+// disagree on each namespace.
+type Printer struct {
+	out        io.Writer
+	namespaces []string
+	diverging  map[string]*divergence
+}
+
+func NewPrinter(out io.Writer, namespaces []string) *Printer {
+	return &Printer{out: out, namespaces: namespaces, diverging: map[string]*divergence{}}
+}
+
+// Round prints [r]. Prices other than the most common one of a namespace
+// are marked with a *.
+func (p *Printer) Round(r *Round) {
+	majorities := map[string]uint64{}
+	for _, ns := range p.namespaces {
+		majorities[ns] = Majority(r.Prices(ns))
+	}
+
+	fmt.Fprintf(p.out, "round %d at %s\n", r.N, r.Time.Format("15:04:05.000"))
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "  node\turi")
+	for _, ns := range p.namespaces {
+		fmt.Fprintf(w, "\t%s", ns)
+	}
+	fmt.Fprintln(w)
+	for i := 0; i < len(r.Readings); i += len(p.namespaces) {
+		fmt.Fprintf(w, "  %d\t%s", r.Readings[i].Node, r.Readings[i].URI)
+		for _, reading := range r.Readings[i : i+len(p.namespaces)] {
+			switch {
+			case reading.Err != nil:
+				fmt.Fprintf(w, "\terror: %v", reading.Err)
+			case reading.Price != majorities[reading.Namespace]:
+				fmt.Fprintf(w, "\t%d *", reading.Price)
+			default:
+				fmt.Fprintf(w, "\t%d", reading.Price)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	for _, ns := range p.namespaces {
+		p.track(r, ns)
+	}
+}
+
+func (p *Printer) track(r *Round, namespace string) {
+	prices := r.Prices(namespace)
+	d := p.diverging[namespace]
+	if !diverged(prices) {
+		if d != nil {
+			fmt.Fprintf(p.out, "  %s: nodes agree again after %s (%d rounds)\n", namespace, r.Time.Sub(d.start).Round(time.Millisecond), d.rounds)
+			delete(p.diverging, namespace)
+		}
+		return
+	}
+	if d == nil {
+		d = &divergence{start: r.Time}
+		p.diverging[namespace] = d
+	}
+	d.rounds++
+	fmt.Fprintf(p.out, "  DIVERGED %s: %s for %s (%d rounds)\n", namespace, describe(prices), r.Time.Sub(d.start).Round(time.Millisecond), d.rounds)
+}
+
+// Close reports the disagreements still going on.
+func (p *Printer) Close() {
+	now := time.Now()
+	for _, ns := range p.namespaces {
+		if d := p.diverging[ns]; d != nil {
+			fmt.Fprintf(p.out, "%s: nodes still disagree after %s (%d rounds)\n", ns, now.Sub(d.start).Round(time.Millisecond), d.rounds)
+		}
+	}
+}
+
+type Options struct {
+	Namespaces []string
+	Interval   time.Duration
+	// Timeout is how long the nodes have to answer in a round, the
+	// interval if 0, or 5s when watching blocks
+	Timeout time.Duration
+
+	// Record is a file every reading is appended to, see [OpenRecorder]
+	Record string
+	// Changes prints only the prices that move, see [Changes], instead of
+	// every round
+	Changes bool
+	// Traffic is a file the SequencerMsg volume of every block that posts to
+	// the namespaces is appended to, see [TrafficWriter]. Only used by
+	// [Watch].
+	Traffic string
+}
+
+// Poll prints the price of every namespace as every node in [uris] reports
+// it, round after round, until [ctx] is done.
+func Poll(ctx context.Context, uris []string, opts Options, out io.Writer) error {
+	if opts.Timeout == 0 {
+		opts.Timeout = opts.Interval
+	}
+	var rec Recorder
+	if len(opts.Record) > 0 {
+		var err error
+		if rec, err = OpenRecorder(opts.Record); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		p      *Printer
+		print  func(*Round)
+		recErr error
+	)
+	if opts.Changes {
+		print = NewChanges(out).Round
+	} else {
+		p = NewPrinter(out, opts.Namespaces)
+		print = p.Round
+	}
+	Rounds(ctx, uris, opts.Namespaces, opts.Interval, opts.Timeout, func(r *Round) {
+		if rec != nil {
+			if recErr = rec.Record(r); recErr != nil {
+				cancel()
+				return
+			}
+		}
+		print(r)
+	})
+	if p != nil {
+		p.Close()
+	}
+	if rec == nil {
+		return nil
+	}
+	if err := rec.Close(); recErr == nil {
+		recErr = err
+	}
+	return recErr
+}
+
+func diverged(prices map[int]uint64) bool {
+	first := true
+	var price uint64
+	for _, p := range prices {
+		if !first && p != price {
+			return true
+		}
+		first, price = false, p
+	}
+	return false
+}
+
+// Majority is the most common of [prices], the lowest one on ties.
+func Majority(prices map[int]uint64) uint64 {
+	counts := map[uint64]int{}
+	for _, p := range prices {
+		counts[p]++
+	}
+	var best uint64
+	for p, c := range counts {
+		if c > counts[best] || (c == counts[best] && p < best) {
+			best = p
+		}
+	}
+	return best
+}
+
+// describe lists the nodes reporting each price.
+func describe(prices map[int]uint64) string {
+	nodes := make([]int, 0, len(prices))
+	for node := range prices {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+	byPrice := map[uint64][]int{}
+	var order []uint64
+	for _, node := range nodes {
+		p := prices[node]
+		if _, seen := byPrice[p]; !seen {
+			order = append(order, p)
+		}
+		byPrice[p] = append(byPrice[p], node)
+	}
+	parts := make([]string, len(order))
+	for i, p := range order {
+		parts[i] = fmt.Sprintf("%d on nodes %v", p, byPrice[p])
+	}
+	return strings.Join(parts, ", ")
+}
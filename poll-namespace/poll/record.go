@@ -0,0 +1,269 @@
+package poll
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var csvHeader = []string{"time", "round", "node", "uri", "namespace", "price", "error"}
+
+// Recorder keeps every reading of a poll.
+type Recorder interface {
+	Record(r *Round) error
+	Close() error
+}
+
+// sqlite reports whether [path] names a SQLite database rather than a CSV
+// file.
+func sqlite(path string) bool {
+	switch filepath.Ext(path) {
+	case ".db", ".sqlite", ".sqlite3":
+		return true
+	default:
+		return false
+	}
+}
+
+// OpenRecorder appends readings to the CSV file, or the SQLite database if
+// [path] ends in .db, .sqlite or .sqlite3, at [path].
+func OpenRecorder(path string) (Recorder, error) {
+	if sqlite(path) {
+		return openSQLite(path)
+	}
+	return openCSV(path)
+}
+
+type csvRecorder struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func openCSV(path string) (*csvRecorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	r := &csvRecorder{f: f, w: csv.NewWriter(f)}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		if err := r.w.Write(csvHeader); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (c *csvRecorder) Record(r *Round) error {
+	for _, reading := range r.Readings {
+		errString := ""
+		if reading.Err != nil {
+			errString = reading.Err.Error()
+		}
+		if err := c.w.Write([]string{
+			r.Time.UTC().Format(time.RFC3339Nano),
+			strconv.Itoa(r.N),
+			strconv.Itoa(reading.Node),
+			reading.URI,
+			reading.Namespace,
+			strconv.FormatUint(reading.Price, 10),
+			errString,
+		}); err != nil {
+			return err
+		}
+	}
+	// Flush every round so a killed poll loses nothing
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvRecorder) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		c.f.Close()
+		return err
+	}
+	return c.f.Close()
+}
+
+type sqliteRecorder struct {
+	db *sql.DB
+}
+
+const createReadings = `CREATE TABLE IF NOT EXISTS readings (
+	time INTEGER NOT NULL,
+	round INTEGER NOT NULL,
+	node INTEGER NOT NULL,
+	uri TEXT NOT NULL,
+	namespace TEXT NOT NULL,
+	price INTEGER NOT NULL,
+	error TEXT NOT NULL
+)`
+
+func openSQLite(path string) (*sqliteRecorder, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(createReadings); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteRecorder{db: db}, nil
+}
+
+func (s *sqliteRecorder) Record(r *Round) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, reading := range r.Readings {
+		errString := ""
+		if reading.Err != nil {
+			errString = reading.Err.Error()
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO readings (time, round, node, uri, namespace, price, error) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			r.Time.UnixNano(), r.N, reading.Node, reading.URI, reading.Namespace, int64(reading.Price), errString,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteRecorder) Close() error {
+	return s.db.Close()
+}
+
+// Load reads back the rounds recorded at [path], in the order they were
+// taken. Rounds of separate polls appended to the same file stay apart.
+func Load(path string) ([]*Round, error) {
+	if sqlite(path) {
+		return loadSQLite(path)
+	}
+	return loadCSV(path)
+}
+
+// recorded groups readings into rounds by time.
+type recorded struct {
+	rounds []*Round
+	byTime map[time.Time]*Round
+}
+
+func (r *recorded) add(t time.Time, n int, reading *Reading) {
+	if r.byTime == nil {
+		r.byTime = map[time.Time]*Round{}
+	}
+	round, ok := r.byTime[t]
+	if !ok {
+		round = &Round{N: n, Time: t}
+		r.byTime[t] = round
+		r.rounds = append(r.rounds, round)
+	}
+	round.Readings = append(round.Readings, reading)
+}
+
+func (r *recorded) sorted() []*Round {
+	sort.SliceStable(r.rounds, func(i, j int) bool { return r.rounds[i].Time.Before(r.rounds[j].Time) })
+	return r.rounds
+}
+
+func loadCSV(path string) ([]*Round, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = len(csvHeader)
+	var rec recorded
+	for line := 1; ; line++ {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row[0] == csvHeader[0] {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		n, err := strconv.Atoi(row[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		node, err := strconv.Atoi(row[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		price, err := strconv.ParseUint(row[5], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		reading := &Reading{Node: node, URI: row[3], Namespace: row[4], Price: price}
+		if len(row[6]) > 0 {
+			reading.Err = errors.New(row[6])
+		}
+		rec.add(t, n, reading)
+	}
+	return rec.sorted(), nil
+}
+
+func loadSQLite(path string) ([]*Round, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT time, round, node, uri, namespace, price, error FROM readings ORDER BY time, node, rowid")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rec recorded
+	for rows.Next() {
+		var (
+			nanos, price int64
+			n            int
+			errString    string
+			reading      = &Reading{}
+		)
+		if err := rows.Scan(&nanos, &n, &reading.Node, &reading.URI, &reading.Namespace, &price, &errString); err != nil {
+			return nil, err
+		}
+		reading.Price = uint64(price)
+		if len(errString) > 0 {
+			reading.Err = errors.New(errString)
+		}
+		rec.add(time.Unix(0, nanos), n, reading)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rec.sorted(), nil
+}
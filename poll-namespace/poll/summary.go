@@ -0,0 +1,148 @@
+package poll
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// NamespaceSummary is what the nodes that answered reported for one
+// namespace.
+type NamespaceSummary struct {
+	Namespace string
+	Readings  int
+	Min       uint64
+	Max       uint64
+	Mean      float64
+	// Changes counts how often the price most nodes agreed on moved
+	Changes int
+}
+
+type NodeSummary struct {
+	Node     int
+	URI      string
+	Readings int
+	Errors   int
+}
+
+func (n *NodeSummary) ErrorRate() float64 {
+	if n.Readings == 0 {
+		return 0
+	}
+	return float64(n.Errors) / float64(n.Readings)
+}
+
+type Summary struct {
+	From   time.Time
+	To     time.Time
+	Rounds int
+
+	Namespaces []*NamespaceSummary
+	Nodes      []*NodeSummary
+}
+
+// Summarize sums up the [rounds] taken between [since] and [until]; zero
+// times don't bound the window.
+func Summarize(rounds []*Round, since time.Time, until time.Time) *Summary {
+	s := &Summary{}
+	namespaces := map[string]*NamespaceSummary{}
+	nodes := map[int]*NodeSummary{}
+	last := map[string]uint64{}
+	sums := map[string]float64{}
+	for _, r := range rounds {
+		if (!since.IsZero() && r.Time.Before(since)) || (!until.IsZero() && r.Time.After(until)) {
+			continue
+		}
+		if s.Rounds == 0 {
+			s.From = r.Time
+		}
+		s.To = r.Time
+		s.Rounds++
+
+		for _, reading := range r.Readings {
+			node, ok := nodes[reading.Node]
+			if !ok {
+				node = &NodeSummary{Node: reading.Node, URI: reading.URI}
+				nodes[reading.Node] = node
+			}
+			node.Readings++
+			ns, ok := namespaces[reading.Namespace]
+			if !ok {
+				ns = &NamespaceSummary{Namespace: reading.Namespace}
+				namespaces[reading.Namespace] = ns
+			}
+			if reading.Err != nil {
+				node.Errors++
+				continue
+			}
+			if ns.Readings == 0 {
+				ns.Min, ns.Max = reading.Price, reading.Price
+			}
+			ns.Readings++
+			ns.Min, ns.Max = min(ns.Min, reading.Price), max(ns.Max, reading.Price)
+			sums[reading.Namespace] += float64(reading.Price)
+		}
+		for name, ns := range namespaces {
+			prices := r.Prices(name)
+			if len(prices) == 0 {
+				continue
+			}
+			price := Majority(prices)
+			if previous, ok := last[name]; ok && previous != price {
+				ns.Changes++
+			}
+			last[name] = price
+		}
+	}
+
+	for name, ns := range namespaces {
+		if ns.Readings > 0 {
+			ns.Mean = sums[name] / float64(ns.Readings)
+		}
+		s.Namespaces = append(s.Namespaces, ns)
+	}
+	sort.Slice(s.Namespaces, func(i, j int) bool { return s.Namespaces[i].Namespace < s.Namespaces[j].Namespace })
+	for _, node := range nodes {
+		s.Nodes = append(s.Nodes, node)
+	}
+	sort.Slice(s.Nodes, func(i, j int) bool { return s.Nodes[i].Node < s.Nodes[j].Node })
+	return s
+}
+
+// ParseTime parses an RFC 3339 time, or a duration meaning that long before
+// [now].
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func (s *Summary) Print(w io.Writer) {
+	if s.Rounds == 0 {
+		fmt.Fprintln(w, "no rounds recorded in the window")
+		return
+	}
+	fmt.Fprintf(w, "%d rounds from %s to %s (%s)\n\n", s.Rounds, s.From.Format(time.RFC3339), s.To.Format(time.RFC3339), s.To.Sub(s.From).Round(time.Second))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tREADINGS\tMIN\tMAX\tMEAN\tCHANGES")
+	for _, ns := range s.Namespaces {
+		if ns.Readings == 0 {
+			fmt.Fprintf(tw, "%s\t0\t-\t-\t-\t-\n", ns.Namespace)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%d\n", ns.Namespace, ns.Readings, ns.Min, ns.Max, ns.Mean, ns.Changes)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tURI\tREADINGS\tERRORS\tERROR RATE")
+	for _, node := range s.Nodes {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%.2f%%\n", node.Node, node.URI, node.Readings, node.Errors, node.ErrorRate()*100)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,136 @@
+package poll
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var trafficHeader = []string{"time", "height", "namespace", "msgs", "bytes"}
+
+// BlockTraffic is the SequencerMsg volume a block posted to each namespace.
+type BlockTraffic struct {
+	Time   time.Time
+	Height uint64
+	Volume map[string]Volume
+}
+
+// TrafficWriter appends the traffic of blocks to a CSV file, one row per
+// block and namespace.
+type TrafficWriter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func OpenTraffic(path string) (*TrafficWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	t := &TrafficWriter{f: f, w: csv.NewWriter(f)}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		if err := t.w.Write(trafficHeader); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t *TrafficWriter) Write(b *BlockTraffic) error {
+	namespaces := make([]string, 0, len(b.Volume))
+	for ns := range b.Volume {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		v := b.Volume[ns]
+		if err := t.w.Write([]string{
+			b.Time.UTC().Format(time.RFC3339Nano),
+			strconv.FormatUint(b.Height, 10),
+			ns,
+			strconv.Itoa(v.Msgs),
+			strconv.FormatUint(v.Bytes, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	t.w.Flush()
+	return t.w.Error()
+}
+
+func (t *TrafficWriter) Close() error {
+	t.w.Flush()
+	if err := t.w.Error(); err != nil {
+		t.f.Close()
+		return err
+	}
+	return t.f.Close()
+}
+
+// LoadTraffic reads back the blocks written by a [TrafficWriter], ordered by
+// height.
+func LoadTraffic(path string) ([]*BlockTraffic, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = len(trafficHeader)
+	blocks := map[uint64]*BlockTraffic{}
+	for line := 1; ; line++ {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row[0] == trafficHeader[0] {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		height, err := strconv.ParseUint(row[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		msgs, err := strconv.Atoi(row[3])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		size, err := strconv.ParseUint(row[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		b, ok := blocks[height]
+		if !ok {
+			b = &BlockTraffic{Time: t, Height: height, Volume: map[string]Volume{}}
+			blocks[height] = b
+		}
+		v := b.Volume[row[2]]
+		v.Msgs += msgs
+		v.Bytes += size
+		b.Volume[row[2]] = v
+	}
+	traffic := make([]*BlockTraffic, 0, len(blocks))
+	for _, b := range blocks {
+		traffic = append(traffic, b)
+	}
+	sort.Slice(traffic, func(i, j int) bool { return traffic[i].Height < traffic[j].Height })
+	return traffic, nil
+}
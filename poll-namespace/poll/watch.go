@@ -0,0 +1,247 @@
+package poll
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/pubsub"
+	hrpc "github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	trpc "github.com/AnomalyFi/nodekit-seq/rpc"
+)
+
+// Volume is the SequencerMsg traffic of a block to one namespace.
+type Volume struct {
+	Msgs  int
+	Bytes uint64
+}
+
+// BlockVolume counts the SequencerMsg actions of [blk] to each namespace of
+// [watched], leaving out those with none.
+func BlockVolume(blk *chain.StatelessBlock, watched map[string]bool) map[string]Volume {
+	volume := map[string]Volume{}
+	for _, tx := range blk.Txs {
+		for _, act := range tx.Actions {
+			msg, ok := act.(*actions.SequencerMsg)
+			if !ok || !watched[string(msg.ChainId)] {
+				continue
+			}
+			v := volume[string(msg.ChainId)]
+			v.Msgs++
+			v.Bytes += uint64(len(msg.Data))
+			volume[string(msg.ChainId)] = v
+		}
+	}
+	return volume
+}
+
+// sample is a block that posted to a namespace, and how its price moved.
+type sample struct {
+	bytes float64
+	delta float64
+}
+
+// Correlator prints the price of the namespaces a block posted to next to
+// the volume posted, and sums up how the two relate.
+type Correlator struct {
+	out     io.Writer
+	last    map[string]uint64
+	samples map[string][]sample
+	names   []string
+}
+
+func NewCorrelator(out io.Writer, namespaces []string) *Correlator {
+	return &Correlator{out: out, last: map[string]uint64{}, samples: map[string][]sample{}, names: namespaces}
+}
+
+// Baseline sets the prices blocks are compared with.
+func (c *Correlator) Baseline(r *Round) {
+	for _, ns := range c.names {
+		if prices := r.Prices(ns); len(prices) > 0 {
+			c.last[ns] = Majority(prices)
+		}
+	}
+}
+
+// Block prints the volume of block [height] and the prices [r] read after
+// it.
+func (c *Correlator) Block(height uint64, volume map[string]Volume, r *Round) {
+	for _, ns := range c.names {
+		v, ok := volume[ns]
+		if !ok {
+			continue
+		}
+		prices := r.Prices(ns)
+		if len(prices) == 0 {
+			fmt.Fprintf(c.out, "%s block %d %s: %d msgs %d bytes, no node answered\n", r.Time.Format("15:04:05.000"), height, ns, v.Msgs, v.Bytes)
+			continue
+		}
+		price := Majority(prices)
+		previous, known := c.last[ns]
+		change := "-"
+		if known {
+			delta := float64(price) - float64(previous)
+			change = fmt.Sprintf("%+.0f", delta)
+			c.samples[ns] = append(c.samples[ns], sample{bytes: float64(v.Bytes), delta: delta})
+		}
+		c.last[ns] = price
+		line := fmt.Sprintf("%s block %d %s: %d msgs %d bytes, price %d (%s)", r.Time.Format("15:04:05.000"), height, ns, v.Msgs, v.Bytes, price, change)
+		if diverged(prices) {
+			line += ", nodes disagree: " + describe(prices)
+		}
+		fmt.Fprintln(c.out, line)
+	}
+}
+
+// Close prints, per namespace, the mean volume of the blocks that posted to
+// it and how well the bytes posted explain the price moves.
+func (c *Correlator) Close() {
+	for _, ns := range c.names {
+		samples := c.samples[ns]
+		if len(samples) == 0 {
+			fmt.Fprintf(c.out, "%s: no block posted to it\n", ns)
+			continue
+		}
+		var bytes, delta float64
+		for _, s := range samples {
+			bytes += s.bytes
+			delta += s.delta
+		}
+		n := float64(len(samples))
+		fmt.Fprintf(c.out, "%s: %d blocks, mean %.0f bytes and price change %+.1f per block, correlation %s\n",
+			ns, len(samples), bytes/n, delta/n, correlation(samples))
+	}
+}
+
+// correlation is the Pearson correlation of the bytes posted with the price
+// change.
+func correlation(samples []sample) string {
+	n := float64(len(samples))
+	var sx, sy, sxx, syy, sxy float64
+	for _, s := range samples {
+		sx += s.bytes
+		sy += s.delta
+		sxx += s.bytes * s.bytes
+		syy += s.delta * s.delta
+		sxy += s.bytes * s.delta
+	}
+	den := math.Sqrt(n*sxx-sx*sx) * math.Sqrt(n*syy-sy*sy)
+	if len(samples) < 2 || den == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f", (n*sxy-sx*sy)/den)
+}
+
+// Watch subscribes to the blocks accepted by the first node in [uris] and,
+// whenever a block posts to one of the namespaces of [opts], asks every node
+// for the price of those namespaces. It runs until [ctx] is done or the
+// connection is lost.
+func Watch(ctx context.Context, uris []string, opts Options, out io.Writer) error {
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	cli := hrpc.NewJSONRPCClient(uris[0])
+	networkID, _, chainID, err := cli.Network(ctx)
+	if err != nil {
+		return err
+	}
+	parser, err := trpc.NewJSONRPCClient(uris[0], networkID, chainID).Parser(ctx)
+	if err != nil {
+		return err
+	}
+	ws, err := hrpc.NewWebSocketClient(uris[0], hrpc.DefaultHandshakeTimeout, pubsub.MaxPendingMessages, pubsub.MaxReadMessageSize)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	if err := ws.RegisterBlocks(); err != nil {
+		return err
+	}
+
+	var rec Recorder
+	if len(opts.Record) > 0 {
+		if rec, err = OpenRecorder(opts.Record); err != nil {
+			return err
+		}
+	}
+	var traffic *TrafficWriter
+	if len(opts.Traffic) > 0 {
+		if traffic, err = OpenTraffic(opts.Traffic); err != nil {
+			if rec != nil {
+				rec.Close()
+			}
+			return err
+		}
+	}
+	clients := make([]*hrpc.JSONRPCClient, len(uris))
+	watched := map[string]bool{}
+	for i, uri := range uris {
+		clients[i] = hrpc.NewJSONRPCClient(uri)
+	}
+	for _, ns := range opts.Namespaces {
+		watched[ns] = true
+	}
+
+	c := NewCorrelator(out, opts.Namespaces)
+	round := ask(ctx, clients, uris, opts.Namespaces, opts.Timeout)
+	c.Baseline(round)
+	err = func() error {
+		if rec != nil {
+			if err := rec.Record(round); err != nil {
+				return err
+			}
+		}
+		n := 1
+		for {
+			blk, _, _, err := ws.ListenBlock(ctx, parser)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			volume := BlockVolume(blk, watched)
+			if len(volume) == 0 {
+				continue
+			}
+			if traffic != nil {
+				if err := traffic.Write(&BlockTraffic{Time: time.UnixMilli(blk.Tmstmp), Height: blk.Hght, Volume: volume}); err != nil {
+					return err
+				}
+			}
+			namespaces := make([]string, 0, len(volume))
+			for _, ns := range opts.Namespaces {
+				if _, ok := volume[ns]; ok {
+					namespaces = append(namespaces, ns)
+				}
+			}
+			round := ask(ctx, clients, uris, namespaces, opts.Timeout)
+			if ctx.Err() != nil {
+				return nil
+			}
+			round.N, n = n, n+1
+			c.Block(blk.Hght, volume, round)
+			if rec != nil {
+				if err := rec.Record(round); err != nil {
+					return err
+				}
+			}
+		}
+	}()
+	c.Close()
+	if rec != nil {
+		if cerr := rec.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if traffic != nil {
+		if cerr := traffic.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,387 @@
+// Package sim predicts namespace prices by running recorded SequencerMsg
+// traffic through a model of SEQ's namespace pricing, and compares the
+// predictions with the prices the nodes reported.
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/poll"
+)
+
+const (
+	// Window is hypersdk's fee manager (chain/fee_manager.go,
+	// computeNextPriceWindow) applied to the bytes posted to a namespace:
+	// the bytes of the last WindowSize seconds are summed, and the price
+	// moves by price*|sum-WindowTarget|/WindowTarget/ChangeDenominator, at
+	// least 1, towards the target
+	Window = "window"
+	// Decay keeps a share of the price every block and adds to it for the
+	// bytes posted. It is mock-seq's made up pricing, not SEQ's
+	Decay = "decay"
+	// EIP1559 moves the price by up to MaxChange every block depending on
+	// how far the bytes posted are from Target
+	EIP1559 = "eip1559"
+)
+
+// MockSeq names [MockSeqModel] in place of a model file.
+const MockSeq = "mock-seq"
+
+// WindowSize is the seconds a [Window] model sums, hypersdk's
+// window.WindowSize.
+const WindowSize = 10
+
+// Model is how the price of a namespace follows from the bytes posted to
+// it, block after block.
+type Model struct {
+	Kind string `json:"kind"`
+	// BasePrice is the lowest price reported, the price of a namespace no
+	// one posts to
+	BasePrice uint64 `json:"basePrice"`
+
+	// Keep is the share of the price kept every block, PerBlock is added
+	// for every block that posts a message and PerKiB for every whole KiB
+	// posted
+	Keep     float64 `json:"keep,omitempty"`
+	PerBlock uint64  `json:"perBlock,omitempty"`
+	PerKiB   uint64  `json:"perKiB,omitempty"`
+
+	// Target is the bytes per block at which the price holds, MaxChange
+	// the share it moves by when a block posts nothing or twice the target
+	Target    uint64  `json:"target,omitempty"`
+	MaxChange float64 `json:"maxChange,omitempty"`
+
+	// WindowTarget is the bytes per window at which the price holds, and
+	// ChangeDenominator how slowly it moves, the WindowTargetUnits and
+	// UnitPriceChangeDenominator of the chain's rules
+	WindowTarget      uint64 `json:"windowTarget,omitempty"`
+	ChangeDenominator uint64 `json:"changeDenominator,omitempty"`
+}
+
+// DefaultModel is hypersdk's fee window with the bandwidth parameters of
+// the example rules in hypersdk's README: MinUnitPrice 100,
+// UnitPriceChangeDenominator 48 and WindowTargetUnits 20,000,000. A chain
+// whose genesis sets others needs them in a model file.
+var DefaultModel = Model{
+	Kind:              Window,
+	BasePrice:         100,
+	WindowTarget:      20_000_000,
+	ChangeDenominator: 48,
+}
+
+// MockSeqModel is how mock-seq prices namespaces with its default
+// --namespace-price; it only predicts mock-seq.
+var MockSeqModel = Model{
+	Kind:      Decay,
+	BasePrice: 1,
+	Keep:      0.5,
+	PerBlock:  1,
+	PerKiB:    1,
+}
+
+// LoadModel reads a model from the JSON file at [path], or returns
+// [MockSeqModel] for [MockSeq].
+func LoadModel(path string) (*Model, error) {
+	if path == MockSeq {
+		m := MockSeqModel
+		return &m, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := DefaultModel
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return &m, m.Validate()
+}
+
+func (m *Model) Validate() error {
+	switch m.Kind {
+	case Window:
+		if m.WindowTarget == 0 {
+			return fmt.Errorf("a %s model needs a windowTarget", Window)
+		}
+		if m.ChangeDenominator == 0 {
+			return fmt.Errorf("a %s model needs a changeDenominator", Window)
+		}
+	case Decay:
+		if m.Keep < 0 || m.Keep > 1 {
+			return fmt.Errorf("keep must be between 0 and 1, not %g", m.Keep)
+		}
+	case EIP1559:
+		if m.Target == 0 {
+			return fmt.Errorf("an %s model needs a target", EIP1559)
+		}
+		if m.MaxChange <= 0 || m.MaxChange >= 1 {
+			return fmt.Errorf("maxChange must be between 0 and 1, not %g", m.MaxChange)
+		}
+	default:
+		return fmt.Errorf("unknown model kind %q, want %s, %s or %s", m.Kind, Window, Decay, EIP1559)
+	}
+	return nil
+}
+
+// state is the pricing state of a namespace.
+type state struct {
+	price uint64
+	// window are the bytes posted in each of the last WindowSize seconds,
+	// the latest last
+	window [WindowSize]uint64
+}
+
+// next moves [s] past a block that posted [v], [since] seconds before the
+// next block.
+func (m *Model) next(s *state, v poll.Volume, since int) {
+	switch m.Kind {
+	case Window:
+		m.nextWindow(s, v.Bytes, since)
+	case EIP1559:
+		p := float64(max(s.price, m.BasePrice))
+		change := m.MaxChange * (float64(v.Bytes) - float64(m.Target)) / float64(m.Target)
+		change = math.Max(-m.MaxChange, math.Min(m.MaxChange, change))
+		s.price = max(uint64(math.Round(p*(1+change))), m.BasePrice)
+	default:
+		next := uint64(float64(s.price) * m.Keep)
+		if v.Msgs > 0 {
+			next += m.PerBlock + m.PerKiB*(v.Bytes/1024)
+		}
+		s.price = next
+	}
+}
+
+// nextWindow is computeNextPriceWindow: the window rolls by [since]
+// seconds, the block's [consumed] bytes go in the second they were posted
+// in, and the price moves towards the target with integer math as the
+// nodes do, wrapping products included.
+func (m *Model) nextWindow(s *state, consumed uint64, since int) {
+	var w [WindowSize]uint64
+	if since < WindowSize {
+		copy(w[:], s.window[since:])
+		slot := WindowSize - 1 - since
+		w[slot] = addSaturating(w[slot], consumed)
+	}
+	s.window = w
+	var total uint64
+	for _, u := range w {
+		total = addSaturating(total, u)
+	}
+
+	price, target := max(s.price, m.BasePrice), m.WindowTarget
+	switch {
+	case total > target:
+		delta := max(price*(total-target)/target/m.ChangeDenominator, 1)
+		price = addSaturating(price, delta)
+	case total < target:
+		delta := max(price*(target-total)/target/m.ChangeDenominator, 1)
+		// Every window without blocks lowers the price once more
+		if since > WindowSize {
+			delta *= uint64(since / WindowSize)
+		}
+		if delta > price {
+			price = 0
+		} else {
+			price -= delta
+		}
+	}
+	s.price = max(price, m.BasePrice)
+}
+
+func addSaturating(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+// reported is the price a node answers with.
+func (m *Model) reported(price uint64) uint64 {
+	return max(price, m.BasePrice)
+}
+
+// Point is the predicted price of a namespace after a block.
+type Point struct {
+	Height uint64
+	// Time is only known for blocks that posted to some namespace
+	Time      time.Time
+	Namespace string
+	Msgs      int
+	Bytes     uint64
+	Price     uint64
+}
+
+// Simulate predicts the price of every namespace of [traffic] after every
+// block from the first to the last one in it; blocks missing in between
+// posted nothing, at times spread evenly between the blocks around them.
+func Simulate(m *Model, traffic []*poll.BlockTraffic) []*Point {
+	if len(traffic) == 0 {
+		return nil
+	}
+	namespaces := map[string]bool{}
+	for _, b := range traffic {
+		for ns := range b.Volume {
+			namespaces[ns] = true
+		}
+	}
+	names := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	times := blockTimes(traffic)
+	var points []*Point
+	states := map[string]*state{}
+	for _, ns := range names {
+		states[ns] = &state{}
+	}
+	next := 0
+	first := traffic[0].Height
+	for h := first; h <= traffic[len(traffic)-1].Height; h++ {
+		var b *poll.BlockTraffic
+		if traffic[next].Height == h {
+			b = traffic[next]
+			next++
+		}
+		// The last block is followed as closely as the one before it
+		i := int(h - first)
+		since := 0
+		switch {
+		case i+1 < len(times):
+			since = seconds(times[i], times[i+1])
+		case i > 0:
+			since = seconds(times[i-1], times[i])
+		}
+		for _, ns := range names {
+			var v poll.Volume
+			p := &Point{Height: h, Namespace: ns}
+			if b != nil {
+				v = b.Volume[ns]
+				p.Time = b.Time
+			}
+			m.next(states[ns], v, since)
+			p.Msgs, p.Bytes, p.Price = v.Msgs, v.Bytes, m.reported(states[ns].price)
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// blockTimes returns the time of every block from the first to the last of
+// [traffic], those of blocks missing from it spread evenly between the
+// blocks around them.
+func blockTimes(traffic []*poll.BlockTraffic) []time.Time {
+	first := traffic[0].Height
+	times := make([]time.Time, traffic[len(traffic)-1].Height-first+1)
+	for i, b := range traffic {
+		times[b.Height-first] = b.Time
+		if i == 0 {
+			continue
+		}
+		prev := traffic[i-1]
+		gap := b.Height - prev.Height
+		for h := prev.Height + 1; h < b.Height; h++ {
+			step := b.Time.Sub(prev.Time) * time.Duration(h-prev.Height) / time.Duration(gap)
+			times[h-first] = prev.Time.Add(step)
+		}
+	}
+	return times
+}
+
+// seconds is the whole seconds from [from] to [to], as hypersdk counts
+// them between block timestamps in milliseconds.
+func seconds(from, to time.Time) int {
+	return max(int((to.UnixMilli()-from.UnixMilli())/1000), 0)
+}
+
+// WriteCSV writes [points] as CSV, one row per block and namespace.
+func WriteCSV(w io.Writer, points []*Point) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"height", "time", "namespace", "msgs", "bytes", "price"}); err != nil {
+		return err
+	}
+	for _, p := range points {
+		t := ""
+		if !p.Time.IsZero() {
+			t = p.Time.UTC().Format(time.RFC3339Nano)
+		}
+		if err := cw.Write([]string{
+			strconv.FormatUint(p.Height, 10),
+			t,
+			p.Namespace,
+			strconv.Itoa(p.Msgs),
+			strconv.FormatUint(p.Bytes, 10),
+			strconv.FormatUint(p.Price, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// PrintCurves writes, per namespace, the range of the predicted prices and
+// a sparkline of them.
+func PrintCurves(w io.Writer, points []*Point, width int) {
+	curves := map[string][]uint64{}
+	var names []string
+	for _, p := range points {
+		if _, ok := curves[p.Namespace]; !ok {
+			names = append(names, p.Namespace)
+		}
+		curves[p.Namespace] = append(curves[p.Namespace], p.Price)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tBLOCKS\tMIN\tMAX\tFINAL\tCURVE")
+	for _, ns := range names {
+		c := curves[ns]
+		lo, hi := c[0], c[0]
+		for _, p := range c {
+			lo, hi = min(lo, p), max(hi, p)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", ns, len(c), lo, hi, c[len(c)-1], sparkline(c, width))
+	}
+	tw.Flush()
+}
+
+var ticks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws [values], averaged down to at most [width] ticks.
+func sparkline(values []uint64, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	buckets := make([]float64, 0, width)
+	per := float64(len(values)) / float64(min(width, len(values)))
+	for start := 0.0; int(start) < len(values); start += per {
+		end := min(int(start+per), len(values))
+		var sum float64
+		for _, v := range values[int(start):end] {
+			sum += float64(v)
+		}
+		buckets = append(buckets, sum/float64(max(end-int(start), 1)))
+	}
+	lo, hi := buckets[0], buckets[0]
+	for _, b := range buckets {
+		lo, hi = math.Min(lo, b), math.Max(hi, b)
+	}
+	s := make([]rune, len(buckets))
+	for i, b := range buckets {
+		t := 0
+		if hi > lo {
+			t = int((b - lo) / (hi - lo) * float64(len(ticks)-1))
+		}
+		s[i] = ticks[t]
+	}
+	return string(s)
+}
@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/poll"
+)
+
+// Fit is how far the predictions of one namespace were from the prices the
+// nodes reported.
+type Fit struct {
+	Namespace string
+	Compared  int
+	// MeanAbsError and MaxAbsError are in price units, MeanAbsPctError in
+	// percent of the observed price
+	MeanAbsError    float64
+	MeanAbsPctError float64
+	MaxAbsError     float64
+}
+
+// Validate compares [points] with the prices most nodes reported in each of
+// [rounds]. A round is compared with the prediction for the last block
+// that posted before it was taken, which is exact for rounds recorded by
+// poll.Watch; rounds before the first block are skipped.
+func Validate(points []*Point, rounds []*poll.Round) []*Fit {
+	// Only blocks that posted have a time
+	timed := map[string][]*Point{}
+	for _, p := range points {
+		if !p.Time.IsZero() {
+			timed[p.Namespace] = append(timed[p.Namespace], p)
+		}
+	}
+
+	fits := map[string]*Fit{}
+	pct := map[string]int{}
+	for _, r := range rounds {
+		for ns, predicted := range timed {
+			prices := r.Prices(ns)
+			if len(prices) == 0 {
+				continue
+			}
+			i := sort.Search(len(predicted), func(i int) bool { return predicted[i].Time.After(r.Time) })
+			if i == 0 {
+				continue
+			}
+			observed := float64(poll.Majority(prices))
+			diff := math.Abs(float64(predicted[i-1].Price) - observed)
+
+			f, ok := fits[ns]
+			if !ok {
+				f = &Fit{Namespace: ns}
+				fits[ns] = f
+			}
+			f.Compared++
+			f.MeanAbsError += diff
+			f.MaxAbsError = math.Max(f.MaxAbsError, diff)
+			if observed > 0 {
+				f.MeanAbsPctError += diff / observed * 100
+				pct[ns]++
+			}
+		}
+	}
+
+	out := make([]*Fit, 0, len(fits))
+	for ns, f := range fits {
+		f.MeanAbsError /= float64(f.Compared)
+		if pct[ns] > 0 {
+			f.MeanAbsPctError /= float64(pct[ns])
+		}
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out
+}
+
+func PrintFits(w io.Writer, fits []*Fit) {
+	if len(fits) == 0 {
+		fmt.Fprintln(w, "no observed price to compare the predictions with")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tCOMPARED\tMEAN ABS ERROR\tMEAN ABS % ERROR\tMAX ABS ERROR")
+	for _, f := range fits {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t%.2f%%\t%.0f\n", f.Namespace, f.Compared, f.MeanAbsError, f.MeanAbsPctError, f.MaxAbsError)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,280 @@
+// Package config generates a relayer config for every node serving the SEQ
+// chain of an ANR cluster.
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	hrpc "github.com/AnomalyFi/hypersdk/rpc"
+	srpc "github.com/AnomalyFi/nodekit-seq/rpc"
+
+	"github.com/AnomalyFi/tools/common/anr"
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+type CelestiaDAClientConfig struct {
+	NameSpaceID NamespaceID `json:"nameSpaceID"`
+	RPCAddress  string      `json:"rpcAddress"`
+	AccessToken string      `json:"accessToken"`
+}
+
+type EigenDAClientConfig struct {
+	Target             string `json:"target"`
+	PrivateKeyFilePath string `json:"privateKeyFilePath"`
+}
+
+type AvailClientConfig struct {
+	ApiURL string `json:"apiURL"`
+	AppID  int    `json:"appID"`
+	Seed   string `json:"seed"`
+}
+
+type DatabaseConfig struct {
+	File         string `json:"file"`
+	RetainWindow uint64 `json:"retainWindow"`
+}
+
+type Config struct {
+	DBConfig   DatabaseConfig `json:"dbConfig"`
+	SeqNode    SeqNodeInfo    `json:"seqNode"`
+	Log        LogConfig      `json:"log"`
+	MetaConfig MetaConfig     `json:"metaConfig"`
+	// DA configs, a relayer only gets the one of its backend
+	EigenDAConfig    *EigenDAClientConfig    `json:"eigenDAConfig,omitempty"`
+	AvailDAConfig    *AvailClientConfig      `json:"availDAConfig,omitempty"`
+	CelestiaDAConfig *CelestiaDAClientConfig `json:"celestiaDAConfig,omitempty"`
+}
+
+type SeqNodeInfo struct {
+	URI       string `json:"uri"`
+	NetworkID uint32 `json:"networkID"`
+	NodeUrl   string `json:"nodeUrl"`
+	// need to be unmarshalled
+	ChainID string `json:"chainID"`
+}
+
+type LogConfig struct {
+	Level     string `json:"level"`
+	ToConsole bool   `json:"toConsole"`
+	LogFile   string `json:"logFile"`
+}
+
+type MetaConfig struct {
+	ServeRpc string `json:"serveRpc"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Load reads a config template.
+func Load(path string) (*Config, error) {
+	configBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %s", path)
+	}
+	var config Config
+	dec := json.NewDecoder(bytes.NewReader(configBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		// The template may hold secrets, so it isn't echoed
+		return nil, fmt.Errorf("unable to parse config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Node is a node the relayer connects to.
+type Node struct {
+	Endpoint *anr.Endpoint
+	// MessageNetPort is the port the node reported, e.g. ":2173"
+	MessageNetPort string
+	// ServeRPCPort is where the relayer serves its own API, see [AssignPorts]
+	ServeRPCPort int
+}
+
+// Discover returns the network ID of [chain] and asks every node serving it
+// for its message net port. The nodes have no serve RPC port yet.
+func Discover(ctx context.Context, cluster *anr.Cluster, chain *anr.Chain) (uint32, []Node, error) {
+	endpoints := cluster.Serving(chain.ID)
+	if len(endpoints) == 0 {
+		return 0, nil, fmt.Errorf("no node serves chain %s", chain.ID)
+	}
+	networkID, _, _, err := hrpc.NewJSONRPCClient(endpoints[0].URI).Network(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	nodes := make([]Node, len(endpoints))
+	for i, endpoint := range endpoints {
+		cli := srpc.NewJSONRPCClient(endpoint.URI, networkID, chain.ID)
+		port, err := cli.MessageNetPort(ctx)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%s: %w", endpoint.Node, err)
+		}
+		nodes[i] = Node{
+			Endpoint:       endpoint,
+			MessageNetPort: port,
+		}
+	}
+	return networkID, nodes, nil
+}
+
+// Generate returns one config per node, based on [template], with only the
+// DA section of the node's backend in [backends]. Files the relayer writes
+// are suffixed with the node's index.
+func Generate(template Config, networkID uint32, nodes []Node, backends []Backend) ([]Config, error) {
+	if len(backends) != len(nodes) {
+		return nil, fmt.Errorf("%d DA backends for %d nodes", len(backends), len(nodes))
+	}
+	configs := make([]Config, len(nodes))
+	for i, n := range nodes {
+		config, err := template.only(backends[i])
+		if err != nil {
+			return nil, fmt.Errorf("relayer %d: %w", i, err)
+		}
+		config.SeqNode.URI = n.Endpoint.URI
+		config.SeqNode.NetworkID = networkID
+		config.SeqNode.ChainID = n.Endpoint.ChainID.String()
+		config.MetaConfig.Endpoint = "localhost" + n.MessageNetPort
+		config.SeqNode.NodeUrl = n.Endpoint.NodeURL
+		config.Log.LogFile = "./relayer" + strconv.Itoa(i) + ".log"
+		config.DBConfig.File = "./relayer" + strconv.Itoa(i) + ".db"
+		if config.EigenDAConfig != nil {
+			config.EigenDAConfig.PrivateKeyFilePath = "./demo" + strconv.Itoa(i) + ".pk"
+		}
+		config.MetaConfig.ServeRpc = "127.0.0.1:" + strconv.Itoa(n.ServeRPCPort)
+		configs[i] = config
+	}
+	return configs, nil
+}
+
+// Options are what the generators' flags choose besides the template.
+type Options struct {
+	// Ports is the range serve RPC ports are taken from, nodes of Previous,
+	// the manifest of an earlier run, keep theirs
+	Ports    PortRange
+	Previous *Manifest
+	// DA assigns the backends, see [AssignBackends]
+	DA      string
+	Secrets Secrets
+	// MockDA is the API of a mock-da process the DA sections point at, if
+	// set
+	MockDA string
+}
+
+// Build returns the configs of the relayers of [nodes], as Discover found
+// them: it assigns their serve RPC ports and backends, generates the
+// configs from [template] and sets the secrets and mock-da endpoints.
+func Build(ctx context.Context, template Config, networkID uint32, nodes []Node, opts Options) ([]Config, error) {
+	if err := AssignPorts(nodes, opts.Ports, opts.Previous); err != nil {
+		return nil, err
+	}
+	backends, err := AssignBackends(opts.DA, len(nodes))
+	if err != nil {
+		return nil, err
+	}
+	configs, err := Generate(template, networkID, nodes, backends)
+	if err != nil {
+		return nil, err
+	}
+	SetSecrets(configs, opts.Secrets)
+	if len(opts.MockDA) > 0 {
+		m, err := LoadMockDA(ctx, opts.MockDA)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.Apply(configs); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
+
+// Secrets are the DA credentials that aren't kept in templates. Empty ones
+// leave the template's value.
+type Secrets struct {
+	CelestiaAccessToken string
+	AvailSeed           string
+}
+
+// LoadSecrets reads the secrets from where [celestiaAccessToken] and
+// [availSeed] point, e.g. env:CELESTIA_AUTH_TOKEN. Empty specs are skipped.
+func LoadSecrets(celestiaAccessToken string, availSeed string) (Secrets, error) {
+	var (
+		s   Secrets
+		err error
+	)
+	if len(celestiaAccessToken) > 0 {
+		if s.CelestiaAccessToken, err = credentials.Secret(celestiaAccessToken, "celestia access token"); err != nil {
+			return s, err
+		}
+	}
+	if len(availSeed) > 0 {
+		if s.AvailSeed, err = credentials.Secret(availSeed, "avail seed"); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// SetSecrets fills [s] into every config.
+func SetSecrets(configs []Config, s Secrets) {
+	for i := range configs {
+		if len(s.CelestiaAccessToken) > 0 && configs[i].CelestiaDAConfig != nil {
+			configs[i].CelestiaDAConfig.AccessToken = s.CelestiaAccessToken
+		}
+		if len(s.AvailSeed) > 0 && configs[i].AvailDAConfig != nil {
+			configs[i].AvailDAConfig.Seed = s.AvailSeed
+		}
+	}
+}
+
+// Redacted returns a copy of [c] that is safe to print.
+func (c Config) Redacted() Config {
+	if c.CelestiaDAConfig != nil {
+		celestia := *c.CelestiaDAConfig
+		celestia.AccessToken = credentials.Redact(celestia.AccessToken)
+		c.CelestiaDAConfig = &celestia
+	}
+	if c.AvailDAConfig != nil {
+		avail := *c.AvailDAConfig
+		avail.Seed = credentials.Redact(avail.Seed)
+		c.AvailDAConfig = &avail
+	}
+	return c
+}
+
+// Write validates [configs], generated for [nodes], and saves them to
+// config<i>.json in [dir] along with a manifest of the relayers. Those using
+// EigenDA get a demo<i>.pk key, unless they already have one and [newKeys]
+// is false. Nothing is written if a config is invalid.
+func Write(dir string, nodes []Node, configs []Config, newKeys bool) (*Manifest, error) {
+	for i := range configs {
+		if err := configs[i].validateFields(); err != nil {
+			return nil, fmt.Errorf("relayer %d: %w", i, err)
+		}
+	}
+	m, err := newManifest(dir, nodes, configs)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range configs {
+		if r := &m.Relayers[i]; len(r.Key) > 0 {
+			if r.Address, err = ensureKey(r.Key, newKeys); err != nil {
+				return nil, fmt.Errorf("relayer %d: %s: %w", i, r.Key, err)
+			}
+		}
+		if err := c.Validate(dir); err != nil {
+			return nil, fmt.Errorf("relayer %d: %w", i, err)
+		}
+		d, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(m.Relayers[i].Config, d, 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return m, m.Save()
+}
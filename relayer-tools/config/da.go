@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Backend is the DA layer a relayer posts to.
+type Backend string
+
+const (
+	EigenDA  Backend = "eigenda"
+	Avail    Backend = "avail"
+	Celestia Backend = "celestia"
+)
+
+// Backends are all the DA backends, in the order of the config sections.
+var Backends = []Backend{EigenDA, Avail, Celestia}
+
+func parseBackend(s string) (Backend, error) {
+	for _, b := range Backends {
+		if Backend(strings.ToLower(s)) == b {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("unknown DA backend %q, want eigenda, avail or celestia", s)
+}
+
+// AssignBackends returns the DA backend of each of [n] relayers from [spec],
+// a comma separated list of backends and <index>=<backend> assignments, e.g.
+// "celestia,avail" or "eigenda,0=celestia". Relayers not assigned by index
+// go through the list round-robin.
+func AssignBackends(spec string, n int) ([]Backend, error) {
+	var list []Backend
+	assigned := map[int]Backend{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		index, name, explicit := strings.Cut(item, "=")
+		if !explicit {
+			b, err := parseBackend(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, b)
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(index))
+		if err != nil {
+			return nil, fmt.Errorf("%q: relayer index %q isn't a number", item, index)
+		}
+		if i < 0 || i >= n {
+			return nil, fmt.Errorf("%q: there is no relayer %d, the chain has %d nodes", item, i, n)
+		}
+		if _, ok := assigned[i]; ok {
+			return nil, fmt.Errorf("%q: relayer %d is assigned twice", item, i)
+		}
+		b, err := parseBackend(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		assigned[i] = b
+	}
+	backends := make([]Backend, n)
+	next := 0
+	for i := range backends {
+		if b, ok := assigned[i]; ok {
+			backends[i] = b
+			continue
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("no DA backend for relayer %d: list backends or assign it with %d=<backend>", i, i)
+		}
+		backends[i] = list[next%len(list)]
+		next++
+	}
+	return backends, nil
+}
+
+// section is the name of the config section of [b].
+func (b Backend) section() string {
+	switch b {
+	case EigenDA:
+		return "eigenDAConfig"
+	case Avail:
+		return "availDAConfig"
+	default:
+		return "celestiaDAConfig"
+	}
+}
+
+// Backends returns the backends [c] has a DA section for.
+func (c *Config) Backends() []Backend {
+	var backends []Backend
+	if c.EigenDAConfig != nil {
+		backends = append(backends, EigenDA)
+	}
+	if c.AvailDAConfig != nil {
+		backends = append(backends, Avail)
+	}
+	if c.CelestiaDAConfig != nil {
+		backends = append(backends, Celestia)
+	}
+	return backends
+}
+
+// only returns a copy of [c] with the DA section of [b] alone, which it
+// doesn't share with [c].
+func (c Config) only(b Backend) (Config, error) {
+	eigenDA, avail, celestia := c.EigenDAConfig, c.AvailDAConfig, c.CelestiaDAConfig
+	c.EigenDAConfig, c.AvailDAConfig, c.CelestiaDAConfig = nil, nil, nil
+	switch {
+	case b == EigenDA && eigenDA != nil:
+		s := *eigenDA
+		c.EigenDAConfig = &s
+	case b == Avail && avail != nil:
+		s := *avail
+		c.AvailDAConfig = &s
+	case b == Celestia && celestia != nil:
+		s := *celestia
+		s.NameSpaceID = append(NamespaceID(nil), celestia.NameSpaceID...)
+		c.CelestiaDAConfig = &s
+	default:
+		return c, fmt.Errorf("uses %s but the template has no %s", b, b.section())
+	}
+	return c, nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+// GenerateKey writes a new EigenDA key to [path] and returns the address
+// paying for the relayer's blobs.
+func GenerateKey(path string) (string, error) {
+	k, err := crypto.GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	return saveKey(path, k)
+}
+
+// ImportKey writes the secp256k1 key [spec] points at, see
+// [credentials.Load], to [path] and returns its address.
+func ImportKey(path string, spec string) (string, error) {
+	key, err := credentials.Load(spec, credentials.Secp256k1)
+	if err != nil {
+		return "", err
+	}
+	k, err := crypto.ToECDSA(key.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key.Source, err)
+	}
+	return saveKey(path, k)
+}
+
+// ExportKey returns the key at [path] hex encoded or, with a [password], as
+// an encrypted keystore to load as keystore:<file>.
+func ExportKey(path string, password []byte) ([]byte, error) {
+	k, err := crypto.LoadECDSA(path)
+	if err != nil {
+		return nil, err
+	}
+	key := credentials.New(credentials.Secp256k1, crypto.FromECDSA(k), path)
+	if password == nil {
+		return []byte(key.Hex() + "\n"), nil
+	}
+	return credentials.Encrypt(key, password)
+}
+
+// KeyAddress returns the address of the key at [path].
+func KeyAddress(path string) (string, error) {
+	k, err := crypto.LoadECDSA(path)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(k.PublicKey).Hex(), nil
+}
+
+// saveKey writes [k] to [path] in hex, as crypto.SaveECDSA does. The key
+// stays in plain text: the relayer's EigenDA config only has
+// privateKeyFilePath, no password or keystore setting, so it can't load an
+// encrypted key.
+func saveKey(path string, k *ecdsa.PrivateKey) (string, error) {
+	if err := WritePrivate(path, []byte(hex.EncodeToString(crypto.FromECDSA(k)))); err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(k.PublicKey).Hex(), nil
+}
+
+// WritePrivate writes [b] to [path], readable by its owner only. It is
+// written to a new 0600 file renamed over [path], so no one else can read it
+// even while a more open file at [path] is replaced.
+func WritePrivate(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// ensureKey returns the address of the key at [path], generating the key if
+// there is none or [replace] is set.
+func ensureKey(path string, replace bool) (string, error) {
+	if !replace {
+		_, err := os.Stat(path)
+		if err == nil {
+			return KeyAddress(path)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return GenerateKey(path)
+}
+
+// PrintKeys writes the key and address of every relayer of [m] using
+// EigenDA, the accounts to fund for its blobs.
+func PrintKeys(w io.Writer, m *Manifest) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RELAYER\tNODE\tKEY\tADDRESS")
+	for _, r := range m.Relayers {
+		if len(r.Key) == 0 {
+			continue
+		}
+		address := r.Address
+		if len(address) == 0 {
+			address = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", r.Index, r.Node, r.Key, address)
+	}
+	tw.Flush()
+}
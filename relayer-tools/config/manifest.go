@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ManifestFile is the name of the manifest in the directory of the configs.
+const ManifestFile = "relayers.json"
+
+// Manifest lists the relayers whose configs are in a directory, which is
+// the relayers' working directory.
+type Manifest struct {
+	Dir       string            `json:"dir"`
+	NetworkID uint32            `json:"networkID"`
+	Relayers  []ManifestRelayer `json:"relayers"`
+}
+
+// ManifestRelayer is one relayer of a [Manifest]. Paths are absolute.
+type ManifestRelayer struct {
+	Index   int    `json:"index"`
+	Node    string `json:"node"`
+	NodeURI string `json:"nodeURI"`
+	// ChainURI is the node's URI of the chain, which its clients talk to
+	ChainURI       string  `json:"chainURI"`
+	ChainID        string  `json:"chainID"`
+	Backend        Backend `json:"backend"`
+	Config         string  `json:"config"`
+	ServeRPC       string  `json:"serveRpc"`
+	ServeRPCPort   int     `json:"serveRpcPort"`
+	MessageNetPort int     `json:"messageNetPort"`
+	Key            string  `json:"key,omitempty"`
+	// Address is the Ethereum address of Key, which pays for the blobs
+	Address string `json:"address,omitempty"`
+	Log     string `json:"log"`
+	DB      string `json:"db"`
+}
+
+// LoadManifest reads the manifest in [dir], or returns nil if there is none.
+func LoadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFile)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+func newManifest(dir string, nodes []Node, configs []Config) (*Manifest, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	resolve := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(abs, p)
+	}
+	m := &Manifest{Dir: abs, Relayers: make([]ManifestRelayer, len(configs))}
+	for i, c := range configs {
+		m.NetworkID = c.SeqNode.NetworkID
+		r := ManifestRelayer{
+			Index:        i,
+			Node:         nodes[i].Endpoint.Node,
+			NodeURI:      c.SeqNode.NodeUrl,
+			ChainURI:     c.SeqNode.URI,
+			ChainID:      c.SeqNode.ChainID,
+			Backend:      c.Backends()[0],
+			Config:       filepath.Join(abs, "config"+strconv.Itoa(i)+".json"),
+			ServeRPC:     c.MetaConfig.ServeRpc,
+			ServeRPCPort: nodes[i].ServeRPCPort,
+			Log:          resolve(c.Log.LogFile),
+			DB:           resolve(c.DBConfig.File),
+		}
+		if _, port, err := net.SplitHostPort(nodes[i].MessageNetPort); err == nil {
+			r.MessageNetPort, _ = strconv.Atoi(port)
+		}
+		if c.EigenDAConfig != nil {
+			r.Key = resolve(c.EigenDAConfig.PrivateKeyFilePath)
+		}
+		m.Relayers[i] = r
+	}
+	return m, nil
+}
+
+// Save writes [m] to its directory.
+func (m *Manifest) Save() error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.Dir, ManifestFile), append(b, '\n'), 0o644)
+}
+
+// EigenDARelayer returns relayer [index] of [m], which has to use EigenDA.
+func (m *Manifest) EigenDARelayer(index int) (*ManifestRelayer, error) {
+	if index < 0 || index >= len(m.Relayers) {
+		return nil, fmt.Errorf("there is no relayer %d, %s has %d", index, m.Dir, len(m.Relayers))
+	}
+	r := &m.Relayers[index]
+	if len(r.Key) == 0 {
+		return nil, fmt.Errorf("relayer %d uses %s, not EigenDA", index, r.Backend)
+	}
+	return r, nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MockDAAvailSeed is the Avail seed of relayers posting to mock-da, which
+// doesn't check signatures.
+const MockDAAvailSeed = "//Alice"
+
+// MockDA are the endpoints of the DA stand-ins a mock-da process serves.
+type MockDA struct {
+	API      string `json:"-"`
+	Celestia *struct {
+		RPCAddress  string `json:"rpcAddress"`
+		AccessToken string `json:"accessToken"`
+	} `json:"celestia"`
+	EigenDA *struct {
+		Target string `json:"target"`
+	} `json:"eigenda"`
+	Avail *struct {
+		ApiURL string `json:"apiURL"`
+		AppID  int    `json:"appID"`
+	} `json:"avail"`
+}
+
+// LoadMockDA fetches the endpoints of the mock-da process whose API is at
+// [api], e.g. 127.0.0.1:26650.
+func LoadMockDA(ctx context.Context, api string) (*MockDA, error) {
+	if !strings.Contains(api, "://") {
+		api = "http://" + api
+	}
+	api = strings.TrimSuffix(api, "/")
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, api+"/endpoints", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("mock-da: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("mock-da: %s/endpoints: %s: %s", api, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	m := &MockDA{API: api}
+	if err := json.NewDecoder(resp.Body).Decode(m); err != nil {
+		return nil, fmt.Errorf("mock-da: %s/endpoints: %w", api, err)
+	}
+	return m, nil
+}
+
+// Apply points the DA section of every config at the stand-in of its
+// backend. The Celestia namespace and the EigenDA key are kept, an empty
+// Avail seed becomes [MockDAAvailSeed].
+func (m *MockDA) Apply(configs []Config) error {
+	for i := range configs {
+		c := &configs[i]
+		switch {
+		case c.EigenDAConfig != nil:
+			if m.EigenDA == nil {
+				return fmt.Errorf("relayer %d uses eigenda, which mock-da at %s doesn't serve", i, m.API)
+			}
+			c.EigenDAConfig.Target = m.EigenDA.Target
+		case c.AvailDAConfig != nil:
+			if m.Avail == nil {
+				return fmt.Errorf("relayer %d uses avail, which mock-da at %s doesn't serve", i, m.API)
+			}
+			c.AvailDAConfig.ApiURL = m.Avail.ApiURL
+			c.AvailDAConfig.AppID = m.Avail.AppID
+			if len(c.AvailDAConfig.Seed) == 0 {
+				c.AvailDAConfig.Seed = MockDAAvailSeed
+			}
+		case c.CelestiaDAConfig != nil:
+			if m.Celestia == nil {
+				return fmt.Errorf("relayer %d uses celestia, which mock-da at %s doesn't serve", i, m.API)
+			}
+			c.CelestiaDAConfig.RPCAddress = m.Celestia.RPCAddress
+			c.CelestiaDAConfig.AccessToken = m.Celestia.AccessToken
+		}
+	}
+	return nil
+}
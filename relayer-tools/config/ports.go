@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultPorts is the range the relayers' serve RPC ports are taken from.
+var DefaultPorts = PortRange{First: 12500, Last: 12599}
+
+// PortRange is the ports from First to Last, both included.
+type PortRange struct {
+	First int
+	Last  int
+}
+
+// ParsePorts parses a range such as "12500-12599".
+func ParsePorts(s string) (PortRange, error) {
+	first, last, ok := strings.Cut(s, "-")
+	if !ok {
+		return PortRange{}, fmt.Errorf("port range %q should be <first>-<last>", s)
+	}
+	var (
+		r   PortRange
+		err error
+	)
+	if r.First, err = strconv.Atoi(strings.TrimSpace(first)); err != nil {
+		return PortRange{}, fmt.Errorf("port range %q: %q isn't a port", s, first)
+	}
+	if r.Last, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+		return PortRange{}, fmt.Errorf("port range %q: %q isn't a port", s, last)
+	}
+	if r.First < 1 || r.Last > 65535 || r.First > r.Last {
+		return PortRange{}, fmt.Errorf("port range %q should be within 1-65535, first port first", s)
+	}
+	return r, nil
+}
+
+func (r PortRange) String() string {
+	return strconv.Itoa(r.First) + "-" + strconv.Itoa(r.Last)
+}
+
+func (r PortRange) contains(port int) bool {
+	return port >= r.First && port <= r.Last
+}
+
+// AssignPorts gives every node of [nodes] a serve RPC port from [r]. A node
+// in [previous], the manifest of an earlier run, keeps its port if it is in
+// the range, even if it is in use since its relayer may be running. The
+// others get the lowest ports no node has that can be listened on. Nothing
+// is assigned if the range runs out.
+func AssignPorts(nodes []Node, r PortRange, previous *Manifest) error {
+	ports := make([]int, len(nodes))
+	taken := map[int]bool{}
+	if previous != nil {
+		kept := map[string]int{}
+		for _, p := range previous.Relayers {
+			kept[p.Node] = p.ServeRPCPort
+		}
+		for i, n := range nodes {
+			if port, ok := kept[n.Endpoint.Node]; ok && r.contains(port) && !taken[port] {
+				ports[i] = port
+				taken[port] = true
+			}
+		}
+	}
+	next := r.First
+	for i, n := range nodes {
+		if ports[i] != 0 {
+			continue
+		}
+		for next <= r.Last && (taken[next] || !available(next)) {
+			next++
+		}
+		if next > r.Last {
+			return fmt.Errorf("no free port left in %s for %s", r, n.Endpoint.Node)
+		}
+		ports[i] = next
+		taken[next] = true
+	}
+	for i := range nodes {
+		nodes[i].ServeRPCPort = ports[i]
+	}
+	return nil
+}
+
+// available reports whether a relayer could serve on [port].
+func available(port int) bool {
+	l, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MaxNamespaceIDLen is how many bytes of a version 0 Celestia namespace are
+// up to the user.
+const MaxNamespaceIDLen = 10
+
+// NamespaceID is a Celestia namespace ID, base64 in JSON like any []byte.
+type NamespaceID []byte
+
+func (n *NamespaceID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("celestiaDAConfig.nameSpaceID: want a base64 string, got %s", b)
+	}
+	id, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("celestiaDAConfig.nameSpaceID: %q isn't base64: %w", s, err)
+	}
+	*n = id
+	return nil
+}
+
+// Validate checks that a relayer can start with [c]: its SEQ node, its own
+// endpoints and its one DA section. Relative key files are read from [dir],
+// the relayer's working directory.
+func (c *Config) Validate(dir string) error {
+	if err := c.validateFields(); err != nil {
+		return err
+	}
+	if c.EigenDAConfig != nil {
+		return c.EigenDAConfig.validateKey(dir)
+	}
+	return nil
+}
+
+// validateFields is [Validate] without the files.
+func (c *Config) validateFields() error {
+	if len(c.DBConfig.File) == 0 {
+		return errors.New("dbConfig.file: is empty")
+	}
+	if err := checkURL("seqNode.uri", c.SeqNode.URI, "http", "https"); err != nil {
+		return err
+	}
+	if err := checkURL("seqNode.nodeUrl", c.SeqNode.NodeUrl, "http", "https"); err != nil {
+		return err
+	}
+	if _, err := ids.FromString(c.SeqNode.ChainID); err != nil {
+		return fmt.Errorf("seqNode.chainID: %q isn't a chain ID: %w", c.SeqNode.ChainID, err)
+	}
+	if err := checkHostPort("metaConfig.serveRpc", c.MetaConfig.ServeRpc); err != nil {
+		return err
+	}
+	if err := checkHostPort("metaConfig.endpoint", c.MetaConfig.Endpoint); err != nil {
+		return err
+	}
+
+	backends := c.Backends()
+	switch len(backends) {
+	case 0:
+		return errors.New("no DA section: set one of eigenDAConfig, availDAConfig or celestiaDAConfig")
+	case 1:
+	default:
+		sections := make([]string, len(backends))
+		for i, b := range backends {
+			sections[i] = b.section()
+		}
+		return fmt.Errorf("%s: a relayer uses a single DA backend", strings.Join(sections, ", "))
+	}
+	switch backends[0] {
+	case EigenDA:
+		return c.EigenDAConfig.validate()
+	case Avail:
+		return c.AvailDAConfig.validate()
+	default:
+		return c.CelestiaDAConfig.validate()
+	}
+}
+
+func (c *EigenDAClientConfig) validate() error {
+	if err := checkHostPort("eigenDAConfig.target", c.Target); err != nil {
+		return err
+	}
+	if len(c.PrivateKeyFilePath) == 0 {
+		return errors.New("eigenDAConfig.privateKeyFilePath: is empty")
+	}
+	return nil
+}
+
+func (c *EigenDAClientConfig) validateKey(dir string) error {
+	path := c.PrivateKeyFilePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("eigenDAConfig.privateKeyFilePath: %w", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("eigenDAConfig.privateKeyFilePath: %s can be read by others (mode %o), chmod it to 600", path, info.Mode().Perm())
+	}
+	if _, err := crypto.LoadECDSA(path); err != nil {
+		return fmt.Errorf("eigenDAConfig.privateKeyFilePath: %w", err)
+	}
+	return nil
+}
+
+func (c *AvailClientConfig) validate() error {
+	if err := checkURL("availDAConfig.apiURL", c.ApiURL, "ws", "wss"); err != nil {
+		return err
+	}
+	if c.AppID < 0 || int64(c.AppID) > math.MaxUint32 {
+		return fmt.Errorf("availDAConfig.appID: %d is out of range, want 0 to %d", c.AppID, uint32(math.MaxUint32))
+	}
+	if len(c.Seed) == 0 {
+		return errors.New("availDAConfig.seed: is empty, pass it with --avail-seed")
+	}
+	return nil
+}
+
+func (c *CelestiaDAClientConfig) validate() error {
+	if n := len(c.NameSpaceID); n == 0 || n > MaxNamespaceIDLen {
+		return fmt.Errorf("celestiaDAConfig.nameSpaceID: is %d bytes, want 1 to %d", n, MaxNamespaceIDLen)
+	}
+	return checkURL("celestiaDAConfig.rpcAddress", c.RPCAddress, "http", "https", "ws", "wss")
+}
+
+// checkURL checks that [value] of [field] is an absolute URL with a host and
+// one of [schemes].
+func checkURL(field string, value string, schemes ...string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%s: %q isn't a URL: %w", field, value, err)
+	}
+	ok := false
+	for _, s := range schemes {
+		ok = ok || u.Scheme == s
+	}
+	if !ok {
+		return fmt.Errorf("%s: %q should start with %s://", field, value, strings.Join(schemes, ":// or "))
+	}
+	if len(u.Host) == 0 || u.Opaque != "" {
+		return fmt.Errorf("%s: %q has no host", field, value)
+	}
+	if p := u.Port(); len(p) > 0 {
+		if err := checkPort(field, value, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkHostPort checks that [value] of [field] is a host:port address.
+func checkHostPort(field string, value string) error {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return fmt.Errorf("%s: %q isn't host:port: %w", field, value, err)
+	}
+	return checkPort(field, value, port)
+}
+
+func checkPort(field string, value string, port string) error {
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return fmt.Errorf("%s: %q has an invalid port %q", field, value, port)
+	}
+	return nil
+}
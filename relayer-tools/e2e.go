@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/e2e"
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+// endToEnd submits messages to the relayers whose manifest is in a
+// directory and reports which reached their relayer and DA backend.
+func endToEnd(args []string) {
+	flags := flag.NewFlagSet("e2e", flag.ExitOnError)
+	opts := e2e.DefaultOptions
+	var (
+		namespaces string
+		relayerIDs string
+		out        string
+	)
+	flags.StringVar(&namespaces, "namespaces", strings.Join(opts.Namespaces, ","), "comma separated namespaces (ChainIds) to post to")
+	flags.StringVar(&relayerIDs, "relayer-ids", "", "comma separated RelayerIDs to post to, RelayerID i is expected at relayer i (default: every relayer)")
+	flags.IntVar(&opts.Messages, "messages", opts.Messages, "messages per namespace and RelayerID")
+	flags.IntVar(&opts.Size, "size", opts.Size, "payload size in bytes")
+	flags.StringVar(&opts.MockDA, "mock-da", opts.MockDA, "API of the mock-da process the relayers post to")
+	flags.StringVar(&opts.RelayerPath, "relayer-path", opts.RelayerPath, "path fetched from each relayer's serve RPC to look for the messages (empty to only check the DA)")
+	flags.DurationVar(&opts.RelayerTimeout, "relayer-timeout", opts.RelayerTimeout, "how long each relayer may take to answer 200 on --relayer-path before anything is submitted")
+	flags.DurationVar(&opts.Interval, "interval", opts.Interval, "how often the relayers and mock-da are polled")
+	flags.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "how long a message may take before it counts as dropped")
+	flags.DurationVar(&opts.Settle, "settle", opts.Settle, "how long to keep polling for duplicates once every message arrived")
+	flags.StringVar(&out, "out", "", "also write the report to this JSON file")
+	keySpec, allowDevKey := credentials.Flags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s e2e [flags] <dir>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	opts.Namespaces = nil
+	for _, ns := range strings.Split(namespaces, ",") {
+		if ns = strings.TrimSpace(ns); len(ns) > 0 {
+			opts.Namespaces = append(opts.Namespaces, ns)
+		}
+	}
+	for _, s := range strings.Split(relayerIDs, ",") {
+		if s = strings.TrimSpace(s); len(s) == 0 {
+			continue
+		}
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			panic(fmt.Sprintf("RelayerID %q isn't a number", s))
+		}
+		opts.RelayerIDs = append(opts.RelayerIDs, id)
+	}
+
+	m, err := config.LoadManifest(flags.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	if m == nil {
+		panic(fmt.Sprintf("no %s in %s", config.ManifestFile, flags.Arg(0)))
+	}
+	msgs, err := e2e.Plan(m, opts)
+	if err != nil {
+		panic(err)
+	}
+	uris := make([]string, len(m.Relayers))
+	for i, r := range m.Relayers {
+		uris[i] = r.ChainURI
+	}
+	key, err := credentials.Load(*keySpec, credentials.ED25519)
+	if err != nil {
+		panic(err)
+	}
+	if err := key.CheckNetwork(uris, *allowDevKey); err != nil {
+		panic(err)
+	}
+	priv, err := key.ED25519()
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	if err := e2e.CheckRelayers(ctx, m, opts); err != nil {
+		panic(err)
+	}
+	submitCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	fmt.Printf("submitting %d messages\n", len(msgs))
+	if err := e2e.Submit(submitCtx, m, priv, msgs); err != nil {
+		panic(err)
+	}
+	fmt.Printf("watching the relayers and %s for up to %s\n", opts.MockDA, opts.Timeout)
+	start := time.Now()
+	polls, err := e2e.Watch(ctx, m, msgs, opts)
+	if err != nil {
+		panic(err)
+	}
+	report := e2e.NewReport(msgs, polls)
+	report.Print(os.Stdout)
+	fmt.Printf("watched for %s\n", time.Since(start).Round(time.Second))
+	if len(out) > 0 {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(out, append(b, '\n'), 0o644); err != nil {
+			panic(err)
+		}
+		fmt.Println(out)
+	}
+	if report.Failed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,235 @@
+// Package e2e checks that the relayers of a manifest relay: it submits
+// SequencerMsg actions with known payloads and waits for each to show up at
+// the relayer its RelayerID names and on that relayer's DA backend.
+package e2e
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/crypto/ed25519"
+	"github.com/AnomalyFi/hypersdk/pubsub"
+	"github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	"github.com/AnomalyFi/nodekit-seq/auth"
+	trpc "github.com/AnomalyFi/nodekit-seq/rpc"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+)
+
+// MarkerPrefix starts the payload of every message, followed by the run and
+// message IDs, so a message can be found in whatever carries it.
+const MarkerPrefix = "seq-e2e/"
+
+type Options struct {
+	// Namespaces are the ChainIds the messages are posted to
+	Namespaces []string
+	// RelayerIDs are the RelayerIDs of the messages, RelayerID i is expected
+	// at the relayer with index i in the manifest
+	RelayerIDs []int
+	// Messages is how many messages go to each namespace and RelayerID
+	Messages int
+	// Size is the payload size, at least the size of the marker
+	Size int
+	// MockDA is the API of the mock-da process the relayers post to
+	MockDA string
+	// RelayerPath is what is fetched from each relayer's serve RPC to look
+	// for the messages; empty skips the relayers and only checks the DA
+	RelayerPath string
+	// RelayerTimeout is how long a relayer may take to first answer 200
+	// before the run fails without submitting anything
+	RelayerTimeout time.Duration
+	// Interval is how often the relayers and mock-da are polled
+	Interval time.Duration
+	// Timeout is how long a message may take to reach its backend before it
+	// counts as dropped
+	Timeout time.Duration
+	// Settle is how long polling goes on once every message arrived, to
+	// catch duplicates
+	Settle time.Duration
+}
+
+var DefaultOptions = Options{
+	Namespaces:     []string{"nkit"},
+	Messages:       3,
+	Size:           256,
+	MockDA:         "127.0.0.1:26650",
+	RelayerPath:    "/",
+	RelayerTimeout: 30 * time.Second,
+	Interval:       500 * time.Millisecond,
+	Timeout:        2 * time.Minute,
+	Settle:         5 * time.Second,
+}
+
+// Message is a SequencerMsg the run submitted and what became of it.
+type Message struct {
+	ID        int            `json:"id"`
+	Namespace string         `json:"namespace"`
+	RelayerID int            `json:"relayerID"`
+	Relayer   int            `json:"relayer"`
+	Backend   config.Backend `json:"backend"`
+	Marker    string         `json:"marker"`
+	TxID      ids.ID         `json:"txID"`
+	Submitted time.Time      `json:"submitted"`
+	// TxError is why the tx wasn't included or failed
+	TxError string `json:"txError,omitempty"`
+	// AtRelayer is when the message was first seen at its relayer
+	AtRelayer time.Time `json:"atRelayer,omitempty"`
+	// OnDA is when the first blob carrying the message was posted to its
+	// backend
+	OnDA time.Time `json:"onDA,omitempty"`
+	// Blobs are the blobs carrying the message, more than one is a duplicate
+	Blobs []BlobRef `json:"blobs,omitempty"`
+	// OtherRelayers are relayers the message was seen at besides its own
+	OtherRelayers []int `json:"otherRelayers,omitempty"`
+
+	payload []byte
+}
+
+// BlobRef is a blob on a DA backend.
+type BlobRef struct {
+	Backend string `json:"backend"`
+	Ref     string `json:"ref"`
+	Height  uint64 `json:"height"`
+}
+
+// Plan returns the messages of a run: [opts].Messages for every namespace
+// and RelayerID, each expected at the relayer of [m] with that index.
+func Plan(m *config.Manifest, opts Options) ([]*Message, error) {
+	if len(opts.Namespaces) == 0 {
+		return nil, errors.New("no namespaces")
+	}
+	relayerIDs := opts.RelayerIDs
+	if len(relayerIDs) == 0 {
+		for _, r := range m.Relayers {
+			relayerIDs = append(relayerIDs, r.Index)
+		}
+	}
+	if len(relayerIDs) == 0 {
+		return nil, errors.New("the manifest has no relayers")
+	}
+	if opts.Messages < 1 {
+		return nil, fmt.Errorf("%d messages per namespace and relayer, want at least 1", opts.Messages)
+	}
+	run := make([]byte, 4)
+	if _, err := rand.Read(run); err != nil {
+		return nil, err
+	}
+	var msgs []*Message
+	for _, ns := range opts.Namespaces {
+		for _, id := range relayerIDs {
+			r, err := relayer(m, id)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < opts.Messages; i++ {
+				msg := &Message{
+					ID:        len(msgs),
+					Namespace: ns,
+					RelayerID: id,
+					Relayer:   r.Index,
+					Backend:   r.Backend,
+					Marker:    MarkerPrefix + hex.EncodeToString(run) + "/" + strconv.Itoa(len(msgs)) + "/",
+				}
+				if msg.payload, err = payload(msg.Marker, opts.Size); err != nil {
+					return nil, err
+				}
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	return msgs, nil
+}
+
+func relayer(m *config.Manifest, index int) (*config.ManifestRelayer, error) {
+	for i := range m.Relayers {
+		if m.Relayers[i].Index == index {
+			return &m.Relayers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("RelayerID %d has no relayer, the manifest has %d", index, len(m.Relayers))
+}
+
+// payload is [marker] followed by random hex up to [size] bytes. It stays
+// printable, so it can be found in what a relayer returns as text.
+func payload(marker string, size int) ([]byte, error) {
+	if size < len(marker) {
+		return nil, fmt.Errorf("payload size %d is less than the %d bytes of the marker", size, len(marker))
+	}
+	filler := make([]byte, (size-len(marker)+1)/2)
+	if _, err := rand.Read(filler); err != nil {
+		return nil, err
+	}
+	return []byte(marker + hex.EncodeToString(filler)[:size-len(marker)]), nil
+}
+
+// Submit signs [msgs] with [key] and submits them to the node of the first
+// relayer of [m], then waits for their results. A message whose tx fails
+// gets a TxError.
+func Submit(ctx context.Context, m *config.Manifest, key ed25519.PrivateKey, msgs []*Message) error {
+	if len(m.Relayers) == 0 {
+		return errors.New("the manifest has no relayers")
+	}
+	uri := m.Relayers[0].ChainURI
+	chainID, err := ids.FromString(m.Relayers[0].ChainID)
+	if err != nil {
+		return fmt.Errorf("chain ID %q: %w", m.Relayers[0].ChainID, err)
+	}
+	parser, err := trpc.NewJSONRPCClient(uri, m.NetworkID, chainID).Parser(ctx)
+	if err != nil {
+		return err
+	}
+	cli := rpc.NewJSONRPCClient(uri)
+	ws, err := rpc.NewWebSocketClient(uri, rpc.DefaultHandshakeTimeout, pubsub.MaxPendingMessages, pubsub.MaxReadMessageSize)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	factory := auth.NewED25519Factory(key)
+	from := auth.NewED25519Address(key.PublicKey())
+
+	byTx := map[ids.ID]*Message{}
+	for _, msg := range msgs {
+		action := &actions.SequencerMsg{
+			ChainId:     []byte(msg.Namespace),
+			Data:        msg.payload,
+			FromAddress: from,
+			RelayerID:   msg.RelayerID,
+		}
+		_, tx, _, err := cli.GenerateTransaction(ctx, parser, []chain.Action{action}, factory)
+		if err != nil {
+			return fmt.Errorf("message %d: %w", msg.ID, err)
+		}
+		msg.TxID, msg.Submitted = tx.ID(), time.Now()
+		if err := ws.RegisterTx(tx); err != nil {
+			return fmt.Errorf("message %d: %w", msg.ID, err)
+		}
+		byTx[msg.TxID] = msg
+	}
+	for len(byTx) > 0 {
+		txID, dErr, result, err := ws.ListenTx(ctx)
+		if err != nil {
+			return err
+		}
+		msg, ok := byTx[txID]
+		if !ok {
+			continue
+		}
+		delete(byTx, txID)
+		switch {
+		case dErr != nil:
+			msg.TxError = dErr.Error()
+		case !result.Success:
+			msg.TxError = strings.TrimSpace(string(result.Error))
+		}
+	}
+	return nil
+}
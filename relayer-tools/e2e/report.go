@@ -0,0 +1,168 @@
+package e2e
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Status of a message at the end of a run, from the worst.
+const (
+	TxFailed   = "tx-failed"
+	Dropped    = "dropped"
+	Misrouted  = "misrouted"
+	Duplicated = "duplicated"
+	OK         = "ok"
+)
+
+// Result is what became of a message.
+type Result struct {
+	*Message
+	Status string `json:"status"`
+	// Problems explain the status, and any other problem the message had
+	Problems []string `json:"problems,omitempty"`
+	// RelayerLatency and DALatency are from submission to the relayer, at
+	// the resolution of the polling, and to the DA backend
+	RelayerLatency time.Duration `json:"relayerLatency,omitempty"`
+	DALatency      time.Duration `json:"daLatency,omitempty"`
+}
+
+type Report struct {
+	Results []Result       `json:"results"`
+	Counts  map[string]int `json:"counts"`
+	// Relayers are how polling each relayer went, if they were polled
+	Relayers []*RelayerPolls `json:"relayers,omitempty"`
+	// DA latency percentiles of the messages that reached their backend
+	P50 time.Duration `json:"p50"`
+	P95 time.Duration `json:"p95"`
+	Max time.Duration `json:"max"`
+}
+
+// NewReport judges [msgs] once watched; [polls] are what Watch returned for
+// the relayers, none if they weren't polled.
+func NewReport(msgs []*Message, polls []*RelayerPolls) *Report {
+	r := &Report{Counts: map[string]int{}, Relayers: polls}
+	relayers := len(polls) > 0
+	var latencies []time.Duration
+	for _, msg := range msgs {
+		res := Result{Message: msg}
+		if len(msg.TxError) > 0 {
+			res.Problems = append(res.Problems, "tx failed: "+msg.TxError)
+		}
+		if relayers {
+			if msg.AtRelayer.IsZero() {
+				problem := "not seen at relayer " + strconv.Itoa(msg.Relayer)
+				if p := r.relayer(msg.Relayer); p != nil && p.Failures > 0 {
+					problem += fmt.Sprintf(" (%d of %d polls failed, last: %s)", p.Failures, p.Polls, p.LastError)
+				}
+				res.Problems = append(res.Problems, problem)
+			} else {
+				res.RelayerLatency = msg.AtRelayer.Sub(msg.Submitted)
+			}
+		}
+		if msg.OnDA.IsZero() {
+			res.Problems = append(res.Problems, "not on "+string(msg.Backend))
+		} else {
+			res.DALatency = msg.OnDA.Sub(msg.Submitted)
+			latencies = append(latencies, res.DALatency)
+		}
+		for _, other := range msg.OtherRelayers {
+			res.Problems = append(res.Problems, "seen at relayer "+strconv.Itoa(other))
+		}
+		copies := 0
+		for _, b := range msg.Blobs {
+			if b.Backend == string(msg.Backend) {
+				copies++
+			} else {
+				res.Problems = append(res.Problems, fmt.Sprintf("posted to %s (%s)", b.Backend, b.Ref))
+			}
+		}
+		if copies > 1 {
+			res.Problems = append(res.Problems, fmt.Sprintf("posted %d times to %s", copies, msg.Backend))
+		}
+
+		switch {
+		case len(msg.TxError) > 0:
+			res.Status = TxFailed
+		case msg.OnDA.IsZero() || (relayers && msg.AtRelayer.IsZero()):
+			res.Status = Dropped
+		case len(msg.OtherRelayers) > 0 || copies < len(msg.Blobs):
+			res.Status = Misrouted
+		case copies > 1:
+			res.Status = Duplicated
+		default:
+			res.Status = OK
+		}
+		r.Counts[res.Status]++
+		r.Results = append(r.Results, res)
+	}
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		r.P50 = percentile(latencies, 0.5)
+		r.P95 = percentile(latencies, 0.95)
+		r.Max = latencies[len(latencies)-1]
+	}
+	return r
+}
+
+func (r *Report) relayer(index int) *RelayerPolls {
+	for _, p := range r.Relayers {
+		if p.Index == index {
+			return p
+		}
+	}
+	return nil
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	return sorted[min(len(sorted)-1, int(p*float64(len(sorted))))]
+}
+
+// Failed tells if any message isn't ok.
+func (r *Report) Failed() bool {
+	return r.Counts[OK] < len(r.Results)
+}
+
+// Print writes a line per message and the summary to [w].
+func (r *Report) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MSG\tNAMESPACE\tRELAYER ID\tRELAYER\tBACKEND\tSTATUS\tRELAYER LATENCY\tDA LATENCY\tBLOBS")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%d\n",
+			res.ID, res.Namespace, res.RelayerID, res.Relayer, res.Backend, res.Status,
+			latency(res.RelayerLatency), latency(res.DALatency), len(res.Blobs))
+	}
+	tw.Flush()
+
+	counts := make([]string, 0, len(r.Counts))
+	for _, s := range []string{OK, Duplicated, Misrouted, Dropped, TxFailed} {
+		if n := r.Counts[s]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, s))
+		}
+	}
+	fmt.Fprintf(w, "messages: %d (%s)\n", len(r.Results), strings.Join(counts, ", "))
+	if r.Max > 0 {
+		fmt.Fprintf(w, "DA latency: p50 %s, p95 %s, max %s\n", latency(r.P50), latency(r.P95), latency(r.Max))
+	}
+	for _, p := range r.Relayers {
+		if p.Failures > 0 {
+			fmt.Fprintf(w, "relayer %d: %d of %d polls failed, last: %s\n", p.Index, p.Failures, p.Polls, p.LastError)
+		}
+	}
+	for _, res := range r.Results {
+		if res.Status != OK {
+			fmt.Fprintf(w, "message %d (%s): %s\n", res.ID, res.Status, strings.Join(res.Problems, "; "))
+		}
+	}
+}
+
+func latency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,263 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+)
+
+// blob is a blob as mock-da's /blobs lists it.
+type blob struct {
+	Backend string    `json:"backend"`
+	Ref     string    `json:"ref"`
+	Height  uint64    `json:"height"`
+	Data    []byte    `json:"data"`
+	Time    time.Time `json:"time"`
+}
+
+// RelayerPolls is how polling a relayer went.
+type RelayerPolls struct {
+	Index    int    `json:"index"`
+	URL      string `json:"url"`
+	Polls    int    `json:"polls"`
+	Failures int    `json:"failures"`
+	// Errors count the failed polls by error, LastError is the latest
+	Errors    map[string]int `json:"errors,omitempty"`
+	LastError string         `json:"lastError,omitempty"`
+}
+
+func (p *RelayerPolls) record(err error) {
+	p.Polls++
+	if err == nil {
+		return
+	}
+	p.Failures++
+	if p.Errors == nil {
+		p.Errors = map[string]int{}
+	}
+	p.LastError = err.Error()
+	p.Errors[p.LastError]++
+}
+
+// relayerURL is what is fetched from relayer [r] to look for the messages.
+func relayerURL(r config.ManifestRelayer, opts Options) string {
+	return "http://" + r.ServeRPC + opts.RelayerPath
+}
+
+// CheckRelayers polls every relayer of [m] until it answers 200, and fails
+// if one doesn't within [opts].RelayerTimeout, so a run doesn't submit
+// messages to relayers that are down.
+func CheckRelayers(ctx context.Context, m *config.Manifest, opts Options) error {
+	if len(opts.RelayerPath) == 0 {
+		return nil
+	}
+	w := &watcher{client: &http.Client{Timeout: opts.Interval + 5*time.Second}}
+	deadline := time.Now().Add(opts.RelayerTimeout)
+	t := time.NewTicker(opts.Interval)
+	defer t.Stop()
+	pending := slices.Clone(m.Relayers)
+	for {
+		var lastErr error
+		pending = slices.DeleteFunc(pending, func(r config.ManifestRelayer) bool {
+			_, err := w.get(ctx, relayerURL(r, opts))
+			if err != nil {
+				lastErr = fmt.Errorf("relayer %d: %w", r.Index, err)
+			}
+			return err == nil
+		})
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d of %d relayers didn't answer 200 in %s, last: %w", len(pending), len(m.Relayers), opts.RelayerTimeout, lastErr)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Watch polls the relayers of [m] and mock-da until every message of [msgs]
+// whose tx went through reached its relayer and backend, and [opts].Settle
+// more, or until [opts].Timeout. It returns how polling each relayer went,
+// nothing if [opts].RelayerPath is empty.
+func Watch(ctx context.Context, m *config.Manifest, msgs []*Message, opts Options) ([]*RelayerPolls, error) {
+	api := opts.MockDA
+	if !strings.Contains(api, "://") {
+		api = "http://" + api
+	}
+	api = strings.TrimSuffix(api, "/")
+	var since time.Time
+	for _, msg := range msgs {
+		if since.IsZero() || msg.Submitted.Before(since) {
+			since = msg.Submitted
+		}
+	}
+	// mock-da's clock is this machine's, give it some slack anyway
+	since = since.Add(-time.Second)
+
+	w := &watcher{
+		client: &http.Client{Timeout: opts.Interval + 5*time.Second},
+		msgs:   msgs,
+		seen:   map[string]bool{},
+	}
+	var polls []*RelayerPolls
+	if len(opts.RelayerPath) > 0 {
+		for _, r := range m.Relayers {
+			polls = append(polls, &RelayerPolls{Index: r.Index, URL: relayerURL(r, opts)})
+		}
+	}
+	deadline := time.Now().Add(opts.Timeout)
+	var settled time.Time
+	t := time.NewTicker(opts.Interval)
+	defer t.Stop()
+	for {
+		if err := w.pollDA(ctx, api+"/blobs?since="+strconv.FormatInt(since.UnixMilli(), 10)); err != nil {
+			return polls, err
+		}
+		// A relayer that is down just hasn't got the messages, the report
+		// tells why
+		for _, p := range polls {
+			p.record(w.pollRelayer(ctx, p.Index, p.URL))
+		}
+		now := time.Now()
+		switch {
+		case now.After(deadline):
+			return polls, nil
+		case settled.IsZero() && w.arrived(len(polls) > 0):
+			settled = now.Add(opts.Settle)
+		case !settled.IsZero() && now.After(settled):
+			return polls, nil
+		}
+		select {
+		case <-ctx.Done():
+			return polls, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+type watcher struct {
+	client *http.Client
+	msgs   []*Message
+	// seen are the blobs already matched, by backend and ref
+	seen map[string]bool
+}
+
+// arrived tells if every message whose tx went through is on the DA and,
+// with [relayers], at its relayer.
+func (w *watcher) arrived(relayers bool) bool {
+	for _, msg := range w.msgs {
+		if len(msg.TxError) > 0 {
+			continue
+		}
+		if msg.OnDA.IsZero() || (relayers && msg.AtRelayer.IsZero()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *watcher) pollDA(ctx context.Context, url string) error {
+	body, err := w.get(ctx, url)
+	if err != nil {
+		return fmt.Errorf("mock-da: %w", err)
+	}
+	var blobs []blob
+	if err := json.Unmarshal(body, &blobs); err != nil {
+		return fmt.Errorf("mock-da: %s: %w", url, err)
+	}
+	for _, b := range blobs {
+		key := b.Backend + "/" + b.Ref
+		if w.seen[key] {
+			continue
+		}
+		w.seen[key] = true
+		// EigenDA clients pad every 31 bytes to a 32 byte field element
+		unpadded := unpad(b.Data)
+		for _, msg := range w.msgs {
+			if !contains(b.Data, msg) && !contains(unpadded, msg) {
+				continue
+			}
+			msg.Blobs = append(msg.Blobs, BlobRef{Backend: b.Backend, Ref: b.Ref, Height: b.Height})
+			if b.Backend == string(msg.Backend) && (msg.OnDA.IsZero() || b.Time.Before(msg.OnDA)) {
+				msg.OnDA = b.Time
+			}
+		}
+	}
+	return nil
+}
+
+func (w *watcher) pollRelayer(ctx context.Context, index int, url string) error {
+	body, err := w.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, msg := range w.msgs {
+		if !contains(body, msg) {
+			continue
+		}
+		switch {
+		case index != msg.Relayer:
+			if !slices.Contains(msg.OtherRelayers, index) {
+				msg.OtherRelayers = append(msg.OtherRelayers, index)
+			}
+		case msg.AtRelayer.IsZero():
+			msg.AtRelayer = now
+		}
+	}
+	return nil
+}
+
+func (w *watcher) get(ctx context.Context, url string) ([]byte, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// contains tells if [b] carries [msg]: its marker as is or hex encoded, or
+// its payload base64 encoded, as JSON encodes bytes.
+func contains(b []byte, msg *Message) bool {
+	return bytes.Contains(b, []byte(msg.Marker)) ||
+		bytes.Contains(b, []byte(hex.EncodeToString([]byte(msg.Marker)))) ||
+		bytes.Contains(b, []byte(base64.StdEncoding.EncodeToString(msg.payload)))
+}
+
+// unpad drops the first byte of every 32 bytes of [b].
+func unpad(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for len(b) > 0 {
+		n := min(32, len(b))
+		out = append(out, b[1:n]...)
+		b = b[n:]
+	}
+	return out
+}
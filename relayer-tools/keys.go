@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+// keys manages the EigenDA keys of the relayers whose manifest is in a
+// directory.
+func keys(args []string) {
+	if len(args) < 1 {
+		panic("Please specify list, generate, import or export")
+	}
+	action := args[0]
+	flags := flag.NewFlagSet("key "+action, flag.ExitOnError)
+	var (
+		force   bool
+		encrypt bool
+		out     string
+		usage   string
+	)
+	switch action {
+	case "list":
+		usage = "<dir>"
+	case "generate":
+		usage = "[--force] <dir> [relayer]..."
+		flags.BoolVar(&force, "force", false, "replace the keys of the relayers given")
+	case "import":
+		usage = "[--force] <dir> <relayer> <key>"
+		flags.BoolVar(&force, "force", false, "replace the relayer's key")
+	case "export":
+		usage = "[--encrypt] [--out <file>] <dir> <relayer>"
+		flags.BoolVar(&encrypt, "encrypt", false, "export an encrypted keystore, the password is $"+credentials.PasswordEnv+" or read from stdin")
+		flags.StringVar(&out, "out", "", "file to write the key to (default: stdout)")
+	default:
+		panic(fmt.Sprintf("unknown key command %q, want list, generate, import or export", action))
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s key %s %s\n", os.Args[0], action, usage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args[1:])
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	m, err := config.LoadManifest(flags.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	if m == nil {
+		panic(fmt.Sprintf("no %s in %s", config.ManifestFile, flags.Arg(0)))
+	}
+	relayers := flags.Args()[1:]
+
+	switch action {
+	case "list":
+		for i := range m.Relayers {
+			r := &m.Relayers[i]
+			if len(r.Key) > 0 {
+				// The key may have been replaced by hand
+				r.Address, _ = config.KeyAddress(r.Key)
+			}
+		}
+	case "generate":
+		if len(relayers) == 0 {
+			// Every EigenDA relayer without a key
+			for i := range m.Relayers {
+				r := &m.Relayers[i]
+				if len(r.Key) == 0 {
+					continue
+				}
+				if exists(r.Key) {
+					if r.Address, err = config.KeyAddress(r.Key); err != nil {
+						panic(fmt.Sprintf("relayer %d: %v", r.Index, err))
+					}
+					continue
+				}
+				if r.Address, err = config.GenerateKey(r.Key); err != nil {
+					panic(err)
+				}
+			}
+			break
+		}
+		for _, s := range relayers {
+			r := eigenDARelayer(m, s)
+			if !force && exists(r.Key) {
+				panic(fmt.Sprintf("relayer %d already has a key %s, --force replaces it", r.Index, r.Key))
+			}
+			if r.Address, err = config.GenerateKey(r.Key); err != nil {
+				panic(err)
+			}
+		}
+	case "import":
+		if len(relayers) != 2 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		r := eigenDARelayer(m, relayers[0])
+		if !force && exists(r.Key) {
+			panic(fmt.Sprintf("relayer %d already has a key %s, --force replaces it", r.Index, r.Key))
+		}
+		if r.Address, err = config.ImportKey(r.Key, relayers[1]); err != nil {
+			panic(err)
+		}
+	case "export":
+		if len(relayers) != 1 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		r := eigenDARelayer(m, relayers[0])
+		var password []byte
+		if encrypt {
+			if password, err = credentials.Password("new keystore password: "); err != nil {
+				panic(err)
+			}
+		}
+		b, err := config.ExportKey(r.Key, password)
+		if err != nil {
+			panic(err)
+		}
+		if len(out) == 0 {
+			os.Stdout.Write(b)
+			return
+		}
+		if err := config.WritePrivate(out, b); err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s: key of relayer %d\n", out, r.Index)
+		return
+	}
+	if err := m.Save(); err != nil {
+		panic(err)
+	}
+	config.PrintKeys(os.Stdout, m)
+}
+
+func eigenDARelayer(m *config.Manifest, s string) *config.ManifestRelayer {
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		panic(fmt.Sprintf("relayer %q isn't an index", s))
+	}
+	r, err := m.EigenDARelayer(index)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
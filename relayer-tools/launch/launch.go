@@ -0,0 +1,233 @@
+// Package launch writes what starts the relayers of a manifest: a
+// docker-compose file, systemd units or a Procfile, and a script checking
+// that they serve.
+package launch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+)
+
+const (
+	Compose  = "compose"
+	Systemd  = "systemd"
+	Procfile = "procfile"
+)
+
+// Formats are all the launch formats.
+var Formats = []string{Compose, Systemd, Procfile}
+
+// Options are how the relayers are started.
+type Options struct {
+	// Command starts one relayer; {config}, {index} and {dir} are replaced
+	// with its config, index and working directory
+	Command string
+	// Image is the docker image with the relayer, and curl for the health
+	// check
+	Image string
+}
+
+var DefaultOptions = Options{
+	Command: "relayer --config {config}",
+	Image:   "relayer:latest",
+}
+
+// ParseFormats splits a comma separated list of formats, "all" being every
+// one of them.
+func ParseFormats(s string) ([]string, error) {
+	var formats []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch f {
+		case "":
+		case "all":
+			return Formats, nil
+		case Compose, Systemd, Procfile:
+			formats = append(formats, f)
+		default:
+			return nil, fmt.Errorf("unknown launch format %q, want %s or all", f, strings.Join(Formats, ", "))
+		}
+	}
+	return formats, nil
+}
+
+// Write writes the [formats] of the relayers of [m], and health.sh, to the
+// manifest's directory and returns the paths written.
+func Write(m *config.Manifest, formats []string, opts Options) ([]string, error) {
+	if len(strings.Fields(opts.Command)) == 0 {
+		return nil, errors.New("no relayer command")
+	}
+	var paths []string
+	write := func(name string, content string, perm os.FileMode) error {
+		path := filepath.Join(m.Dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	}
+	for _, f := range formats {
+		switch f {
+		case Compose:
+			if err := write("docker-compose.yml", compose(m, opts), 0o644); err != nil {
+				return nil, err
+			}
+		case Systemd:
+			for _, r := range m.Relayers {
+				if err := write(filepath.Join("systemd", unit(r)), service(m, r, opts), 0o644); err != nil {
+					return nil, err
+				}
+			}
+		case Procfile:
+			if err := write("Procfile", procfile(m, opts), 0o644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := write("health.sh", healthScript(m), 0o755); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// command is the relayer command of [r], split in arguments.
+func command(m *config.Manifest, r config.ManifestRelayer, opts Options) []string {
+	replacer := strings.NewReplacer("{config}", r.Config, "{index}", strconv.Itoa(r.Index), "{dir}", m.Dir)
+	args := strings.Fields(opts.Command)
+	for i, a := range args {
+		args[i] = replacer.Replace(a)
+	}
+	return args
+}
+
+func name(r config.ManifestRelayer) string {
+	return "relayer" + strconv.Itoa(r.Index)
+}
+
+func unit(r config.ManifestRelayer) string {
+	return name(r) + ".service"
+}
+
+// healthURL is where [r] serves; any HTTP answer means it is up.
+func healthURL(r config.ManifestRelayer) string {
+	return "http://" + r.ServeRPC + "/"
+}
+
+// healthCheck is the command checking that [r] serves.
+func healthCheck(r config.ManifestRelayer) []string {
+	return []string{"curl", "-s", "-o", "/dev/null", "--max-time", "3", healthURL(r)}
+}
+
+// compose runs every relayer in its own container on the host network,
+// where the nodes are, with the directory mounted at the same path so the
+// manifest's paths hold inside.
+func compose(m *config.Manifest, opts Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by relayer-tools from %s\n", filepath.Join(m.Dir, config.ManifestFile))
+	b.WriteString("services:\n")
+	for _, r := range m.Relayers {
+		fmt.Fprintf(&b, "  %s:\n", name(r))
+		fmt.Fprintf(&b, "    image: %s\n", composeQuote(opts.Image))
+		fmt.Fprintf(&b, "    command: %s\n", yamlList(command(m, r, opts)))
+		fmt.Fprintf(&b, "    working_dir: %s\n", composeQuote(m.Dir))
+		fmt.Fprintf(&b, "    volumes:\n      - %s\n", composeQuote(m.Dir+":"+m.Dir))
+		b.WriteString("    network_mode: host\n")
+		b.WriteString("    restart: unless-stopped\n")
+		fmt.Fprintf(&b, "    labels:\n      seq.relayer.node: %s\n      seq.relayer.backend: %s\n      seq.relayer.log: %s\n      seq.relayer.db: %s\n",
+			composeQuote(r.Node), composeQuote(string(r.Backend)), composeQuote(r.Log), composeQuote(r.DB))
+		b.WriteString("    healthcheck:\n")
+		fmt.Fprintf(&b, "      test: %s\n", yamlList(append([]string{"CMD"}, healthCheck(r)...)))
+		b.WriteString("      interval: 10s\n      timeout: 5s\n      retries: 3\n      start_period: 10s\n")
+	}
+	return b.String()
+}
+
+func yamlList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = composeQuote(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// composeQuote quotes [s] as a YAML string docker compose doesn't
+// interpolate.
+func composeQuote(s string) string {
+	return strconv.Quote(strings.ReplaceAll(s, "$", "$$"))
+}
+
+// service is the systemd unit of [r]. It only counts as started once the
+// relayer serves.
+func service(m *config.Manifest, r config.ManifestRelayer, opts Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by relayer-tools from %s\n", filepath.Join(m.Dir, config.ManifestFile))
+	fmt.Fprintf(&b, "# Log: %s\n# DB: %s\n", r.Log, r.DB)
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=SEQ relayer %d for %s (%s)\n", r.Index, r.Node, r.Backend)
+	b.WriteString("After=network-online.target\nWants=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdEscape(m.Dir))
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdEscape(shellJoin(command(m, r, opts))))
+	check := shellJoin(healthCheck(r))
+	fmt.Fprintf(&b, "ExecStartPost=/bin/sh -c %s\n", systemdEscape(shellQuote("for i in $(seq 30); do "+check+" && exit 0; sleep 1; done; exit 1")))
+	b.WriteString("Restart=on-failure\nRestartSec=5\n\n")
+	b.WriteString("[Install]\nWantedBy=multi-user.target\n")
+	return b.String()
+}
+
+// procfile starts every relayer from the directory.
+func procfile(m *config.Manifest, opts Options) string {
+	var b strings.Builder
+	for _, r := range m.Relayers {
+		fmt.Fprintf(&b, "%s: cd %s && exec %s\n", name(r), shellQuote(m.Dir), shellJoin(command(m, r, opts)))
+	}
+	return b.String()
+}
+
+// healthScript checks every relayer and fails if one doesn't serve.
+func healthScript(m *config.Manifest) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# Generated by relayer-tools from %s\n", filepath.Join(m.Dir, config.ManifestFile))
+	b.WriteString("status=0\n")
+	b.WriteString("check() {\n")
+	b.WriteString("\tname=$1\n\tshift\n")
+	b.WriteString("\tif \"$@\"; then\n\t\techo \"$name ok\"\n\telse\n\t\techo \"$name FAIL\"\n\t\tstatus=1\n\tfi\n")
+	b.WriteString("}\n")
+	for _, r := range m.Relayers {
+		fmt.Fprintf(&b, "check %s %s\n", shellQuote(name(r)+" ("+r.ServeRPC+")"), shellJoin(healthCheck(r)))
+	}
+	b.WriteString("exit $status\n")
+	return b.String()
+}
+
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes [s] for sh, and systemd, if it needs to be.
+func shellQuote(s string) string {
+	if len(s) > 0 && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// systemdEscape keeps systemd from expanding specifiers and variables in
+// [s].
+func systemdEscape(s string) string {
+	return strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+}
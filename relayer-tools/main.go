@@ -3,176 +3,198 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"math/rand"
 	"os"
-	"strconv"
-	"strings"
-	"time"
+	"path/filepath"
 
-	hrpc "github.com/AnomalyFi/hypersdk/rpc"
-	srpc "github.com/AnomalyFi/nodekit-seq/rpc"
-	"github.com/ava-labs/avalanche-network-runner/client"
-	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ava-labs/avalanchego/utils/logging"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/launch"
+	"github.com/AnomalyFi/tools/common/anr"
+	commonconfig "github.com/AnomalyFi/tools/common/config"
 )
 
-type CelestiaDAClientConfig struct {
-	NameSpaceID []byte `json:"nameSpaceID"`
-	RPCAddress  string `json:"rpcAddress"`
-	AccessToken string `json:"accessToken"`
-}
-
-type EigenDAClientConfig struct {
-	Target             string `json:"target"`
-	PrivateKeyFilePath string `json:"privateKeyFilePath"`
-}
-
-type AvailClientConfig struct {
-	ApiURL string `json:"apiURL"`
-	AppID  int    `json:"appID"`
-	Seed   string `json:"seed"`
-}
-
-type DatabaseConfig struct {
-	File         string `json:"file"`
-	RetainWindow uint64 `json:"retainWindow"`
-}
-
-type Config struct {
-	DBConfig   DatabaseConfig `json:"dbConfig"`
-	SeqNode    SeqNodeInfo    `json:"seqNode"`
-	Log        LogConfig      `json:"log"`
-	MetaConfig MetaConfig     `json:"metaConfig"`
-	// DA configs
-	EigenDAConfig    EigenDAClientConfig    `json:"eigenDAConfig"`
-	AvailDAConfig    AvailClientConfig      `json:"availDAConfig"`
-	CelestiaDAConfig CelestiaDAClientConfig `json:"celestiaDAConfig"`
-}
-
-type SeqNodeInfo struct {
-	URI       string `json:"uri"`
-	NetworkID uint32 `json:"networkID"`
-	NodeUrl   string `json:"nodeUrl"`
-	// need to be unmarshalled
-	ChainID string `json:"chainID"`
-}
-
-type LogConfig struct {
-	Level     string `json:"level"`
-	ToConsole bool   `json:"toConsole"`
-	LogFile   string `json:"logFile"`
-}
-
-type MetaConfig struct {
-	ServeRpc string `json:"serveRpc"`
-	Endpoint string `json:"endpoint"`
-}
+var (
+	anrEndpoint   = flag.String("anr", anr.DefaultEndpoint, "avalanche-network-runner control server")
+	chain         = flag.String("chain", "", "name or ID of the custom chain to use (required if the cluster has several)")
+	networkName   = commonconfig.NetworkFlag(flag.CommandLine)
+	out           = flag.String("out", ".", "directory to write the configs, keys and manifest to; the relayers' working directory")
+	ports         = flag.String("ports", config.DefaultPorts.String(), "range the relayers' serve RPC ports are taken from")
+	newKeys       = flag.Bool("new-keys", false, "replace the EigenDA keys already in --out instead of keeping them")
+	launchFormats = flag.String("launch", "", "also write what starts the relayers to --out: compose, systemd, procfile or all, comma separated")
+	relayerCmd    = flag.String("relayer-cmd", launch.DefaultOptions.Command, "command starting a relayer; {config}, {index} and {dir} are replaced")
+	image         = flag.String("image", launch.DefaultOptions.Image, "docker image of the relayer in the compose file, which needs curl for the health check")
+	da            = flag.String("da", string(config.EigenDA), "DA backend of each relayer: backends used round-robin and <index>=<backend> assignments, e.g. celestia,avail or eigenda,0=celestia")
+	mockDA        = flag.String("mock-da", "", "API of a mock-da process, e.g. 127.0.0.1:26650, to point the relayers' DA sections at instead of the template's endpoints")
+
+	celestiaToken = flag.String("celestia-token", "", "where to read the Celestia access token from: env:NAME, file:PATH or stdin (default: the template's)")
+	availSeed     = flag.String("avail-seed", "", "where to read the Avail seed from: env:NAME, file:PATH or stdin (default: the template's)")
+)
 
 func main() {
+	flag.Parse()
 	ctx := context.Background()
 
 	// read config file tempelate
-	args := os.Args[1:]
+	args := flag.Args()
 	if len(args) < 1 {
 		panic("Please specify config file path")
 	}
+	if args[0] == "validate" {
+		validate(args[1:])
+		return
+	}
+	if args[0] == "key" {
+		keys(args[1:])
+		return
+	}
+	if args[0] == "launch" {
+		writeLaunch(args[1:])
+		return
+	}
+	if args[0] == "e2e" {
+		endToEnd(args[1:])
+		return
+	}
+	formats, err := launch.ParseFormats(*launchFormats)
+	if err != nil {
+		panic(err)
+	}
 	fmt.Println(args)
 
-	configBytes, err := os.ReadFile(args[0])
+	template, err := config.Load(args[0])
 	if err != nil {
-		panic(fmt.Sprintf("unable to open file %s\n", args[0]))
+		panic(err)
 	}
-
-	var config Config
-	if err := json.Unmarshal(configBytes, &config); err != nil {
-		panic(fmt.Sprintf("unable to parse config:\n %s\n", string(configBytes)))
+	secrets, err := config.LoadSecrets(*celestiaToken, *availSeed)
+	if err != nil {
+		panic(err)
 	}
 
+	// A named network replaces --anr and --chain
+	if len(*networkName) > 0 {
+		n, err := commonconfig.LoadNetwork(*networkName)
+		if err != nil {
+			panic(err)
+		}
+		if n.Discovery() != "anr" {
+			panic(fmt.Sprintf("network %s isn't discovered through ANR", *networkName))
+		}
+		*anrEndpoint, *chain = n.ANR, n.Chain
+	}
 	// Load new items from ANR
-	anrCli, err := client.New(client.Config{
-		Endpoint:    "0.0.0.0:12352",
-		DialTimeout: 10 * time.Second,
-	}, logging.NoLog{})
+	cluster, err := anr.Load(ctx, *anrEndpoint)
 	if err != nil {
 		panic(err)
 	}
-	status, err := anrCli.Status(ctx)
+	seqChain, err := cluster.Chain(*chain)
 	if err != nil {
 		panic(err)
 	}
-	subnets := map[ids.ID][]ids.ID{}
-	uris := []string{}
-	nodeUrls := []string{}
-	for chain, chainInfo := range status.ClusterInfo.CustomChains {
-		chainID, err := ids.FromString(chain)
+	// fetch network id and serverless ports for every node
+	networkID, nodes, err := config.Discover(ctx, cluster, seqChain)
+	if err != nil {
+		panic(err)
+	}
+	for _, n := range nodes {
+		fmt.Println(n.MessageNetPort)
+	}
+	// keep the ports of an earlier run in --out
+	portRange, err := config.ParsePorts(*ports)
+	if err != nil {
+		panic(err)
+	}
+	previous, err := config.LoadManifest(*out)
+	if err != nil {
+		panic(err)
+	}
+	// create new config file(s)
+	configs, err := config.Build(ctx, *template, networkID, nodes, config.Options{
+		Ports:    portRange,
+		Previous: previous,
+		DA:       *da,
+		Secrets:  secrets,
+		MockDA:   *mockDA,
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		panic(err)
+	}
+	manifest, err := config.Write(*out, nodes, configs, *newKeys)
+	if err != nil {
+		panic(err)
+	}
+	for i, r := range manifest.Relayers {
+		d, err := json.Marshal(configs[i].Redacted())
 		if err != nil {
 			panic(err)
 		}
-		subnetID, err := ids.FromString(chainInfo.SubnetId)
+		fmt.Println(r.Config, string(d))
+	}
+	fmt.Println(filepath.Join(manifest.Dir, config.ManifestFile))
+	// the EigenDA accounts to fund
+	config.PrintKeys(os.Stdout, manifest)
+	if len(formats) > 0 {
+		paths, err := launch.Write(manifest, formats, launch.Options{Command: *relayerCmd, Image: *image})
 		if err != nil {
 			panic(err)
 		}
-		chainIDs, ok := subnets[subnetID]
-		if !ok {
-			chainIDs = []ids.ID{}
+		for _, p := range paths {
+			fmt.Println(p)
 		}
-		chainIDs = append(chainIDs, chainID)
-		subnets[subnetID] = chainIDs
 	}
-	var filledChainID ids.ID
-	for _, nodeInfo := range status.ClusterInfo.NodeInfos {
-		if len(nodeInfo.WhitelistedSubnets) == 0 {
-			continue
+}
+
+// validate checks generated configs, reading their key files relative to
+// the config's directory.
+func validate(paths []string) {
+	if len(paths) == 0 {
+		panic("Please specify the configs to validate")
+	}
+	failed := false
+	for _, p := range paths {
+		c, err := config.Load(p)
+		if err == nil {
+			err = c.Validate(filepath.Dir(p))
 		}
-		trackedSubnets := strings.Split(nodeInfo.WhitelistedSubnets, ",")
-		for _, subnet := range trackedSubnets {
-			subnetID, err := ids.FromString(subnet)
-			if err != nil {
-				panic(err)
-			}
-			for _, chainID := range subnets[subnetID] {
-				uri := fmt.Sprintf("%s/ext/bc/%s", nodeInfo.Uri, chainID)
-				uris = append(uris, uri)
-				nodeUrls = append(nodeUrls, nodeInfo.Uri)
-				filledChainID = chainID
-			}
+		if err != nil {
+			failed = true
+			fmt.Printf("%s: %v\n", p, err)
+			continue
 		}
+		fmt.Printf("%s: ok (%s)\n", p, c.Backends()[0])
 	}
-	// fetch network id
-	hcli := hrpc.NewJSONRPCClient(uris[0])
-	networkID, _, _, err := hcli.Network(ctx)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// writeLaunch writes the launch files of the relayers in a directory from
+// its manifest, every format unless --launch picks some.
+func writeLaunch(args []string) {
+	if len(args) != 1 {
+		panic("Please specify the directory of the relayers")
+	}
+	manifest, err := config.LoadManifest(args[0])
 	if err != nil {
 		panic(err)
 	}
-	// fetch serverless ports for every node
-	for i, uri := range uris {
-		cli := srpc.NewJSONRPCClient(uri, networkID, filledChainID)
-		port, err := cli.MessageNetPort(ctx)
-
-		fmt.Println(port)
-		if err != nil {
-			panic(err)
-		}
-		// modify config file
-		config.SeqNode.URI = uri
-		config.SeqNode.NetworkID = networkID
-		config.SeqNode.ChainID = filledChainID.String()
-		config.MetaConfig.Endpoint = "localhost" + port
-		config.SeqNode.NodeUrl = nodeUrls[i]
-		config.Log.LogFile = "./relayer" + strconv.Itoa(i) + ".log"
-		config.DBConfig.File = "./relayer" + strconv.Itoa(i) + ".db"
-		config.EigenDAConfig.PrivateKeyFilePath = "./demo" + strconv.Itoa(i) + ".pk"
-		r := rand.Intn(100) + 12500
-		config.MetaConfig.ServeRpc = "127.0.0.1:" + strconv.Itoa(r)
-		// create new config file(s)
-		d, err := json.Marshal(config)
-		if err != nil {
+	if manifest == nil {
+		panic(fmt.Sprintf("no %s in %s", config.ManifestFile, args[0]))
+	}
+	formats := launch.Formats
+	if len(*launchFormats) > 0 {
+		if formats, err = launch.ParseFormats(*launchFormats); err != nil {
 			panic(err)
 		}
-		fmt.Println(os.WriteFile("config"+strconv.Itoa(i)+".json", d, 0644))
-		privKey, _ := crypto.GenerateKey()
-		crypto.SaveECDSA("demo"+strconv.Itoa(i)+".pk", privKey)
+	}
+	paths, err := launch.Write(manifest, formats, launch.Options{Command: *relayerCmd, Image: *image})
+	if err != nil {
+		panic(err)
+	}
+	for _, p := range paths {
+		fmt.Println(p)
 	}
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	oracle "github.com/AnomalyFi/nodekit-tools/oracle-tools/config"
+	relayer "github.com/AnomalyFi/nodekit-tools/relayer-tools/config"
+	"github.com/AnomalyFi/nodekit-tools/relayer-tools/launch"
+	"github.com/AnomalyFi/tools/common/anr"
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+var anrCommand = &command{
+	name:    "anr",
+	summary: "Work with an avalanche-network-runner cluster.",
+	commands: []*command{
+		{
+			name:    "configs",
+			summary: "Point config templates at the nodes of the cluster.",
+			commands: []*command{
+				{
+					name:    "oracle",
+					args:    "<template>",
+					summary: "Write an oracle config talking to one node of the chain.",
+					details: "The oracle signs with --key, or with the template's key if --key and the profile set none.",
+					network: true,
+					flags: func(fs *flag.FlagSet) {
+						fs.IntVar(&oracleNode, "node", oracle.DefaultNode, "index of the node, among those serving the chain, the oracle talks to (-1: the third, or the last of smaller clusters)")
+						fs.StringVar(&oracleOut, "out", "config.json", "file to write the config to")
+					},
+					run: runOracleConfig,
+				},
+				{
+					name:    "relayer",
+					args:    "<template>",
+					summary: "Write a relayer config for every node of the chain, and an EigenDA key for those using EigenDA.",
+					details: "--da picks each relayer's DA backend, e.g. celestia,avail alternates them and eigenda,0=celestia\n" +
+						"only gives the first relayer Celestia. A config only has its backend's section, and is validated\n" +
+						"before anything is written. Serve RPC ports are the lowest free ones of --ports; a rerun into the\n" +
+						"same --out-dir keeps the ports of its relayers.json manifest and the existing keys. --launch also\n" +
+						"writes a docker-compose file, systemd units or a Procfile starting them, and health.sh. --mock-da\n" +
+						"points the DA sections at the stand-ins of a running mock-da instead of the template's endpoints.",
+					network: true,
+					flags: func(fs *flag.FlagSet) {
+						fs.StringVar(&relayerDir, "out-dir", ".", "directory to write the configs and keys to")
+						fs.StringVar(&relayerPorts, "ports", relayer.DefaultPorts.String(), "range the relayers' serve RPC ports are taken from")
+						fs.BoolVar(&relayerNewKeys, "new-keys", false, "replace the EigenDA keys already in --out-dir instead of keeping them")
+						fs.StringVar(&relayerLaunch, "launch", "", "also write what starts the relayers: compose, systemd, procfile or all, comma separated")
+						fs.StringVar(&relayerLaunchOptions.Command, "relayer-cmd", launch.DefaultOptions.Command, "command starting a relayer; {config}, {index} and {dir} are replaced")
+						fs.StringVar(&relayerLaunchOptions.Image, "image", launch.DefaultOptions.Image, "docker image of the relayer in the compose file, which needs curl for the health check")
+						fs.StringVar(&relayerDA, "da", string(relayer.EigenDA), "DA backend of each relayer: backends used round-robin and <index>=<backend> assignments")
+						fs.StringVar(&celestiaToken, "celestia-token", "", "where to read the Celestia access token from: env:NAME, file:PATH or stdin (default: the template's)")
+						fs.StringVar(&availSeed, "avail-seed", "", "where to read the Avail seed from: env:NAME, file:PATH or stdin (default: the template's)")
+						fs.StringVar(&mockDA, "mock-da", "", "API of a mock-da process, e.g. 127.0.0.1:26650, to point the DA sections at")
+					},
+					run: runRelayerConfigs,
+				},
+			},
+		},
+	},
+}
+
+var (
+	oracleNode     int
+	oracleOut      string
+	relayerDir     string
+	relayerDA      string
+	relayerPorts   string
+	relayerNewKeys bool
+	relayerLaunch  string
+
+	relayerLaunchOptions launch.Options
+	celestiaToken        string
+	availSeed            string
+	mockDA               string
+)
+
+var errNeedsANR = errors.New("configs are generated from an ANR cluster, not a list of URIs")
+
+// cluster loads the cluster and chain of the network selected, which has to
+// be discovered through ANR.
+func (e *env) cluster(ctx context.Context) (*anr.Cluster, *anr.Chain, error) {
+	n, err := e.network()
+	if err != nil {
+		return nil, nil, err
+	}
+	if n.Discovery() != "anr" {
+		return nil, nil, errNeedsANR
+	}
+	cluster, err := anr.Load(ctx, n.ANR)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain, err := cluster.Chain(n.Chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cluster, chain, nil
+}
+
+func runOracleConfig(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	template, err := oracle.Load(args[0])
+	if err != nil {
+		return err
+	}
+	cluster, chain, err := e.cluster(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint, networkID, err := oracle.Discover(ctx, cluster, chain, oracleNode)
+	if err != nil {
+		return err
+	}
+	c := oracle.Generate(*template, endpoint, networkID)
+	if len(c.SEQConfig.Ed25519PrivateKeyHex) == 0 || len(e.globals.key) > 0 || len(e.profile.Key) > 0 {
+		key, err := e.key([]string{endpoint.URI})
+		if err != nil {
+			return err
+		}
+		c.SEQConfig.Ed25519PrivateKeyHex = key.Hex()
+	} else {
+		// The template's key gets the checks of a --key
+		key, err := credentials.Parse(c.SEQConfig.Ed25519PrivateKeyHex, credentials.ED25519, args[0])
+		if err != nil {
+			return err
+		}
+		if err := key.CheckNetwork([]string{endpoint.URI}, e.globals.allowDev); err != nil {
+			return err
+		}
+	}
+	d, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(oracleOut, d, 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "%s: %s (%s)\n", oracleOut, endpoint.Node, endpoint.URI)
+	return nil
+}
+
+func runRelayerConfigs(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	template, err := relayer.Load(args[0])
+	if err != nil {
+		return err
+	}
+	secrets, err := relayer.LoadSecrets(celestiaToken, availSeed)
+	if err != nil {
+		return err
+	}
+	ports, err := relayer.ParsePorts(relayerPorts)
+	if err != nil {
+		return err
+	}
+	formats, err := launch.ParseFormats(relayerLaunch)
+	if err != nil {
+		return err
+	}
+	cluster, chain, err := e.cluster(ctx)
+	if err != nil {
+		return err
+	}
+	networkID, nodes, err := relayer.Discover(ctx, cluster, chain)
+	if err != nil {
+		return err
+	}
+	previous, err := relayer.LoadManifest(relayerDir)
+	if err != nil {
+		return err
+	}
+	configs, err := relayer.Build(ctx, *template, networkID, nodes, relayer.Options{
+		Ports:    ports,
+		Previous: previous,
+		DA:       relayerDA,
+		Secrets:  secrets,
+		MockDA:   mockDA,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(relayerDir, 0o755); err != nil {
+		return err
+	}
+	manifest, err := relayer.Write(relayerDir, nodes, configs, relayerNewKeys)
+	if err != nil {
+		return err
+	}
+	for _, r := range manifest.Relayers {
+		fmt.Fprintf(e.out, "%s: %s (%s, serve RPC %d, message net %d)\n", r.Config, r.Node, r.Backend, r.ServeRPCPort, r.MessageNetPort)
+	}
+	fmt.Fprintln(e.out, filepath.Join(manifest.Dir, relayer.ManifestFile))
+	relayer.PrintKeys(e.out, manifest)
+	if len(formats) == 0 {
+		return nil
+	}
+	paths, err := launch.Write(manifest, formats, relayerLaunchOptions)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		fmt.Fprintln(e.out, p)
+	}
+	return nil
+}
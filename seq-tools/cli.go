@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/AnomalyFi/tools/common/config"
+	"github.com/AnomalyFi/tools/common/credentials"
+	"github.com/AnomalyFi/tools/common/network"
+)
+
+const name = "seq-tools"
+
+// errUsage makes run print the usage of the command instead of the error.
+var errUsage = errors.New("usage")
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	// details are printed below the summary in the command's usage
+	details string
+	// network commands take --network, --anr, --chain, --uris, --key and
+	// --allow-dev-key
+	network bool
+	flags   func(fs *flag.FlagSet)
+	run     func(ctx context.Context, e *env, args []string) error
+	// raw commands get their arguments unparsed
+	raw      bool
+	commands []*command
+}
+
+// globals are the flags every command takes.
+type globals struct {
+	config    string
+	profile   string
+	network   string
+	key       string
+	allowDev  bool
+	selection network.Selection
+}
+
+func (g *globals) addFlags(fs *flag.FlagSet, network bool) {
+	fs.StringVar(&g.config, "config", g.config, "seq-tools config file (env "+config.PathEnv+")")
+	fs.StringVar(&g.profile, "profile", g.profile, "profile of the config file to use instead of its default")
+	if network {
+		fs.StringVar(&g.network, "network", g.network, "named network of the config file or built in: local, devnet (default: the profile's)")
+		g.selection.AddFlags(fs)
+		credentials.FlagsVar(fs, &g.key, &g.allowDev)
+	}
+}
+
+// env is what a command runs with.
+type env struct {
+	globals *globals
+	// set are the global flags given on the command line
+	set     map[string]bool
+	config  *config.Config
+	profile *config.Profile
+	out     io.Writer
+}
+
+// networkName is --network, or the profile's network.
+func (e *env) networkName() string {
+	if e.set["network"] {
+		return e.globals.network
+	}
+	return e.profile.Network
+}
+
+// overridden reports whether --anr, --chain or --uris were given.
+func (e *env) overridden() bool {
+	return e.set["anr"] || e.set["chain"] || e.set["uris"]
+}
+
+// network is the named network, changed by the --anr, --chain and --uris
+// given. The IDs a named network expects are dropped once its nodes are
+// overridden.
+func (e *env) network() (*network.Profile, error) {
+	n := &network.Profile{}
+	if name := e.networkName(); len(name) > 0 {
+		var err error
+		if n, err = e.config.Network(name); err != nil {
+			return nil, err
+		}
+	}
+	s := e.globals.selection
+	if e.overridden() {
+		n.ChainID, n.NetworkID = "", 0
+	}
+	if e.set["anr"] {
+		n.ANR, n.Node = s.ANR, ""
+	}
+	if e.set["chain"] {
+		n.Chain = s.Chain
+	}
+	if e.set["uris"] {
+		n.URIs = s.URIs
+	} else if e.set["anr"] || e.set["chain"] {
+		// Asking for a cluster overrides the network's URIs
+		n.URIs = nil
+	}
+	if len(n.URIs) == 0 && len(n.Node) == 0 && len(n.ANR) == 0 {
+		n.ANR = s.ANR
+	}
+	return n, nil
+}
+
+// selected reports whether the user picked a network at all.
+func (e *env) selected() bool {
+	return e.overridden() || len(e.networkName()) > 0
+}
+
+func (e *env) target(ctx context.Context) (*network.Target, error) {
+	n, err := e.network()
+	if err != nil {
+		return nil, err
+	}
+	return n.Resolve(ctx)
+}
+
+// key loads the ed25519 key of --key or the profile and makes sure it may
+// sign for [uris].
+func (e *env) key(uris []string) (*credentials.Key, error) {
+	spec := e.globals.key
+	if len(spec) == 0 {
+		spec = e.profile.Key
+	}
+	key, err := credentials.Load(spec, credentials.ED25519)
+	if err != nil {
+		return nil, err
+	}
+	if err := key.CheckNetwork(uris, e.globals.allowDev); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func run(args []string) int {
+	g := &globals{config: config.DefaultPath()}
+	root := flag.NewFlagSet(name, flag.ContinueOnError)
+	g.addFlags(root, true)
+	root.Usage = func() { usage(root.Output(), []*command{rootCommand}, root) }
+	if err := root.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	set := map[string]bool{}
+	root.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	// Find the command
+	path := []*command{rootCommand}
+	args = root.Args()
+	for cmd := rootCommand; len(cmd.commands) > 0; cmd = path[len(path)-1] {
+		if len(args) == 0 {
+			usage(os.Stderr, path, nil)
+			return 2
+		}
+		if args[0] == "help" {
+			return help(path, args[1:])
+		}
+		sub := find(cmd, args[0])
+		if sub == nil {
+			fmt.Fprintf(os.Stderr, "%s: unknown command %q\n", strings.Join(names(path), " "), args[0])
+			usage(os.Stderr, path, nil)
+			return 2
+		}
+		path, args = append(path, sub), args[1:]
+	}
+	cmd := path[len(path)-1]
+
+	fs := flag.NewFlagSet(strings.Join(names(path), " "), flag.ContinueOnError)
+	if !cmd.raw {
+		g.addFlags(fs, cmd.network)
+		if cmd.flags != nil {
+			cmd.flags(fs)
+		}
+		fs.Usage = func() { usage(fs.Output(), path, fs) }
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			return 2
+		}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		args = fs.Args()
+	}
+
+	cfg, err := config.Load(g.config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	profile, err := cfg.Select(g.profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	e := &env{globals: g, set: set, config: cfg, profile: profile, out: os.Stdout}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := cmd.run(ctx, e, args); err != nil {
+		if errors.Is(err, errUsage) {
+			usage(os.Stderr, path, fs)
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Name(), err)
+		return 1
+	}
+	return 0
+}
+
+// help prints the usage of the command [args] names below [path].
+func help(path []*command, args []string) int {
+	for _, arg := range args {
+		sub := find(path[len(path)-1], arg)
+		if sub == nil {
+			fmt.Fprintf(os.Stderr, "%s: unknown command %q\n", strings.Join(names(path), " "), arg)
+			return 2
+		}
+		path = append(path, sub)
+	}
+	cmd := path[len(path)-1]
+	if cmd.raw {
+		// Raw commands print their own flags
+		return run(append(names(path)[1:], "-h"))
+	}
+	fs := flag.NewFlagSet(strings.Join(names(path), " "), flag.ContinueOnError)
+	if len(cmd.commands) == 0 {
+		(&globals{config: config.DefaultPath()}).addFlags(fs, cmd.network)
+		if cmd.flags != nil {
+			cmd.flags(fs)
+		}
+	}
+	usage(os.Stdout, path, fs)
+	return 0
+}
+
+func usage(w io.Writer, path []*command, fs *flag.FlagSet) {
+	cmd := path[len(path)-1]
+	full := strings.Join(names(path), " ")
+	if len(cmd.commands) > 0 {
+		fmt.Fprintf(w, "usage: %s <command> [flags] [args]\n\n%s\n", full, cmd.summary)
+		if len(cmd.details) > 0 {
+			fmt.Fprintf(w, "\n%s\n", cmd.details)
+		}
+		fmt.Fprintf(w, "\ncommands:\n")
+		for _, sub := range cmd.commands {
+			fmt.Fprintf(w, "  %-16s %s\n", sub.name, sub.summary)
+		}
+		fmt.Fprintf(w, "\nrun '%s help <command>' for the flags of a command\n", full)
+		if len(path) == 1 {
+			fmt.Fprintf(w, "\nevery command takes --config and --profile; commands that talk to a chain\nalso take --network, --anr, --chain, --uris, --key and --allow-dev-key, before\nor after the command\n")
+		}
+		return
+	}
+	fmt.Fprintf(w, "usage: %s\n\n%s\n", strings.TrimSpace(full+" [flags] "+cmd.args), cmd.summary)
+	if len(cmd.details) > 0 {
+		fmt.Fprintf(w, "\n%s\n", cmd.details)
+	}
+	if fs != nil {
+		fmt.Fprintf(w, "\nflags:\n")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+func find(cmd *command, name string) *command {
+	for _, sub := range cmd.commands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func names(path []*command) []string {
+	n := make([]string, len(path))
+	for i, cmd := range path {
+		n[i] = cmd.name
+	}
+	return n
+}
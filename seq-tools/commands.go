@@ -0,0 +1,19 @@
+package main
+
+var rootCommand = &command{
+	name:    name,
+	summary: "Tools to run, configure and load test SEQ networks.",
+	commands: []*command{
+		keysCommand,
+		addrCommand,
+		nodeIDPortCommand,
+		profilesCommand,
+		anrCommand,
+		pollCommand,
+		pollSummaryCommand,
+		pollSimulateCommand,
+		healthCommand,
+		contractCommand,
+		spamCommand,
+	},
+}
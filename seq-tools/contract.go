@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/tools/seq-wasm-tools/contract"
+)
+
+var contractCommand = &command{
+	name:    "contract",
+	summary: "Deploy and call wasm contracts.",
+	commands: []*command{
+		{
+			name:    "deploy",
+			args:    "<contract.wasm>",
+			summary: "Deploy a contract and call its initializer.",
+			network: true,
+			flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&contractFunction, "init", "initializer", "function called once the contract is deployed")
+				addContractFlags(fs)
+			},
+			run: runContractDeploy,
+		},
+		{
+			name:    "call",
+			args:    "<contract-address> <function>",
+			summary: "Call a function of a deployed contract.",
+			network: true,
+			flags: func(fs *flag.FlagSet) {
+				fs.BoolVar(&contractDeployTx, "deploy-tx", false, "the address is the ID of the tx that deployed the contract")
+				addContractFlags(fs)
+			},
+			run: runContractCall,
+		},
+		{
+			name:    "address",
+			args:    "<deploy-tx>",
+			summary: "Print the address of the contract deployed by a tx.",
+			run:     runContractAddress,
+		},
+	},
+}
+
+var (
+	contractFunction string
+	contractDeployTx bool
+	contractInput    string
+	contractSlots    string
+)
+
+func addContractFlags(fs *flag.FlagSet) {
+	fs.StringVar(&contractInput, "input", "", "hex encoded input of the function, e.g. ABI packed without the selector")
+	fs.StringVar(&contractSlots, "slots", "", "comma separated hex encoded state keys the function touches")
+}
+
+// contract returns a client for the selected chain and the decoded --input and
+// --slots.
+func (e *env) contract(ctx context.Context) (*contract.Client, []byte, [][]byte, error) {
+	input, err := decodeHex(contractInput)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("--input: %w", err)
+	}
+	var slots [][]byte
+	for _, s := range strings.Split(contractSlots, ",") {
+		if len(s) == 0 {
+			continue
+		}
+		slot, err := decodeHex(s)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("--slots: %w", err)
+		}
+		slots = append(slots, slot)
+	}
+	t, err := e.target(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := e.key(t.URIs[:1])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	priv, err := key.ED25519()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cli, err := contract.New(ctx, t.URIs[0], t.NetworkID, t.ChainID, priv)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cli, input, slots, nil
+}
+
+func runContractDeploy(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	code, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	cli, input, slots, err := e.contract(ctx)
+	if err != nil {
+		return err
+	}
+	txID, err := cli.Deploy(ctx, code, contractFunction, input, slots)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "%-10s %s\n", "tx:", txID)
+	fmt.Fprintf(e.out, "%-10s %s\n", "contract:", contract.Address(txID))
+	return nil
+}
+
+func runContractCall(ctx context.Context, e *env, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	address, err := ids.FromString(args[0])
+	if err != nil {
+		return err
+	}
+	if contractDeployTx {
+		address = contract.Address(address)
+	}
+	cli, input, slots, err := e.contract(ctx)
+	if err != nil {
+		return err
+	}
+	txID, err := cli.Call(ctx, address, args[1], input, slots)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "%-10s %s\n", "tx:", txID)
+	return nil
+}
+
+func runContractAddress(_ context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	txID, err := ids.FromString(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(e.out, contract.Address(txID))
+	return nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
@@ -0,0 +1,137 @@
+module github.com/AnomalyFi/tools/seq-tools
+
+go 1.22.8
+
+require (
+	github.com/AnomalyFi/hypersdk v0.9.7-arcadia.13
+	github.com/AnomalyFi/key2seqaddr v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/nodeid2port v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/nodekit-seq v0.9.17-sidecar.12
+	github.com/AnomalyFi/nodekit-tools/oracle-tools v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/nodekit-tools/poll-namespace v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/nodekit-tools/relayer-tools v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/tools/bls-keygen v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/tools/common v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/tools/seq-wasm-tools v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/tools/spam/fuzz v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/tools/spam/sequencer-msg v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/tools/spam/transfer v0.0.0-00010101000000-000000000000
+	github.com/ava-labs/avalanchego v1.11.10
+)
+
+require (
+	filippo.io/edwards25519 v1.0.0 // indirect
+	github.com/AnomalyFi/tools/spam/common v0.0.0-00010101000000-000000000000 // indirect
+	github.com/AnomalyFi/tools/state-keys v0.0.0-00010101000000-000000000000 // indirect
+	github.com/BurntSushi/toml v1.4.0 // indirect
+	github.com/DataDog/zstd v1.5.2 // indirect
+	github.com/Microsoft/go-winio v0.6.1 // indirect
+	github.com/NYTimes/gziphandler v1.1.1 // indirect
+	github.com/ava-labs/avalanche-network-runner v1.7.4-rc.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/bits-and-blooms/bitset v1.10.0 // indirect
+	github.com/btcsuite/btcd/btcec/v2 v2.3.2 // indirect
+	github.com/btcsuite/btcd/btcutil v1.1.3 // indirect
+	github.com/celestiaorg/nmt v0.20.0 // indirect
+	github.com/cenkalti/backoff/v4 v4.2.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/consensys/bavard v0.1.13 // indirect
+	github.com/consensys/gnark-crypto v0.12.2-0.20240504013751-564b6f724c3b // indirect
+	github.com/crate-crypto/go-kzg-4844 v0.7.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/deckarep/golang-set/v2 v2.1.0 // indirect
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.1.0 // indirect
+	github.com/ethereum/c-kzg-4844 v0.4.0 // indirect
+	github.com/ethereum/go-ethereum v1.13.14 // indirect
+	github.com/flashbots/go-boost-utils v1.8.1 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/go-ole/go-ole v1.3.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/protobuf v1.5.4 // indirect
+	github.com/google/btree v1.1.2 // indirect
+	github.com/google/renameio/v2 v2.0.0 // indirect
+	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/gorilla/rpc v1.2.0 // indirect
+	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.16.0 // indirect
+	github.com/hdevalence/ed25519consensus v0.2.0 // indirect
+	github.com/holiman/uint256 v1.2.4 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/jackc/pgx/v5 v5.5.5 // indirect
+	github.com/jackc/puddle/v2 v2.2.1 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
+	github.com/mmcloughlin/addchain v0.4.0 // indirect
+	github.com/mr-tron/base58 v1.2.0 // indirect
+	github.com/nbutton23/zxcvbn-go v0.0.0-20180912185939-ae427f1e4c1d // indirect
+	github.com/onsi/ginkgo/v2 v2.13.1 // indirect
+	github.com/openzipkin/zipkin-go v0.4.1 // indirect
+	github.com/pires/go-proxyproto v0.6.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/prometheus/client_golang v1.16.0 // indirect
+	github.com/prometheus/client_model v0.3.0 // indirect
+	github.com/prometheus/common v0.42.0 // indirect
+	github.com/prometheus/procfs v0.10.1 // indirect
+	github.com/rs/cors v1.7.0 // indirect
+	github.com/shirou/gopsutil v3.21.11+incompatible // indirect
+	github.com/stretchr/testify v1.8.4 // indirect
+	github.com/supranational/blst v0.3.11 // indirect
+	github.com/tklauser/go-sysconf v0.3.12 // indirect
+	github.com/tklauser/numcpus v0.6.1 // indirect
+	github.com/yusufpapurcu/wmi v1.2.2 // indirect
+	go.opentelemetry.io/otel v1.22.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.22.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.22.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.22.0 // indirect
+	go.opentelemetry.io/otel/exporters/zipkin v1.11.2 // indirect
+	go.opentelemetry.io/otel/metric v1.22.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.22.0 // indirect
+	go.opentelemetry.io/otel/trace v1.22.0 // indirect
+	go.opentelemetry.io/proto/otlp v1.0.0 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+	go.uber.org/mock v0.4.0 // indirect
+	go.uber.org/multierr v1.11.0 // indirect
+	go.uber.org/zap v1.26.0 // indirect
+	golang.org/x/crypto v0.22.0 // indirect
+	golang.org/x/exp v0.0.0-20240110193028-0dcbfd608b1e // indirect
+	golang.org/x/mod v0.14.0 // indirect
+	golang.org/x/net v0.24.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/sys v0.19.0 // indirect
+	golang.org/x/term v0.19.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/time v0.3.0 // indirect
+	golang.org/x/tools v0.17.0 // indirect
+	gonum.org/v1/gonum v0.11.0 // indirect
+	google.golang.org/genproto v0.0.0-20240123012728-ef4313101c80 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20240123012728-ef4313101c80 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240123012728-ef4313101c80 // indirect
+	google.golang.org/grpc v1.62.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gorm.io/driver/postgres v1.5.9 // indirect
+	gorm.io/driver/sqlite v1.5.6 // indirect
+	gorm.io/gorm v1.25.10 // indirect
+	rsc.io/tmplfunc v0.0.3 // indirect
+)
+
+replace (
+	github.com/AnomalyFi/key2seqaddr => ../key2seqaddr
+	github.com/AnomalyFi/nodeid2port => ../nodeid2port
+	github.com/AnomalyFi/nodekit-tools/oracle-tools => ../oracle-tools
+	github.com/AnomalyFi/nodekit-tools/poll-namespace => ../poll-namespace
+	github.com/AnomalyFi/nodekit-tools/relayer-tools => ../relayer-tools
+	github.com/AnomalyFi/tools/bls-keygen => ../bls-keygen
+	github.com/AnomalyFi/tools/common => ../common
+	github.com/AnomalyFi/tools/seq-wasm-tools => ../seq-wasm-tools
+	github.com/AnomalyFi/tools/spam/common => ../spam/common
+	github.com/AnomalyFi/tools/spam/fuzz => ../spam/fuzz
+	github.com/AnomalyFi/tools/spam/sequencer-msg => ../spam/sequencer-msg
+	github.com/AnomalyFi/tools/spam/transfer => ../spam/transfer
+	github.com/AnomalyFi/tools/state-keys => ../state-keys
+)
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/poll"
+	"github.com/AnomalyFi/tools/common/health"
+)
+
+var healthCommand = &command{
+	name:    "health",
+	summary: "Check that every node of the chain is up, caught up and agrees with the others.",
+	details: "Every node is asked for its network, last accepted block, unit prices and namespace prices.\n" +
+		"Nodes that don't answer, report other IDs than the network expects (or than most nodes),\n" +
+		"lag more than --max-lag blocks or report other prices than the nodes at the same height fail\n" +
+		"the check. Exits with 1 if it fails.",
+	network: true,
+	flags: func(fs *flag.FlagSet) {
+		fs.Func("namespaces", "comma separated namespaces whose price is compared (default "+health.DefaultNamespace+")", func(s string) error {
+			healthOptions.Namespaces = poll.SplitNamespaces(s)
+			return nil
+		})
+		fs.Uint64Var(&healthOptions.MaxLag, "max-lag", 2, "how many blocks a node may be behind the highest one")
+		fs.DurationVar(&healthOptions.MaxAge, "max-age", time.Minute, "how old the last accepted block may be before the chain counts as stalled, 0 to not check")
+		fs.DurationVar(&healthOptions.Timeout, "timeout", 5*time.Second, "how long every node has to answer")
+		fs.BoolVar(&healthJSON, "json", false, "print the report as JSON")
+	},
+	run: runHealth,
+}
+
+var (
+	healthOptions health.Options
+	healthJSON    bool
+)
+
+var errUnhealthy = errors.New("the chain is unhealthy")
+
+func runHealth(ctx context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	n, err := e.network()
+	if err != nil {
+		return err
+	}
+	// The nodes are only found here, the IDs they report are checked below
+	t, err := n.Discover(ctx)
+	if err != nil {
+		return err
+	}
+	healthOptions.NetworkID = n.NetworkID
+	if len(n.ChainID) > 0 {
+		if healthOptions.ChainID, err = ids.FromString(n.ChainID); err != nil {
+			return err
+		}
+	}
+
+	r := health.Check(ctx, t.URIs, healthOptions)
+	if healthJSON {
+		err = r.WriteJSON(e.out)
+	} else {
+		r.Print(e.out)
+	}
+	if err != nil {
+		return err
+	}
+	if !r.OK {
+		return errUnhealthy
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/crypto/ed25519"
+	"github.com/AnomalyFi/nodekit-seq/auth"
+	"github.com/AnomalyFi/nodekit-seq/consts"
+
+	"github.com/AnomalyFi/key2seqaddr/addr"
+	"github.com/AnomalyFi/tools/bls-keygen/keygen"
+	"github.com/AnomalyFi/tools/common/credentials"
+)
+
+var keysCommand = &command{
+	name:    "keys",
+	summary: "Generate keys.",
+	commands: []*command{
+		{
+			name:    "bls",
+			summary: "Generate a BLS keypair for a builder or relay.",
+			run:     runKeysBLS,
+		},
+		{
+			name:    "ed25519",
+			summary: "Generate an ed25519 key to sign SEQ txs with and print its address.",
+			flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&keyOut, "out", "", "write the hex encoded key to this file instead of printing it")
+				fs.BoolVar(&keyEncrypt, "encrypt", false, "write an encrypted keystore to --out instead, the password is $"+credentials.PasswordEnv+" or read from stdin")
+			},
+			run: runKeysED25519,
+		},
+		{
+			name:    "encrypt",
+			summary: "Encrypt the key --key points at into a keystore, to load as keystore:<file>.",
+			details: "The password is $" + credentials.PasswordEnv + " or read from stdin.",
+			flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&keyType, "type", string(credentials.ED25519), "type of the key: ed25519, bls or secp256k1")
+				fs.StringVar(&keySpec, "key", "", "key to encrypt: env:NAME, file:PATH or a path, or stdin")
+				fs.StringVar(&keyOut, "out", "", "file to write the keystore to")
+			},
+			run: runKeysEncrypt,
+		},
+	},
+}
+
+var (
+	keyOut     string
+	keyEncrypt bool
+	keyType    string
+	keySpec    string
+)
+
+func runKeysBLS(_ context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	kp, err := keygen.Generate()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "sk: %s\n", kp.SecretKey)
+	fmt.Fprintf(e.out, "pk: %s\n", kp.PublicKey)
+	return nil
+}
+
+func runKeysED25519(_ context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	key, err := ed25519.GeneratePrivateKey()
+	if err != nil {
+		return err
+	}
+	address, err := codec.AddressBech32(consts.HRP, auth.NewED25519Address(key.PublicKey()))
+	if err != nil {
+		return err
+	}
+	switch {
+	case keyEncrypt:
+		if len(keyOut) == 0 {
+			return errors.New("--encrypt needs --out")
+		}
+		if err := writeKeystore(credentials.New(credentials.ED25519, key[:], "generated"), keyOut); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.out, "%-15s keystore:%s\n", "key:", keyOut)
+	case len(keyOut) > 0:
+		if err := os.WriteFile(keyOut, []byte(codec.ToHex(key[:])), 0o600); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.out, "%-15s %s\n", "key:", keyOut)
+	default:
+		fmt.Fprintf(e.out, "%-15s %s\n", "key:", codec.ToHex(key[:]))
+	}
+	fmt.Fprintf(e.out, "%-15s %s\n", "SEQ addr:", address)
+	return nil
+}
+
+func runKeysEncrypt(_ context.Context, e *env, args []string) error {
+	if len(args) != 0 || len(keyOut) == 0 {
+		return errUsage
+	}
+	typ := credentials.Type(keyType)
+	if typ != credentials.ED25519 && typ != credentials.BLS && typ != credentials.Secp256k1 {
+		return fmt.Errorf("unknown key type %q", keyType)
+	}
+	if len(keySpec) == 0 || keySpec == credentials.Dev {
+		return errors.New("--key must point at the key to encrypt")
+	}
+	key, err := credentials.Load(keySpec, typ)
+	if err != nil {
+		return err
+	}
+	if err := writeKeystore(key, keyOut); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "%s: keystore:%s\n", key, keyOut)
+	return nil
+}
+
+func writeKeystore(key *credentials.Key, path string) error {
+	password, err := credentials.Password("new keystore password: ")
+	if err != nil {
+		return err
+	}
+	if len(password) == 0 {
+		return errors.New("empty keystore password")
+	}
+	b, err := credentials.Encrypt(key, password)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o600)
+}
+
+var addrCommand = &command{
+	name:    "addr",
+	args:    "[bls-secret-key-hex]",
+	summary: "Print the public key and SEQ address of a BLS secret key.",
+	details: "Pass the key with --bls-key rather than as an argument to keep it out of the shell history.",
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&addrHRP, "hrp", addr.HRP, "human readable part of the address")
+		fs.StringVar(&addrKey, "bls-key", "", "BLS key: env:NAME, file:PATH or a path, keystore:PATH or stdin")
+	},
+	run: runAddr,
+}
+
+var (
+	addrHRP string
+	addrKey string
+)
+
+func runAddr(_ context.Context, e *env, args []string) error {
+	var keyHex string
+	switch {
+	case len(args) == 1 && len(addrKey) == 0:
+		keyHex = args[0]
+	case len(args) == 0 && len(addrKey) > 0:
+		key, err := credentials.Load(addrKey, credentials.BLS)
+		if err != nil {
+			return err
+		}
+		keyHex = key.Hex()
+	default:
+		return errUsage
+	}
+	info, err := addr.FromBLSKey(keyHex, addrHRP)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "%-15s %#x\n", "BLS secretkey:", info.SecretKey)
+	fmt.Fprintf(e.out, "%-15s %#x\n", "BLS pubkey:", info.PublicKey)
+	fmt.Fprintf(e.out, "%-15s %s\n", "SEQ addr:", info.Address)
+	return nil
+}
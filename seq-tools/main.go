@@ -0,0 +1,12 @@
+// seq-tools bundles the tools of this repository behind one binary with
+// shared network selection and configuration. The packages behind every
+// subcommand stay importable on their own.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AnomalyFi/nodeid2port/port"
+)
+
+var nodeIDPortCommand = &command{
+	name:    "nodeid-port",
+	args:    "<node-id>...",
+	summary: "Print the port the message net of a node listens on.",
+	run:     runNodeIDPort,
+}
+
+func runNodeIDPort(_ context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	for _, nodeID := range args {
+		p, err := port.FromNodeID(nodeID)
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			fmt.Fprintln(e.out, p)
+		} else {
+			fmt.Fprintln(e.out, nodeID, p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/poll"
+	"github.com/AnomalyFi/nodekit-tools/poll-namespace/sim"
+)
+
+var pollCommand = &command{
+	name:    "poll",
+	summary: "Print the price of namespaces as every node of the chain reports it, and when they disagree.",
+	network: true,
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&pollNamespaces, "namespaces", poll.DefaultNamespace, "comma separated namespaces whose price is polled")
+		fs.DurationVar(&pollOptions.Interval, "interval", 500*time.Millisecond, "how often every node is asked")
+		fs.DurationVar(&pollOptions.Timeout, "timeout", 0, "how long the nodes have to answer in a round (default: the interval)")
+		fs.StringVar(&pollOptions.Record, "record", "", "append every reading to this CSV file, or SQLite database if it ends in .db, .sqlite or .sqlite3")
+		fs.BoolVar(&pollOptions.Changes, "changes", false, "only print prices that move instead of every round")
+		fs.BoolVar(&pollWatch, "watch", false, "instead of polling every interval, ask for the prices whenever an accepted block posts to the namespaces")
+		fs.StringVar(&pollOptions.Traffic, "traffic", "", "with --watch, append the SequencerMsg volume of every block that posts to the namespaces to this CSV file")
+	},
+	run: runPoll,
+}
+
+var pollSummaryCommand = &command{
+	name:    "poll-summary",
+	args:    "<recording>",
+	summary: "Sum up the namespace prices a poll recorded with --record.",
+	details: "Prints each namespace's min, max and mean price and how often the price most nodes agreed on\n" +
+		"changed, and every node's error rate.",
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&pollSince, "since", "", "start of the window: RFC 3339 time, or duration before now (default: the first round)")
+		fs.StringVar(&pollUntil, "until", "", "end of the window: RFC 3339 time, or duration before now (default: the last round)")
+	},
+	run: runPollSummary,
+}
+
+var pollSimulateCommand = &command{
+	name:    "poll-simulate",
+	args:    "<traffic>",
+	summary: "Predict namespace prices from SequencerMsg traffic recorded with poll --watch --traffic.",
+	details: "Prints the predicted price curve of every namespace. With --validate the predictions are compared\n" +
+		"with the prices of a recording, e.g. of poll --watch --record during a spam run.",
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&simModel, "model", "", "JSON file of the pricing model, or mock-seq for mock-seq's (default: hypersdk's fee window)")
+		fs.StringVar(&simOut, "out", "", "write the predicted price after every block to this CSV file")
+		fs.StringVar(&simValidate, "validate", "", "compare the predictions with the prices in this recording")
+		fs.IntVar(&simWidth, "width", 60, "width of the printed price curves")
+	},
+	run: runPollSimulate,
+}
+
+var (
+	simModel    string
+	simOut      string
+	simValidate string
+	simWidth    int
+)
+
+var (
+	pollNamespaces string
+	pollOptions    poll.Options
+	pollWatch      bool
+	pollSince      string
+	pollUntil      string
+)
+
+func runPoll(ctx context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	t, err := e.target(ctx)
+	if err != nil {
+		return err
+	}
+	pollOptions.Namespaces = poll.SplitNamespaces(pollNamespaces)
+	if pollWatch {
+		return poll.Watch(ctx, t.URIs, pollOptions, e.out)
+	}
+	return poll.Poll(ctx, t.URIs, pollOptions, e.out)
+}
+
+func runPollSummary(_ context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	now := time.Now()
+	var since, until time.Time
+	var err error
+	if len(pollSince) > 0 {
+		if since, err = poll.ParseTime(pollSince, now); err != nil {
+			return err
+		}
+	}
+	if len(pollUntil) > 0 {
+		if until, err = poll.ParseTime(pollUntil, now); err != nil {
+			return err
+		}
+	}
+	rounds, err := poll.Load(args[0])
+	if err != nil {
+		return err
+	}
+	poll.Summarize(rounds, since, until).Print(e.out)
+	return nil
+}
+
+func runPollSimulate(_ context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	model := &sim.DefaultModel
+	if len(simModel) > 0 {
+		var err error
+		if model, err = sim.LoadModel(simModel); err != nil {
+			return err
+		}
+	}
+	blocks, err := poll.LoadTraffic(args[0])
+	if err != nil {
+		return err
+	}
+	points := sim.Simulate(model, blocks)
+	sim.PrintCurves(e.out, points, simWidth)
+	if len(simOut) > 0 {
+		f, err := os.Create(simOut)
+		if err != nil {
+			return err
+		}
+		if err := sim.WriteCSV(f, points); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	if len(simValidate) > 0 {
+		rounds, err := poll.Load(simValidate)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(e.out)
+		sim.PrintFits(e.out, sim.Validate(points, rounds))
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AnomalyFi/tools/common/network"
+)
+
+var profilesCommand = &command{
+	name:    "profiles",
+	summary: "List and verify the named networks --network picks from.",
+	details: "Networks are the built-in local and devnet, and those in the \"networks\" of the config file.",
+	commands: []*command{
+		{
+			name:    "list",
+			summary: "Print every network and how its nodes are found.",
+			run:     runProfilesList,
+		},
+		{
+			name:    "verify",
+			args:    "[network]...",
+			summary: "Check that every node of the networks answers, on the expected network and chain.",
+			details: "Without arguments the network picked by the flags or the profile is verified, or every network\n" +
+				"if none is picked. Exits with 1 if any node fails.",
+			network: true,
+			flags: func(fs *flag.FlagSet) {
+				fs.DurationVar(&verifyTimeout, "timeout", 5*time.Second, "how long every node has to answer")
+			},
+			run: runProfilesVerify,
+		},
+	},
+}
+
+var verifyTimeout time.Duration
+
+var errVerify = errors.New("some nodes failed verification")
+
+func runProfilesList(_ context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	networks := e.config.AllNetworks()
+	w := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NETWORK\tDISCOVERY\tNODES\tCHAIN\tNETWORK ID\tCHAIN ID\tHRP")
+	for _, name := range network.Names(networks) {
+		n := networks[name]
+		var nodes string
+		switch n.Discovery() {
+		case "uris":
+			nodes = n.URIs[0]
+			if len(n.URIs) > 1 {
+				nodes += fmt.Sprintf(" (+%d)", len(n.URIs)-1)
+			}
+		case "node":
+			nodes = n.Node
+		case "anr":
+			nodes = n.ANR
+		}
+		networkID := "-"
+		if n.NetworkID != 0 {
+			networkID = fmt.Sprint(n.NetworkID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", name, n.Discovery(), nodes, orDash(n.Chain), networkID, orDash(n.ChainID), orDash(n.HRP))
+	}
+	return w.Flush()
+}
+
+func runProfilesVerify(ctx context.Context, e *env, args []string) error {
+	type named struct {
+		name string
+		n    *network.Profile
+	}
+	var networks []named
+	switch {
+	case len(args) > 0:
+		for _, name := range args {
+			n, err := e.config.Network(name)
+			if err != nil {
+				return err
+			}
+			networks = append(networks, named{name, n})
+		}
+	case e.selected():
+		n, err := e.network()
+		if err != nil {
+			return err
+		}
+		name := e.networkName()
+		if len(name) == 0 || e.overridden() {
+			name = "(flags)"
+		}
+		networks = append(networks, named{name, n})
+	default:
+		all := e.config.AllNetworks()
+		for _, name := range network.Names(all) {
+			networks = append(networks, named{name, all[name]})
+		}
+	}
+
+	failed := false
+	w := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NETWORK\tURI\tNETWORK ID\tCHAIN ID\tLATENCY\tRESULT")
+	for _, nn := range networks {
+		checks, err := nn.n.Verify(ctx, verifyTimeout)
+		if err != nil {
+			failed = true
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\tFAIL %v\n", nn.name, err)
+			continue
+		}
+		for _, c := range checks {
+			networkID, chainID, result := "-", "-", "OK"
+			if c.NetworkID != 0 {
+				networkID, chainID = fmt.Sprint(c.NetworkID), c.ChainID.String()
+			}
+			if c.Err != nil {
+				failed = true
+				result = "FAIL " + c.Err.Error()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", nn.name, c.URI, networkID, chainID, c.Latency.Round(time.Millisecond), result)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed {
+		return errVerify
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/AnomalyFi/tools/common/config"
+	fuzz "github.com/AnomalyFi/tools/spam/fuzz/spammer"
+	seqmsg "github.com/AnomalyFi/tools/spam/sequencer-msg/spammer"
+	transfer "github.com/AnomalyFi/tools/spam/transfer/spammer"
+)
+
+var spamCommand = &command{
+	name:    "spam",
+	summary: "Load test the chain.",
+	details: "The flags after the spammer's name are the spammer's own, see 'seq-tools spam help <spammer>'.\n" +
+		"Unless they include --network or --uris, the spammer uses the network of the profile or of\n" +
+		"--network, --anr, --chain and --uris given before 'spam', and otherwise the devnet. The\n" +
+		"same goes for --key and --allow-dev-key.",
+	commands: []*command{
+		spammer("transfer", "Spam transfers between accounts.", transfer.Main),
+		spammer("sequencer-msg", "Spam sequencer messages.", seqmsg.Main),
+		spammer("fuzz", "Spam random mixes of transfers and sequencer messages, and ill-formed txs.", fuzz.Main),
+	},
+}
+
+func spammer(name string, summary string, main func([]string)) *command {
+	return &command{
+		name:    name,
+		args:    "[spammer flags]",
+		summary: summary,
+		raw:     true,
+		run: func(ctx context.Context, e *env, args []string) error {
+			switch {
+			case hasFlag(args, "uris") || hasFlag(args, "network"):
+				// The spammer's own flags win
+			case len(e.networkName()) > 0 && !e.overridden():
+				// The spammer resolves the network itself, from the same
+				// config, so it also gets its HRP
+				if err := os.Setenv(config.PathEnv, e.globals.config); err != nil {
+					return err
+				}
+				args = append([]string{"--network=" + e.networkName()}, args...)
+			case e.selected():
+				t, err := e.target(ctx)
+				if err != nil {
+					return err
+				}
+				args = append([]string{"--uris=" + strings.Join(t.URIs, ",")}, args...)
+			}
+			key := e.globals.key
+			if len(key) == 0 {
+				key = e.profile.Key
+			}
+			if len(key) > 0 && !hasFlag(args, "key") {
+				args = append([]string{"--key=" + key}, args...)
+			}
+			if e.globals.allowDev && !hasFlag(args, "allow-dev-key") {
+				args = append([]string{"--allow-dev-key"}, args...)
+			}
+			main(args)
+			return nil
+		},
+	}
+}
+
+// hasFlag reports whether [args] set --[name].
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		arg = strings.TrimLeft(arg, "-")
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// Package blobstream has the Go bindings of the inputs of the blobstream
+// contract's functions.
+package blobstream
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// BinaryMerkleProof is an auto generated low-level Go binding around an user-defined struct.
+type BinaryMerkleProof struct {
+	SideNodes [][32]byte
+	Key       *big.Int
+	NumLeaves *big.Int
+}
+
+// CommitHeaderRangeInput is an auto generated low-level Go binding around an user-defined struct.
+type CommitHeaderRangeInput struct {
+	Proof        []byte
+	PublicValues []byte
+}
+
+// DataRootTuple is an auto generated low-level Go binding around an user-defined struct.
+type DataRootTuple struct {
+	Height   *big.Int
+	DataRoot [32]byte
+}
+
+// InitializerInput is an auto generated low-level Go binding around an user-defined struct.
+type InitializerInput struct {
+	Height                    uint64
+	Header                    [32]byte
+	BlobstreamProgramVKeyHash []byte
+	BlobstreamProgramVKey     []byte
+}
+
+// UpdateFreezeInput is an auto generated low-level Go binding around an user-defined struct.
+type UpdateFreezeInput struct {
+	Freeze bool
+}
+
+// UpdateGenesisStateInput is an auto generated low-level Go binding around an user-defined struct.
+type UpdateGenesisStateInput struct {
+	Height uint64
+	Header [32]byte
+}
+
+// UpdateProgramVkeyInput is an auto generated low-level Go binding around an user-defined struct.
+type UpdateProgramVkeyInput struct {
+	BlobstreamProgramVKeyHash []byte
+	BlobstreamProgramVKey     []byte
+}
+
+// VAInput is an auto generated low-level Go binding around an user-defined struct.
+type VAInput struct {
+	TupleRootNonce *big.Int
+	Tuple          DataRootTuple
+	Proof          BinaryMerkleProof
+}
+
+// BlobStreamInputsMetaData contains all meta data concerning the BlobStreamInputs contract.
+var BlobStreamInputsMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"components\":[{\"internalType\":\"bytes\",\"name\":\"proof\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"publicValues\",\"type\":\"bytes\"}],\"internalType\":\"structCommitHeaderRangeInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"commitHeaderRange\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"height\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"header\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKeyHash\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKey\",\"type\":\"bytes\"}],\"internalType\":\"structInitializerInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"initializer\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"bool\",\"name\":\"freeze\",\"type\":\"bool\"}],\"internalType\":\"structUpdateFreezeInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"updateFreeze\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"height\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"header\",\"type\":\"bytes32\"}],\"internalType\":\"structUpdateGenesisStateInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"updateGenesisState\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKeyHash\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKey\",\"type\":\"bytes\"}],\"internalType\":\"structUpdateProgramVkeyInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"updateProgramVkey\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint256\",\"name\":\"tuple_root_nonce\",\"type\":\"uint256\"},{\"components\":[{\"internalType\":\"uint256\",\"name\":\"height\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"dataRoot\",\"type\":\"bytes32\"}],\"internalType\":\"structDataRootTuple\",\"name\":\"tuple\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes32[]\",\"name\":\"sideNodes\",\"type\":\"bytes32[]\"},{\"internalType\":\"uint256\",\"name\":\"key\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"numLeaves\",\"type\":\"uint256\"}],\"internalType\":\"structBinaryMerkleProof\",\"name\":\"proof\",\"type\":\"tuple\"}],\"internalType\":\"structVAInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"verifyAppend\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+var BlobStreamInputsABI, _ = BlobStreamInputsMetaData.GetAbi()
+
+// Pack encodes [input] as the argument of [function], without the selector
+// the contract functions don't expect.
+func Pack(function string, input any) ([]byte, error) {
+	packed, err := BlobStreamInputsABI.Pack(function, input)
+	if err != nil {
+		return nil, err
+	}
+	return packed[4:], nil
+}
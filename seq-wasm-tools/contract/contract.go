@@ -0,0 +1,70 @@
+// Package contract submits the SEQ actions that deploy wasm contracts and
+// call their functions.
+package contract
+
+import (
+	"context"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/crypto/ed25519"
+	"github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	"github.com/AnomalyFi/nodekit-seq/auth"
+	trpc "github.com/AnomalyFi/nodekit-seq/rpc"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+type Client struct {
+	cli     *rpc.JSONRPCClient
+	parser  chain.Parser
+	factory chain.AuthFactory
+}
+
+// New returns a client that signs with [key] and submits to the chain at
+// [uri].
+func New(ctx context.Context, uri string, networkID uint32, chainID ids.ID, key ed25519.PrivateKey) (*Client, error) {
+	parser, err := trpc.NewJSONRPCClient(uri, networkID, chainID).Parser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		cli:     rpc.NewJSONRPCClient(uri),
+		parser:  parser,
+		factory: auth.NewED25519Factory(key),
+	}, nil
+}
+
+// Address is the address of the contract deployed by [deployTx].
+func Address(deployTx ids.ID) ids.ID {
+	return chain.CreateActionID(deployTx, 0)
+}
+
+// Deploy submits [code] and has [initializer] called with [input], which
+// may touch the state keys in [slots]. It returns the ID of the tx, which
+// [Address] turns into the contract address.
+func (c *Client) Deploy(ctx context.Context, code []byte, initializer string, input []byte, slots [][]byte) (ids.ID, error) {
+	return c.submit(ctx, &actions.Deploy{
+		ContractCode:            code,
+		InitializerFunctionName: initializer,
+		Input:                   input,
+		DynamicStateSlots:       slots,
+	})
+}
+
+// Call submits a call of [function] of the contract at [address].
+func (c *Client) Call(ctx context.Context, address ids.ID, function string, input []byte, slots [][]byte) (ids.ID, error) {
+	return c.submit(ctx, &actions.Transact{
+		ContractAddress:   address,
+		FunctionName:      function,
+		Input:             input,
+		DynamicStateSlots: slots,
+	})
+}
+
+func (c *Client) submit(ctx context.Context, action chain.Action) (ids.ID, error) {
+	_, tx, _, err := c.cli.GenerateTransaction(ctx, c.parser, []chain.Action{action}, c.factory)
+	if err != nil {
+		return ids.Empty, err
+	}
+	return c.cli.SubmitTx(ctx, tx.Bytes())
+}
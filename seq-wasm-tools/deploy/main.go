@@ -2,124 +2,69 @@ package main
 
 import (
 	"context"
-	"errors"
+	"flag"
 	"fmt"
-	"math/big"
 	"os"
-	"strings"
 
-	"github.com/AnomalyFi/hypersdk/chain"
-	"github.com/AnomalyFi/hypersdk/codec"
-	"github.com/AnomalyFi/hypersdk/crypto/ed25519"
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/event"
-
-	"github.com/AnomalyFi/hypersdk/rpc"
-	"github.com/AnomalyFi/nodekit-seq/actions"
-	"github.com/AnomalyFi/nodekit-seq/auth"
-	trpc "github.com/AnomalyFi/nodekit-seq/rpc"
 	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/common"
 
-	"github.com/AnomalyFi/tools/state-keys/blobstream"
+	"github.com/AnomalyFi/tools/common/config"
+	"github.com/AnomalyFi/tools/common/credentials"
+	"github.com/AnomalyFi/tools/seq-wasm-tools/blobstream"
+	"github.com/AnomalyFi/tools/seq-wasm-tools/contract"
+	stateKeys "github.com/AnomalyFi/tools/state-keys/blobstream"
 )
 
-// Reference imports to suppress errors if they are not otherwise used.
 var (
-	_ = errors.New
-	_ = big.NewInt
-	_ = strings.NewReader
-	_ = ethereum.NotFound
-	_ = bind.Bind
-	_ = common.Big1
-	_ = types.BloomLookup
-	_ = event.NewSubscription
-	_ = abi.ConvertType
+	uriFlag       = flag.String("uri", "http://127.0.0.1:9658/ext/bc/tEpDFmDWyU4C7FCUYLg7YNudkJsRo6AQyvksmLAaAJ14yM1cs", "chain URI, e.g. one printed by mock-seq")
+	networkIDFlag = flag.Uint("network-id", 1337, "network ID")
+	chainIDFlag   = flag.String("chain-id", "tEpDFmDWyU4C7FCUYLg7YNudkJsRo6AQyvksmLAaAJ14yM1cs", "chain ID")
+	contractPath  = flag.String("contract", "/home/ubuntu/seq-wasm/target/wasm32-unknown-unknown/release/blobstream_contracts_rust.wasm", "contract wasm to deploy")
+	vkPath        = flag.String("vk", "/home/ubuntu/tools/seq-wasm-tools/vk.bin", "blobstream program verifying key")
+
+	networkName          = config.NetworkFlag(flag.CommandLine)
+	keySpec, allowDevKey = credentials.Flags(flag.CommandLine)
 )
 
-// BinaryMerkleProof is an auto generated low-level Go binding around an user-defined struct.
-type BinaryMerkleProof struct {
-	SideNodes [][32]byte
-	Key       *big.Int
-	NumLeaves *big.Int
-}
-
-// CommitHeaderRangeInput is an auto generated low-level Go binding around an user-defined struct.
-type CommitHeaderRangeInput struct {
-	Proof        []byte
-	PublicValues []byte
-}
-
-// DataRootTuple is an auto generated low-level Go binding around an user-defined struct.
-type DataRootTuple struct {
-	Height   *big.Int
-	DataRoot [32]byte
-}
-
-// InitializerInput is an auto generated low-level Go binding around an user-defined struct.
-type InitializerInput struct {
-	Height                    uint64
-	Header                    [32]byte
-	BlobstreamProgramVKeyHash []byte
-	BlobstreamProgramVKey     []byte
-}
-
-// UpdateFreezeInput is an auto generated low-level Go binding around an user-defined struct.
-type UpdateFreezeInput struct {
-	Freeze bool
-}
-
-// UpdateGenesisStateInput is an auto generated low-level Go binding around an user-defined struct.
-type UpdateGenesisStateInput struct {
-	Height uint64
-	Header [32]byte
-}
-
-// UpdateProgramVkeyInput is an auto generated low-level Go binding around an user-defined struct.
-type UpdateProgramVkeyInput struct {
-	BlobstreamProgramVKeyHash []byte
-	BlobstreamProgramVKey     []byte
-}
-
-// VAInput is an auto generated low-level Go binding around an user-defined struct.
-type VAInput struct {
-	TupleRootNonce *big.Int
-	Tuple          DataRootTuple
-	Proof          BinaryMerkleProof
-}
-
-// BlobStreamInputsMetaData contains all meta data concerning the BlobStreamInputs contract.
-var BlobStreamInputsMetaData = &bind.MetaData{
-	ABI: "[{\"inputs\":[{\"components\":[{\"internalType\":\"bytes\",\"name\":\"proof\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"publicValues\",\"type\":\"bytes\"}],\"internalType\":\"structCommitHeaderRangeInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"commitHeaderRange\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"height\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"header\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKeyHash\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKey\",\"type\":\"bytes\"}],\"internalType\":\"structInitializerInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"initializer\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"bool\",\"name\":\"freeze\",\"type\":\"bool\"}],\"internalType\":\"structUpdateFreezeInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"updateFreeze\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"height\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"header\",\"type\":\"bytes32\"}],\"internalType\":\"structUpdateGenesisStateInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"updateGenesisState\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKeyHash\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"blobstreamProgramVKey\",\"type\":\"bytes\"}],\"internalType\":\"structUpdateProgramVkeyInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"updateProgramVkey\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"uint256\",\"name\":\"tuple_root_nonce\",\"type\":\"uint256\"},{\"components\":[{\"internalType\":\"uint256\",\"name\":\"height\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"dataRoot\",\"type\":\"bytes32\"}],\"internalType\":\"structDataRootTuple\",\"name\":\"tuple\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes32[]\",\"name\":\"sideNodes\",\"type\":\"bytes32[]\"},{\"internalType\":\"uint256\",\"name\":\"key\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"numLeaves\",\"type\":\"uint256\"}],\"internalType\":\"structBinaryMerkleProof\",\"name\":\"proof\",\"type\":\"tuple\"}],\"internalType\":\"structVAInput\",\"name\":\"inputs\",\"type\":\"tuple\"}],\"name\":\"verifyAppend\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
-}
-
-var BlobStreamInputsABI, _ = BlobStreamInputsMetaData.GetAbi()
-
 func main() {
+	flag.Parse()
 	ctx := context.Background()
-	privBytes, _ := codec.LoadHex(
-		"323b1d8f4eed5f0da9da93071b034f2dce9d2d22692c172f3cb252a64ddfafd01b057de320297c29ad0c1f589ea216869cf1938d88c9fbd70d6748323dbf2fa7", //nolint:lll
-		ed25519.PrivateKeyLen,
-	)
-	uri := "http://127.0.0.1:9658/ext/bc/tEpDFmDWyU4C7FCUYLg7YNudkJsRo6AQyvksmLAaAJ14yM1cs"
-	var networkID uint32 = 1337
-	chainID, _ := ids.FromString("tEpDFmDWyU4C7FCUYLg7YNudkJsRo6AQyvksmLAaAJ14yM1cs")
-	cli := rpc.NewJSONRPCClient(uri)
-	tcli := trpc.NewJSONRPCClient(uri, networkID, chainID)
-	parser, err := tcli.Parser(ctx)
+	uri := *uriFlag
+	networkID := uint32(*networkIDFlag)
+	chainID, err := ids.FromString(*chainIDFlag)
+	if err != nil {
+		panic(err)
+	}
+	// A named network replaces --uri, --network-id and --chain-id
+	if len(*networkName) > 0 {
+		target, err := config.ResolveNetwork(ctx, *networkName)
+		if err != nil {
+			panic(err)
+		}
+		uri, networkID, chainID = target.URIs[0], target.NetworkID, target.ChainID
+	}
+	key, err := credentials.Load(*keySpec, credentials.ED25519)
+	if err != nil {
+		panic(err)
+	}
+	if err := key.CheckNetwork([]string{uri}, *allowDevKey); err != nil {
+		panic(err)
+	}
+	priv, err := key.ED25519()
+	if err != nil {
+		panic(err)
+	}
+	cli, err := contract.New(ctx, uri, networkID, chainID, priv)
 	if err != nil {
 		panic(err)
 	}
-	factory := auth.NewED25519Factory(ed25519.PrivateKey(privBytes))
 
-	contractCode, err := os.ReadFile("/home/ubuntu/seq-wasm/target/wasm32-unknown-unknown/release/blobstream_contracts_rust.wasm")
+	contractCode, err := os.ReadFile(*contractPath)
 	if err != nil {
 		panic(err)
 	}
-	vkey, err := os.ReadFile("/home/ubuntu/tools/seq-wasm-tools/vk.bin")
+	vkey, err := os.ReadFile(*vkPath)
 	if err != nil {
 		panic(err)
 	}
@@ -131,7 +76,7 @@ func main() {
 	// target_block: 2202310
 	// validator_bit_map: 1267650595505862918627057991679
 	height := uint64(2202300)
-	inputPacked, err := abi.ABI.Pack(*BlobStreamInputsABI, "initializer", InitializerInput{
+	input, err := blobstream.Pack("initializer", blobstream.InitializerInput{
 		Height:                    height,
 		Header:                    [32]byte(common.Hex2BytesFixed("188b708bee180f43e3a252471754fd35283a6b09a6fd02f5b9130cc15604f80b", 32)),
 		BlobstreamProgramVKeyHash: []byte("414456900754233403821469318749333346230962952863679230760144647782402486705"),
@@ -140,19 +85,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	DynamicStateSlots := blobstream.StateKeysInitializer(height)
-	action := []chain.Action{&actions.Deploy{
-		ContractCode:            contractCode,
-		InitializerFunctionName: "initializer",
-		Input:                   inputPacked[4:],
-		DynamicStateSlots:       DynamicStateSlots,
-	}}
-
-	_, tx, _, err := cli.GenerateTransaction(ctx, parser, action, factory)
-	if err != nil {
-		panic(err)
-	}
-	txID, err := cli.SubmitTx(ctx, tx.Bytes())
+	txID, err := cli.Deploy(ctx, contractCode, "initializer", input, stateKeys.StateKeysInitializer(height))
 	if err != nil {
 		panic(err)
 	}
@@ -3,4 +3,14 @@ module github.com/AnomalyFi/tools/seq-wasm-tools
 go 1.22.2
 
 replace github.com/AnomalyFi/nodekit-seq => ../../nodekit-seq
-replace github.com/AnomalyFi/hypersdk => ../../hypersdk
\ No newline at end of file
+replace github.com/AnomalyFi/hypersdk => ../../hypersdk
+
+require (
+	github.com/AnomalyFi/tools/common v0.0.0-00010101000000-000000000000
+	github.com/AnomalyFi/tools/state-keys v0.0.0-00010101000000-000000000000
+)
+
+replace (
+	github.com/AnomalyFi/tools/common => ../common
+	github.com/AnomalyFi/tools/state-keys => ../state-keys
+)
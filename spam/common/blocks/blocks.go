@@ -0,0 +1,350 @@
+// Package blocks subscribes to accepted blocks while a spammer is running and
+// reports how full each block was, how many txs it included and how long the
+// spammer's own txs took to land in it.
+package blocks
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/pubsub"
+	"github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/AnomalyFi/hypersdk/utils"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var csvHeader = []string{
+	"time",
+	"height",
+	"block_timestamp",
+	"txs",
+	"issued_txs",
+	"size",
+	"bandwidth",
+	"compute",
+	"storage_read",
+	"storage_allocate",
+	"storage_write",
+	"fullness_pct",
+	"issue_to_block_mean_ms",
+	"issue_to_block_max_ms",
+}
+
+// Block is what we learned about a single accepted block.
+type Block struct {
+	Height    uint64
+	Timestamp int64 // block timestamp in milliseconds
+	Received  time.Time
+
+	Txs      int
+	Issued   int // txs in this block that were issued by us
+	Size     int
+	Units    fees.Dimensions
+	MaxUnits fees.Dimensions
+
+	IssueToBlockMean time.Duration
+	IssueToBlockMax  time.Duration
+}
+
+// Fullness returns the share of the block's unit limit consumed in the most
+// used dimension, in percent.
+func (b *Block) Fullness() float64 {
+	return fullness(b.Units, b.MaxUnits)
+}
+
+// Interval accumulates block stats over a period of time.
+type Interval struct {
+	Elapsed time.Duration
+
+	Blocks   int
+	Txs      int
+	Issued   int
+	Size     int
+	Units    fees.Dimensions
+	Fullness float64 // highest fullness of any block in the interval
+
+	issueToBlock      time.Duration
+	IssueToBlockCount int
+	IssueToBlockMax   time.Duration
+}
+
+// TPS is the number of txs included per second over the interval.
+func (i *Interval) TPS() float64 {
+	if i.Elapsed <= 0 {
+		return 0
+	}
+	return float64(i.Txs) / i.Elapsed.Seconds()
+}
+
+// IssueToBlockMean is the mean time between issuing one of our txs and
+// seeing it in an accepted block.
+func (i *Interval) IssueToBlockMean() time.Duration {
+	if i.IssueToBlockCount == 0 {
+		return 0
+	}
+	return i.issueToBlock / time.Duration(i.IssueToBlockCount)
+}
+
+func (i *Interval) add(b *Block) {
+	i.Blocks++
+	i.Txs += b.Txs
+	i.Issued += b.Issued
+	i.Size += b.Size
+	for d := range i.Units {
+		i.Units[d] += b.Units[d]
+	}
+	i.Fullness = max(i.Fullness, b.Fullness())
+	i.issueToBlock += b.IssueToBlockMean * time.Duration(b.Issued)
+	i.IssueToBlockCount += b.Issued
+	i.IssueToBlockMax = max(i.IssueToBlockMax, b.IssueToBlockMax)
+}
+
+func (i *Interval) String() string {
+	txsPerBlock := 0.0
+	if i.Blocks > 0 {
+		txsPerBlock = float64(i.Txs) / float64(i.Blocks)
+	}
+	return fmt.Sprintf(
+		"blocks=%d txs/block=%.1f included/s=%.1f issued=%d fullness=%.2f%% size=%d issue->block(mean=%s max=%s) units=[%s]",
+		i.Blocks,
+		txsPerBlock,
+		i.TPS(),
+		i.Issued,
+		i.Fullness,
+		i.Size,
+		i.IssueToBlockMean().Round(time.Millisecond),
+		i.IssueToBlockMax.Round(time.Millisecond),
+		formatDimensions(i.Units),
+	)
+}
+
+// Listener follows accepted blocks on a single node.
+type Listener struct {
+	uri    string
+	parser chain.Parser
+	ttl    time.Duration
+
+	l         sync.Mutex
+	issued    map[ids.ID]time.Time
+	lastPrune time.Time
+	start     time.Time
+	last      time.Time
+	interval  Interval
+	total     Interval
+
+	f *os.File
+	w *csv.Writer
+}
+
+// New creates a listener for [uri]. If [csvPath] is not empty, every block
+// is also appended to a CSV file at that path.
+func New(uri string, parser chain.Parser, csvPath string) (*Listener, error) {
+	now := time.Now()
+	// Txs we never see in a block have expired once the validity window has
+	// passed, so there is no reason to keep their issue time much longer.
+	validityWindow := parser.Rules(now.UnixMilli()).GetValidityWindow()
+	l := &Listener{
+		uri:       uri,
+		parser:    parser,
+		ttl:       2 * time.Duration(validityWindow) * time.Millisecond,
+		issued:    map[ids.ID]time.Time{},
+		lastPrune: now,
+		start:     now,
+		last:      now,
+	}
+	if len(csvPath) == 0 {
+		return l, nil
+	}
+	f, err := os.Create(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	l.f = f
+	l.w = csv.NewWriter(f)
+	if err := l.w.Write(csvHeader); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Start subscribes to accepted blocks and processes them until [ctx] is
+// done or the connection is lost.
+func (l *Listener) Start(ctx context.Context) error {
+	cli, err := rpc.NewWebSocketClient(l.uri, rpc.DefaultHandshakeTimeout, pubsub.MaxPendingMessages, pubsub.MaxReadMessageSize) // we write the max read
+	if err != nil {
+		return err
+	}
+	if err := cli.RegisterBlocks(); err != nil {
+		_ = cli.Close()
+		return err
+	}
+	go func() {
+		defer func() {
+			_ = cli.Close()
+		}()
+		for {
+			blk, results, _, err := cli.ListenBlock(ctx, l.parser)
+			if err != nil {
+				if ctx.Err() == nil {
+					utils.Outf("{{orange}}block listener stopped:{{/}} %v\n", err)
+				}
+				return
+			}
+			l.record(blk, results, time.Now())
+		}
+	}()
+	return nil
+}
+
+// Issued records the time [txID] was sent so we can measure how long it
+// takes to be included.
+func (l *Listener) Issued(txID ids.ID) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	l.issued[txID] = time.Now()
+}
+
+func (l *Listener) record(blk *chain.StatelessBlock, results []*chain.Result, received time.Time) {
+	b := &Block{
+		Height:    blk.Hght,
+		Timestamp: blk.Tmstmp,
+		Received:  received,
+		Txs:       len(blk.Txs),
+		Size:      blk.Size(),
+		MaxUnits:  l.parser.Rules(blk.Tmstmp).GetMaxBlockUnits(),
+	}
+	for _, result := range results {
+		units, err := fees.Add(b.Units, result.Units)
+		if err != nil {
+			utils.Outf("{{orange}}failed to sum block units:{{/}} %v\n", err)
+			break
+		}
+		b.Units = units
+	}
+
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	var issueToBlock time.Duration
+	for _, tx := range blk.Txs {
+		issued, ok := l.issued[tx.ID()]
+		if !ok {
+			continue
+		}
+		delete(l.issued, tx.ID())
+		d := received.Sub(issued)
+		issueToBlock += d
+		b.IssueToBlockMax = max(b.IssueToBlockMax, d)
+		b.Issued++
+	}
+	if b.Issued > 0 {
+		b.IssueToBlockMean = issueToBlock / time.Duration(b.Issued)
+	}
+	if received.Sub(l.lastPrune) > l.ttl {
+		for txID, issued := range l.issued {
+			if received.Sub(issued) > l.ttl {
+				delete(l.issued, txID)
+			}
+		}
+		l.lastPrune = received
+	}
+	l.interval.add(b)
+	l.total.add(b)
+
+	if l.w != nil {
+		if err := l.w.Write(csvRecord(b)); err != nil {
+			utils.Outf("{{orange}}failed to write block stats:{{/}} %v\n", err)
+		}
+		l.w.Flush()
+	}
+}
+
+// Report returns the stats accumulated since the last call to Report.
+func (l *Listener) Report() *Interval {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	now := time.Now()
+	interval := l.interval
+	interval.Elapsed = now.Sub(l.last)
+	l.interval = Interval{}
+	l.last = now
+	return &interval
+}
+
+// Totals returns the stats accumulated since the listener was created.
+func (l *Listener) Totals() *Interval {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	total := l.total
+	total.Elapsed = time.Since(l.start)
+	return &total
+}
+
+// Close flushes and closes the CSV file, if any.
+func (l *Listener) Close() error {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	if l.f == nil {
+		return nil
+	}
+	l.w.Flush()
+	if err := l.w.Error(); err != nil {
+		_ = l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+func csvRecord(b *Block) []string {
+	record := []string{
+		b.Received.Format(time.RFC3339Nano),
+		strconv.FormatUint(b.Height, 10),
+		strconv.FormatInt(b.Timestamp, 10),
+		strconv.Itoa(b.Txs),
+		strconv.Itoa(b.Issued),
+		strconv.Itoa(b.Size),
+	}
+	for _, u := range b.Units {
+		record = append(record, strconv.FormatUint(u, 10))
+	}
+	return append(
+		record,
+		strconv.FormatFloat(b.Fullness(), 'f', 2, 64),
+		strconv.FormatInt(b.IssueToBlockMean.Milliseconds(), 10),
+		strconv.FormatInt(b.IssueToBlockMax.Milliseconds(), 10),
+	)
+}
+
+func fullness(units fees.Dimensions, limits fees.Dimensions) float64 {
+	var f float64
+	for d := range units {
+		if limits[d] == 0 {
+			continue
+		}
+		f = max(f, float64(units[d])/float64(limits[d])*100)
+	}
+	return f
+}
+
+func formatDimensions(d fees.Dimensions) string {
+	return fmt.Sprintf(
+		"bandwidth=%d compute=%d storage(read)=%d storage(allocate)=%d storage(write)=%d",
+		d[fees.Bandwidth],
+		d[fees.Compute],
+		d[fees.StorageRead],
+		d[fees.StorageAllocate],
+		d[fees.StorageWrite],
+	)
+}
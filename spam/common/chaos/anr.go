@@ -0,0 +1,81 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/client"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+
+	"github.com/AnomalyFi/tools/common/anr"
+)
+
+// ANR controls nodes through an avalanche-network-runner control server.
+type ANR struct {
+	cli client.Client
+}
+
+func NewANR(endpoint string) (*ANR, error) {
+	cli, err := client.New(client.Config{
+		Endpoint:    endpoint,
+		DialTimeout: 10 * time.Second,
+	}, logging.NoLog{})
+	if err != nil {
+		return nil, err
+	}
+	return &ANR{cli: cli}, nil
+}
+
+func (a *ANR) Inject(ctx context.Context, action Action, node string) error {
+	var err error
+	switch action {
+	case Stop:
+		_, err = a.cli.RemoveNode(ctx, node)
+	case Restart:
+		_, err = a.cli.RestartNode(ctx, node)
+	case Pause:
+		_, err = a.cli.PauseNode(ctx, node)
+	case Resume:
+		_, err = a.cli.ResumeNode(ctx, node)
+	default:
+		err = fmt.Errorf("unknown action %q", action)
+	}
+	return err
+}
+
+// Cluster is the SEQ chain running on the ANR network.
+type Cluster struct {
+	ChainID ids.ID
+	URIs    []string
+	// Nodes is the name of the node serving each URI
+	Nodes []string
+}
+
+// Cluster loads the chain URIs of every node from ANR. The cluster must run
+// a single custom chain.
+func (a *ANR) Cluster(ctx context.Context) (*Cluster, error) {
+	status, err := a.cli.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := anr.FromClusterInfo(status.ClusterInfo)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := info.Chain("")
+	if err != nil {
+		return nil, err
+	}
+	cluster := &Cluster{ChainID: chain.ID}
+	for _, e := range info.Serving(chain.ID) {
+		cluster.URIs = append(cluster.URIs, e.URI)
+		cluster.Nodes = append(cluster.Nodes, e.Node)
+	}
+	return cluster, nil
+}
+
+func (a *ANR) Close() error {
+	return a.cli.Close()
+}
@@ -0,0 +1,310 @@
+// Package chaos injects node faults into an avalanche-network-runner cluster
+// while a spammer is running and reports how the load recovered.
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/utils"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+type Action string
+
+const (
+	// Stop removes the node from the network for the rest of the run.
+	Stop    Action = "stop"
+	Restart Action = "restart"
+	Pause   Action = "pause"
+	Resume  Action = "resume"
+)
+
+// Fault is an action applied to a node [At] after the run starts.
+type Fault struct {
+	At     time.Duration `json:"at"`
+	Action Action        `json:"action"`
+	Node   string        `json:"node"`
+}
+
+func (f Fault) String() string {
+	return fmt.Sprintf("%s %s at +%s", f.Action, f.Node, f.At)
+}
+
+// ParseSchedule parses a comma separated list of offset:action:node entries,
+// e.g. "30s:pause:node2,1m30s:resume:node2".
+func ParseSchedule(spec string) ([]Fault, error) {
+	var faults []Fault
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid fault %q: expected offset:action:node", entry)
+		}
+		at, err := time.ParseDuration(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid offset in fault %q", err, entry)
+		}
+		action := Action(parts[1])
+		switch action {
+		case Stop, Restart, Pause, Resume:
+		default:
+			return nil, fmt.Errorf("invalid fault %q: unknown action %q", entry, parts[1])
+		}
+		if len(parts[2]) == 0 {
+			return nil, fmt.Errorf("invalid fault %q: missing node name", entry)
+		}
+		faults = append(faults, Fault{At: at, Action: action, Node: parts[2]})
+	}
+	sort.SliceStable(faults, func(i, j int) bool { return faults[i].At < faults[j].At })
+	// A stopped node is gone from the network, so nothing can bring it back
+	stopped := map[string]Fault{}
+	for _, f := range faults {
+		if s, ok := stopped[f.Node]; ok {
+			return nil, fmt.Errorf("invalid fault %q: %s was removed by %q; pause it instead to resume it later", f, f.Node, s)
+		}
+		if f.Action == Stop {
+			stopped[f.Node] = f
+		}
+	}
+	return faults, nil
+}
+
+// Controller applies faults to nodes.
+type Controller interface {
+	Inject(ctx context.Context, action Action, node string) error
+}
+
+// Event is a fault that was applied.
+type Event struct {
+	Fault Fault     `json:"fault"`
+	Time  time.Time `json:"time"`
+	Err   string    `json:"err,omitempty"`
+}
+
+// Runner applies a fault schedule and samples the spammer's counters every
+// second so recovery can be measured.
+type Runner struct {
+	controller Controller
+	faults     []Fault
+	collector  *stats.Collector
+
+	l       sync.Mutex
+	events  []*Event
+	samples []*stats.Snapshot
+}
+
+func NewRunner(controller Controller, faults []Fault, collector *stats.Collector) *Runner {
+	return &Runner{
+		controller: controller,
+		faults:     faults,
+		collector:  collector,
+	}
+}
+
+// Run applies every fault and keeps sampling for [tail] after the last one.
+// It returns early if [ctx] is done.
+func (r *Runner) Run(ctx context.Context, tail time.Duration) {
+	start := time.Now()
+	r.sample()
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+
+	end := tail
+	if len(r.faults) > 0 {
+		end += r.faults[len(r.faults)-1].At
+	}
+	done := time.NewTimer(end)
+	defer done.Stop()
+
+	next := 0
+	first := end
+	if len(r.faults) > 0 {
+		first = r.faults[0].At
+	}
+	timer := time.NewTimer(first)
+	defer timer.Stop()
+	for {
+		select {
+		case <-t.C:
+			r.sample()
+		case <-timer.C:
+			if next >= len(r.faults) {
+				continue
+			}
+			f := r.faults[next]
+			next++
+			r.inject(ctx, f)
+			if next < len(r.faults) {
+				timer.Reset(time.Until(start.Add(r.faults[next].At)))
+			}
+		case <-done.C:
+			r.sample()
+			return
+		case <-ctx.Done():
+			r.sample()
+			return
+		}
+	}
+}
+
+func (r *Runner) sample() {
+	s := r.collector.Snapshot()
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.samples = append(r.samples, s)
+}
+
+func (r *Runner) inject(ctx context.Context, f Fault) {
+	e := &Event{Fault: f, Time: time.Now()}
+	if err := r.controller.Inject(ctx, f.Action, f.Node); err != nil {
+		e.Err = err.Error()
+		utils.Outf("{{orange}}failed to inject fault:{{/}} %s {{orange}}error:{{/}} %v\n", f, err)
+	} else {
+		utils.Outf("{{red}}injected fault:{{/}} %s {{red}}at:{{/}} %s\n", f, e.Time.Format(time.RFC3339Nano))
+	}
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.events = append(r.events, e)
+}
+
+// FaultReport describes the load around a single fault.
+type FaultReport struct {
+	Event *Event `json:"event"`
+
+	// Measured over the window before the fault
+	BaselineTPS        float64       `json:"baselineTps"`
+	BaselineErrors     uint64        `json:"baselineErrors"`
+	BaselineLatencyP50 time.Duration `json:"baselineLatencyP50"`
+	BaselineLatencyP99 time.Duration `json:"baselineLatencyP99"`
+
+	// Measured over the window after the fault
+	TroughTPS  float64                   `json:"troughTps"`
+	Errors     map[stats.Category]uint64 `json:"errors"`
+	LatencyP50 time.Duration             `json:"latencyP50"`
+	LatencyP99 time.Duration             `json:"latencyP99"`
+
+	// Time from the fault until throughput was back to the baseline
+	Recovered      bool          `json:"recovered"`
+	RecoveredAfter time.Duration `json:"recoveredAfter"`
+}
+
+type Report struct {
+	Faults []*FaultReport `json:"faults"`
+}
+
+// Report compares the [window] before and after every fault. Throughput is
+// considered recovered once the mean of three consecutive seconds reaches
+// [threshold] times the baseline.
+func (r *Runner) Report(window time.Duration, threshold float64) *Report {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	report := &Report{}
+	for _, e := range r.events {
+		fr := &FaultReport{Event: e}
+		report.Faults = append(report.Faults, fr)
+		if len(r.samples) < 2 {
+			continue
+		}
+		before := r.between(e.Time.Add(-window), e.Time)
+		fr.BaselineTPS = before.ConfirmedPerSecond()
+		fr.BaselineErrors = before.Errors()
+		fr.BaselineLatencyP50 = before.Latency.Quantile(0.5)
+		fr.BaselineLatencyP99 = before.Latency.Quantile(0.99)
+
+		after := r.between(e.Time, e.Time.Add(window))
+		fr.Errors = after.Outcomes
+		delete(fr.Errors, stats.Confirmed)
+		fr.LatencyP50 = after.Latency.Quantile(0.5)
+		fr.LatencyP99 = after.Latency.Quantile(0.99)
+
+		fr.TroughTPS = -1
+		var rolling []float64
+		for i := 1; i < len(r.samples); i++ {
+			if !r.samples[i].Time.After(e.Time) {
+				continue
+			}
+			tps := r.samples[i].Sub(r.samples[i-1]).ConfirmedPerSecond()
+			if r.samples[i].Time.Before(e.Time.Add(window)) && (fr.TroughTPS < 0 || tps < fr.TroughTPS) {
+				fr.TroughTPS = tps
+			}
+			rolling = append(rolling, tps)
+			if len(rolling) > 3 {
+				rolling = rolling[1:]
+			}
+			if fr.Recovered || len(rolling) < 3 {
+				continue
+			}
+			mean := (rolling[0] + rolling[1] + rolling[2]) / 3
+			if mean >= threshold*fr.BaselineTPS {
+				fr.Recovered = true
+				fr.RecoveredAfter = r.samples[i].Time.Sub(e.Time)
+			}
+		}
+		fr.TroughTPS = max(fr.TroughTPS, 0)
+	}
+	return report
+}
+
+// between returns what happened between the samples closest to [from] and
+// [to].
+func (r *Runner) between(from, to time.Time) *stats.Snapshot {
+	first, last := r.samples[0], r.samples[0]
+	for _, s := range r.samples {
+		if !s.Time.After(from) {
+			first = s
+		}
+		if !s.Time.After(to) {
+			last = s
+		}
+	}
+	return last.Sub(first)
+}
+
+func (r *Report) Print() {
+	for _, f := range r.Faults {
+		if len(f.Event.Err) > 0 {
+			utils.Outf("{{orange}}fault not applied:{{/}} %s {{orange}}error:{{/}} %s\n", f.Event.Fault, f.Event.Err)
+			continue
+		}
+		recovery := "{{red}}not recovered{{/}}"
+		if f.Recovered {
+			recovery = fmt.Sprintf("{{green}}recovered after %s{{/}}", f.RecoveredAfter.Round(time.Second))
+		}
+		errs := make([]string, 0, len(f.Errors))
+		for k, v := range f.Errors {
+			errs = append(errs, fmt.Sprintf("%s=%d", k, v))
+		}
+		sort.Strings(errs)
+		utils.Outf(
+			"{{cyan}}fault:{{/}} %s {{yellow}}tps:{{/}} %.1f -> %.1f {{yellow}}errors:{{/}} %d -> [%s] {{yellow}}latency p50/p99:{{/}} %s/%s -> %s/%s "+recovery+"\n", //nolint:lll
+			f.Event.Fault,
+			f.BaselineTPS,
+			f.TroughTPS,
+			f.BaselineErrors,
+			strings.Join(errs, " "),
+			f.BaselineLatencyP50,
+			f.BaselineLatencyP99,
+			f.LatencyP50,
+			f.LatencyP99,
+		)
+	}
+}
+
+// Write stores the report as JSON at [path].
+func (r *Report) Write(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
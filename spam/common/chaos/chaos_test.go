@@ -0,0 +1,57 @@
+package chaos
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want []Fault
+		err  bool
+	}{
+		{
+			name: "sorted by offset",
+			spec: "1m30s:resume:node2, 30s:pause:node2,2m:restart:node3",
+			want: []Fault{
+				{At: 30 * time.Second, Action: Pause, Node: "node2"},
+				{At: 90 * time.Second, Action: Resume, Node: "node2"},
+				{At: 2 * time.Minute, Action: Restart, Node: "node3"},
+			},
+		},
+		{
+			name: "stop another node",
+			spec: "10s:stop:node1,20s:restart:node2",
+			want: []Fault{
+				{At: 10 * time.Second, Action: Stop, Node: "node1"},
+				{At: 20 * time.Second, Action: Restart, Node: "node2"},
+			},
+		},
+		{name: "restart after stop", spec: "20s:restart:node1,10s:stop:node1", err: true},
+		{name: "stop twice", spec: "10s:stop:node1,20s:stop:node1", err: true},
+		{name: "unknown action", spec: "10s:kill:node1", err: true},
+		{name: "bad offset", spec: "soon:stop:node1", err: true},
+		{name: "missing node", spec: "10s:stop:", err: true},
+		{name: "missing part", spec: "10s:stop", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSchedule(tt.spec)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
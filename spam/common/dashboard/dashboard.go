@@ -0,0 +1,340 @@
+// Package dashboard draws a full-screen live view of a spam run: charts of
+// throughput, latency and unit prices, a table of nodes and a rolling log.
+//
+// While the dashboard is shown everything written to os.Stdout, e.g. by
+// utils.Outf, is captured into the log instead of scrolling the view away.
+package dashboard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/utils"
+	"golang.org/x/term"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+const (
+	refreshInterval = 500 * time.Millisecond
+	// maxHistory is more samples than any terminal is wide
+	maxHistory = 512
+	maxLog     = 256
+
+	labelWidth = 18
+	valueWidth = 12
+
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+	bold        = "\x1b[1m"
+	yellow      = "\x1b[33m"
+	red         = "\x1b[31m"
+	green       = "\x1b[32m"
+	reset       = "\x1b[0m"
+)
+
+var (
+	ErrNotTerminal = errors.New("the dashboard needs stdout to be a terminal")
+
+	ansi = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+	// categories are the "what went wrong:" prefix of a log line
+	category = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9 ()\-]*):`)
+)
+
+// Node is one row of the node table.
+type Node struct {
+	URI         string
+	State       string
+	Outstanding int
+	Reconnects  int
+	Errors      int
+}
+
+// Frame is what the spammer reports every second.
+type Frame struct {
+	// Delta is what happened since the last frame, Total since the start
+	Delta      *stats.Snapshot
+	Total      *stats.Snapshot
+	Inflight   int64
+	UnitPrices fees.Dimensions
+	Nodes      []Node
+}
+
+type series struct {
+	name   string
+	format func(float64) string
+	values []float64
+}
+
+func (s *series) add(v float64) {
+	s.values = append(s.values, v)
+	if len(s.values) > maxHistory {
+		s.values = s.values[len(s.values)-maxHistory:]
+	}
+}
+
+type Dashboard struct {
+	title string
+	tty   *os.File
+	pipe  *os.File
+
+	l          sync.Mutex
+	frame      *Frame
+	charts     []*series
+	log        []string
+	categories map[string]int
+
+	done   chan struct{}
+	closed sync.Once
+	wg     sync.WaitGroup
+}
+
+// Start takes over the terminal until [Close] is called.
+func Start(title string) (*Dashboard, error) {
+	tty := os.Stdout
+	if !term.IsTerminal(int(tty.Fd())) {
+		return nil, ErrNotTerminal
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	count := func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	ms := func(v float64) string {
+		return time.Duration(v * float64(time.Millisecond)).Round(time.Millisecond).String()
+	}
+	d := &Dashboard{
+		title: title,
+		tty:   tty,
+		pipe:  w,
+		charts: []*series{
+			{name: "issued/s", format: count},
+			{name: "confirmed/s", format: count},
+			{name: "inflight", format: count},
+			{name: "success rate", format: func(v float64) string { return fmt.Sprintf("%.2f%%", v) }},
+			{name: "latency p50", format: ms},
+			{name: "latency p90", format: ms},
+			{name: "latency p99", format: ms},
+			{name: "bandwidth", format: count},
+			{name: "compute", format: count},
+			{name: "storage(read)", format: count},
+			{name: "storage(allocate)", format: count},
+			{name: "storage(write)", format: count},
+		},
+		categories: map[string]int{},
+		done:       make(chan struct{}),
+	}
+	os.Stdout = w
+	fmt.Fprint(tty, enterScreen)
+
+	d.wg.Add(2)
+	go d.capture(r)
+	go d.refresh()
+	return d, nil
+}
+
+// capture moves every line written to os.Stdout into the log.
+func (d *Dashboard) capture(r io.ReadCloser) {
+	defer d.wg.Done()
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(ansi.ReplaceAllString(scanner.Text(), ""))
+		if len(line) == 0 {
+			continue
+		}
+		d.l.Lock()
+		d.log = append(d.log, time.Now().Format("15:04:05")+" "+line)
+		if len(d.log) > maxLog {
+			d.log = d.log[len(d.log)-maxLog:]
+		}
+		if m := category.FindStringSubmatch(line); m != nil {
+			d.categories[m[1]]++
+		}
+		d.l.Unlock()
+	}
+}
+
+func (d *Dashboard) refresh() {
+	defer d.wg.Done()
+
+	t := time.NewTicker(refreshInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			d.render()
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// Update adds a second of the run to the charts.
+func (d *Dashboard) Update(f *Frame) {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	d.frame = f
+	successRate := 0.0
+	if resolved := f.Total.Resolved(); resolved > 0 {
+		successRate = float64(f.Total.Outcomes[stats.Confirmed]) / float64(resolved) * 100
+	}
+	values := []float64{
+		float64(f.Delta.Issued),
+		float64(f.Delta.Outcomes[stats.Confirmed]),
+		float64(f.Inflight),
+		successRate,
+		ms(f.Delta.Latency.Quantile(0.5)),
+		ms(f.Delta.Latency.Quantile(0.9)),
+		ms(f.Delta.Latency.Quantile(0.99)),
+		float64(f.UnitPrices[fees.Bandwidth]),
+		float64(f.UnitPrices[fees.Compute]),
+		float64(f.UnitPrices[fees.StorageRead]),
+		float64(f.UnitPrices[fees.StorageAllocate]),
+		float64(f.UnitPrices[fees.StorageWrite]),
+	}
+	for i, v := range values {
+		d.charts[i].add(v)
+	}
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Close gives the terminal back and prints how often each log category
+// was seen.
+func (d *Dashboard) Close() error {
+	var err error
+	d.closed.Do(func() {
+		close(d.done)
+		os.Stdout = d.tty
+		err = d.pipe.Close()
+		d.wg.Wait()
+		fmt.Fprint(d.tty, leaveScreen)
+
+		d.l.Lock()
+		defer d.l.Unlock()
+		if len(d.categories) > 0 {
+			utils.Outf("{{yellow}}dashboard log:{{/}} [%s]\n", d.categoryCounts())
+		}
+	})
+	return err
+}
+
+func (d *Dashboard) categoryCounts() string {
+	counts := make([]string, 0, len(d.categories))
+	for k, v := range d.categories {
+		counts = append(counts, fmt.Sprintf("%s=%d", k, v))
+	}
+	sort.Strings(counts)
+	return strings.Join(counts, " ")
+}
+
+func (d *Dashboard) render() {
+	width, height, err := term.GetSize(int(d.tty.Fd()))
+	if err != nil {
+		width, height = 120, 40
+	}
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	line := func(format string, args ...any) {
+		s := fmt.Sprintf(format, args...)
+		b.WriteString(s)
+		b.WriteString("\r\n")
+		height--
+	}
+
+	header := bold + d.title + reset
+	if f := d.frame; f != nil {
+		header += fmt.Sprintf(
+			"  elapsed %s  issued %d  confirmed %d  errors %d",
+			f.Total.Elapsed.Round(time.Second),
+			f.Total.Issued,
+			f.Total.Outcomes[stats.Confirmed],
+			f.Total.Errors(),
+		)
+	}
+	line("%s", header)
+	line("")
+
+	chartWidth := max(width-labelWidth-valueWidth-2, 10)
+	for i, s := range d.charts {
+		if i == 7 {
+			line("%sunit prices%s", bold, reset)
+		}
+		value := ""
+		if n := len(s.values); n > 0 {
+			value = s.format(s.values[n-1])
+		}
+		line("%s%-*s%s%*s  %s", yellow, labelWidth, s.name, reset, valueWidth, value, sparkline(s.values, chartWidth))
+	}
+	line("")
+
+	line("%s%-4s %-*s %-10s %11s %10s %7s%s", bold, "node", max(width-50, 20), "uri", "state", "outstanding", "reconnects", "errors", reset)
+	if d.frame != nil {
+		for i, n := range d.frame.Nodes {
+			color := green
+			if n.State != "healthy" {
+				color = red
+			}
+			line("%-4d %-*s %s%-10s%s %11d %10d %7d", i, max(width-50, 20), truncate(n.URI, max(width-50, 20)), color, n.State, reset, n.Outstanding, n.Reconnects, n.Errors)
+		}
+	}
+	line("")
+
+	line("%slog%s [%s]", bold, reset, d.categoryCounts())
+	start := max(len(d.log)-max(height, 0), 0)
+	for _, l := range d.log[start:] {
+		line("%s", truncate(l, width))
+	}
+	fmt.Fprint(d.tty, b.String())
+}
+
+var ticks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the last [width] values scaled between their minimum and
+// maximum.
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(ticks)-1))
+		}
+		b.WriteRune(ticks[i])
+	}
+	return b.String()
+}
+
+func truncate(s string, width int) string {
+	if r := []rune(s); len(r) > width {
+		return string(r[:max(width, 0)])
+	}
+	return s
+}
@@ -0,0 +1,414 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/utils"
+
+	"github.com/AnomalyFi/tools/spam/common/results"
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+const (
+	// defaultStartDelay gives every worker time to receive the start time
+	// before it passes.
+	defaultStartDelay = 2 * time.Second
+	// lostAfter is how long a running worker may go without reporting
+	// before the controller stops waiting for it.
+	lostAfter = 10 * ReportInterval
+)
+
+var (
+	ErrInvalidPlan = errors.New("invalid plan")
+	ErrInterrupted = errors.New("interrupted before the workers started")
+)
+
+// Plan is how the controller divides the run.
+type Plan struct {
+	Workers int
+	// Accounts are split as evenly as possible, so the target rate of
+	// Accounts*TxsPerAccount txs per second is too
+	Accounts      int
+	TxsPerAccount int
+	// Budget is split evenly between workers
+	Budget     uint64
+	StartDelay time.Duration
+}
+
+func (p Plan) assignment(i int) Assignment {
+	accounts := p.Accounts / p.Workers
+	if i < p.Accounts%p.Workers {
+		accounts++
+	}
+	return Assignment{
+		Worker:        i,
+		Workers:       p.Workers,
+		Accounts:      accounts,
+		TxsPerAccount: p.TxsPerAccount,
+		Budget:        p.Budget / uint64(p.Workers),
+	}
+}
+
+type workerState struct {
+	name       string
+	ready      bool
+	last       time.Time
+	snapshot   *stats.Snapshot
+	stopped    *stats.Snapshot
+	stopReason string
+	done       bool
+	lost       bool
+}
+
+// Controller coordinates the workers of one run.
+type Controller struct {
+	plan     Plan
+	listener net.Listener
+	server   *http.Server
+
+	l          sync.Mutex
+	workers    []*workerState
+	registered chan struct{}
+	ready      int
+	started    chan struct{}
+	start      time.Time
+	stopReason string
+}
+
+// NewController listens on [addr] for the workers of [plan].
+func NewController(addr string, plan Plan) (*Controller, error) {
+	if plan.Workers < 1 || plan.Accounts < plan.Workers || plan.TxsPerAccount < 1 {
+		return nil, fmt.Errorf("%w: need at least one worker, one account per worker and one tx per account", ErrInvalidPlan)
+	}
+	if plan.StartDelay == 0 {
+		plan.StartDelay = defaultStartDelay
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Controller{
+		plan:       plan,
+		listener:   listener,
+		registered: make(chan struct{}),
+		started:    make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(registerPath, c.register)
+	mux.HandleFunc(readyPath, c.readyHandler)
+	mux.HandleFunc(reportPath, c.report)
+	c.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return c, nil
+}
+
+func (c *Controller) Addr() string {
+	return c.listener.Addr().String()
+}
+
+func (c *Controller) register(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	c.l.Lock()
+	if len(c.workers) == c.plan.Workers {
+		c.l.Unlock()
+		http.Error(w, fmt.Sprintf("all %d workers already joined", c.plan.Workers), http.StatusConflict)
+		return
+	}
+	i := len(c.workers)
+	c.workers = append(c.workers, &workerState{name: req.Name})
+	utils.Outf("{{yellow}}worker joined:{{/}} %d/%d %s\n", i+1, c.plan.Workers, req.Name)
+	if len(c.workers) == c.plan.Workers {
+		close(c.registered)
+	}
+	c.l.Unlock()
+
+	select {
+	case <-c.registered:
+		reply(w, c.plan.assignment(i))
+	case <-r.Context().Done():
+	}
+}
+
+func (c *Controller) readyHandler(w http.ResponseWriter, r *http.Request) {
+	var req readyRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	c.l.Lock()
+	if req.Worker < 0 || req.Worker >= len(c.workers) {
+		c.l.Unlock()
+		http.Error(w, fmt.Sprintf("unknown worker %d", req.Worker), http.StatusBadRequest)
+		return
+	}
+	if !c.workers[req.Worker].ready {
+		c.workers[req.Worker].ready = true
+		c.ready++
+		if c.ready == c.plan.Workers {
+			c.start = time.Now().Add(c.plan.StartDelay)
+			for _, s := range c.workers {
+				s.last = c.start
+			}
+			close(c.started)
+		}
+	}
+	c.l.Unlock()
+
+	select {
+	case <-c.started:
+		reply(w, &readyReply{Start: c.start})
+	case <-r.Context().Done():
+	}
+}
+
+func (c *Controller) report(w http.ResponseWriter, r *http.Request) {
+	var req reportRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if req.Worker < 0 || req.Worker >= len(c.workers) {
+		http.Error(w, fmt.Sprintf("unknown worker %d", req.Worker), http.StatusBadRequest)
+		return
+	}
+	s := c.workers[req.Worker]
+	s.last = time.Now()
+	s.lost = false
+	s.snapshot = req.Snapshot
+	if req.Done {
+		s.done = true
+		s.stopped = req.Stopped
+		s.stopReason = req.StopReason
+	}
+	reply(w, &reportReply{Stop: len(c.stopReason) > 0, Reason: c.stopReason})
+}
+
+// Stop asks every worker to stop issuing and drain.
+func (c *Controller) Stop(reason string) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if len(c.stopReason) == 0 {
+		utils.Outf("{{yellow}}stopping workers:{{/}} %s\n", reason)
+		c.stopReason = reason
+	}
+}
+
+// Snapshot adds up the latest counters of every worker.
+func (c *Controller) Snapshot() *stats.Snapshot {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	snapshots := make([]*stats.Snapshot, len(c.workers))
+	for i, s := range c.workers {
+		snapshots[i] = s.snapshot
+	}
+	m := stats.Merge(snapshots...)
+	m.Time = time.Now()
+	m.Elapsed = m.Time.Sub(c.start)
+	return m
+}
+
+// check marks workers that stopped reporting as lost and returns how many
+// are still running and whether all of them stopped issuing.
+func (c *Controller) check() (running int, issuing bool) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	for i, s := range c.workers {
+		if s.done || s.lost {
+			continue
+		}
+		if time.Since(s.last) > lostAfter {
+			s.lost = true
+			utils.Outf("{{red}}lost worker:{{/}} %d %s {{red}}no report for:{{/}} %s\n", i, s.name, lostAfter)
+			continue
+		}
+		running++
+	}
+	return running, running > 0 && len(c.stopReason) == 0
+}
+
+// Run serves the workers until all of them finished, were lost or the
+// controller is interrupted twice. [duration] stops the workers that long
+// after the start if positive.
+func (c *Controller) Run(ctx context.Context, duration time.Duration) (*Report, error) {
+	go func() {
+		_ = c.server.Serve(c.listener)
+	}()
+	defer c.server.Close()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	utils.Outf("{{yellow}}controller listening on:{{/}} %s {{yellow}}waiting for workers:{{/}} %d\n", c.Addr(), c.plan.Workers)
+	select {
+	case <-c.started:
+	case <-signals:
+		return nil, ErrInterrupted
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case <-time.After(time.Until(c.start)):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	utils.Outf(
+		"{{yellow}}started %d workers:{{/}} %d accounts {{yellow}}target rate:{{/}} %d tx/s\n",
+		c.plan.Workers,
+		c.plan.Accounts,
+		c.plan.Accounts*c.plan.TxsPerAccount,
+	)
+
+	sctx, stopSampling := context.WithCancel(ctx)
+	defer stopSampling()
+	sampler := results.NewSampler(c)
+	go sampler.Run(sctx)
+
+	var deadline <-chan time.Time
+	if duration > 0 {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	prev := c.Snapshot()
+	interrupted := false
+	for {
+		select {
+		case <-t.C:
+			current := c.Snapshot()
+			running, issuing := c.check()
+			printLive(running, c.plan.Workers, current.Sub(prev), current)
+			prev = current
+			if !issuing {
+				stopSampling()
+			}
+			if running == 0 {
+				return c.finalReport(sampler.Samples()), nil
+			}
+		case <-deadline:
+			c.Stop("duration reached")
+		case <-signals:
+			if interrupted {
+				utils.Outf("{{red}}interrupted again, not waiting for workers{{/}}\n")
+				return c.finalReport(sampler.Samples()), nil
+			}
+			interrupted = true
+			c.Stop("interrupted")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func printLive(running, workers int, delta, total *stats.Snapshot) {
+	successRate := 0.0
+	if resolved := total.Resolved(); resolved > 0 {
+		successRate = float64(total.Outcomes[stats.Confirmed]) / float64(resolved) * 100
+	}
+	utils.Outf(
+		"{{yellow}}workers:{{/}} %d/%d {{yellow}}issued:{{/}} %d {{yellow}}confirmed:{{/}} %d {{yellow}}success rate:{{/}} %.2f%% {{yellow}}issued/s:{{/}} %d {{yellow}}confirmed/s:{{/}} %d {{yellow}}p50:{{/}} %s {{yellow}}p99:{{/}} %s\n", //nolint:lll
+		running,
+		workers,
+		total.Issued,
+		total.Outcomes[stats.Confirmed],
+		successRate,
+		delta.Issued,
+		delta.Outcomes[stats.Confirmed],
+		delta.Latency.Quantile(0.5),
+		delta.Latency.Quantile(0.99),
+	)
+}
+
+// WorkerReport is how one worker's run ended.
+type WorkerReport struct {
+	Name       string          `json:"name"`
+	StopReason string          `json:"stopReason"`
+	Lost       bool            `json:"lost"`
+	Final      *stats.Snapshot `json:"final"`
+}
+
+// Report is the aggregated outcome of every worker.
+type Report struct {
+	StopReason string
+	// Stopped adds up the workers' snapshots from when they stopped issuing,
+	// Final those from after their drain
+	Stopped *stats.Snapshot
+	Final   *stats.Snapshot
+	Workers []*WorkerReport
+	Series  []results.Sample
+}
+
+func (c *Controller) finalReport(series []results.Sample) *Report {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	r := &Report{StopReason: c.stopReason, Series: series}
+	if len(r.StopReason) == 0 {
+		r.StopReason = "workers finished"
+	}
+	var stopped, final []*stats.Snapshot
+	for _, s := range c.workers {
+		reason := s.stopReason
+		switch {
+		case s.lost:
+			reason = "lost"
+		case !s.done:
+			reason = "still running"
+		}
+		r.Workers = append(r.Workers, &WorkerReport{
+			Name:       s.name,
+			StopReason: reason,
+			Lost:       s.lost,
+			Final:      s.snapshot,
+		})
+		// Workers that never finished only have their last report
+		if s.stopped != nil {
+			stopped = append(stopped, s.stopped)
+		} else {
+			stopped = append(stopped, s.snapshot)
+		}
+		final = append(final, s.snapshot)
+	}
+	r.Stopped = stats.Merge(stopped...)
+	r.Final = stats.Merge(final...)
+	return r
+}
+
+func (r *Report) Print() {
+	for i, w := range r.Workers {
+		color := "yellow"
+		if w.Lost {
+			color = "red"
+		}
+		final := stats.Merge(w.Final)
+		utils.Outf(
+			"{{"+color+"}}worker %d:{{/}} %s {{yellow}}stopped:{{/}} %s {{yellow}}issued:{{/}} %d {{yellow}}confirmed:{{/}} %d {{yellow}}errors:{{/}} %d\n",
+			i,
+			w.Name,
+			w.StopReason,
+			final.Issued,
+			final.Outcomes[stats.Confirmed],
+			final.Errors(),
+		)
+	}
+}
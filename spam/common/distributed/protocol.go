@@ -0,0 +1,105 @@
+// Package distributed splits a spam run across worker processes. A
+// controller hands every worker its share of the accounts and funds, starts
+// them together and aggregates their counters into one view.
+//
+// Workers talk to the controller over HTTP with JSON bodies:
+//
+//	POST /register  blocks until every worker joined, returns its Assignment
+//	POST /ready     blocks until every worker funded its accounts, returns the start time
+//	POST /report    sends the worker's counters, returns whether it should stop
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+const (
+	registerPath = "/register"
+	readyPath    = "/ready"
+	reportPath   = "/report"
+)
+
+// Assignment is a worker's share of the run.
+type Assignment struct {
+	Worker        int `json:"worker"`
+	Workers       int `json:"workers"`
+	Accounts      int `json:"accounts"`
+	TxsPerAccount int `json:"txsPerAccount"`
+	// Budget is how much of the root account's balance the worker may
+	// distribute
+	Budget uint64 `json:"budget"`
+}
+
+type registerRequest struct {
+	Name string `json:"name"`
+}
+
+type readyRequest struct {
+	Worker int `json:"worker"`
+}
+
+type readyReply struct {
+	Start time.Time `json:"start"`
+}
+
+type reportRequest struct {
+	Worker   int             `json:"worker"`
+	Snapshot *stats.Snapshot `json:"snapshot"`
+	// Stopped, StopReason and Done are only set by the last report
+	Stopped    *stats.Snapshot `json:"stopped,omitempty"`
+	StopReason string          `json:"stopReason,omitempty"`
+	Done       bool            `json:"done"`
+}
+
+type reportReply struct {
+	Stop   bool   `json:"stop"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func post(ctx context.Context, client *http.Client, url string, req, reply any) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s: %s", url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(reply)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func reply(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
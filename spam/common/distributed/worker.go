@@ -0,0 +1,117 @@
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/utils"
+
+	"github.com/AnomalyFi/tools/spam/common/results"
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+// ReportInterval is how often workers send their counters.
+const ReportInterval = time.Second
+
+// Worker is the connection of a spam process to its controller.
+type Worker struct {
+	url        string
+	client     *http.Client
+	assignment Assignment
+
+	once sync.Once
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Join registers with the controller at [controller] (host:port or URL) and
+// blocks until every worker joined.
+func Join(ctx context.Context, controller string) (*Worker, error) {
+	if !strings.Contains(controller, "://") {
+		controller = "http://" + controller
+	}
+	w := &Worker{
+		url:    strings.TrimSuffix(controller, "/"),
+		client: &http.Client{},
+		done:   make(chan struct{}),
+	}
+	host, _ := os.Hostname()
+	req := &registerRequest{Name: fmt.Sprintf("%s/%d", host, os.Getpid())}
+	if err := post(ctx, w.client, w.url+registerPath, req, &w.assignment); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Worker) Assignment() Assignment {
+	return w.assignment
+}
+
+// Ready tells the controller the worker's accounts are funded and waits
+// until every worker should start issuing.
+func (w *Worker) Ready(ctx context.Context) error {
+	var r readyReply
+	if err := post(ctx, w.client, w.url+readyPath, &readyRequest{Worker: w.assignment.Worker}, &r); err != nil {
+		return err
+	}
+	utils.Outf("{{yellow}}starting at:{{/}} %s\n", r.Start.Format(time.RFC3339Nano))
+	t := time.NewTimer(time.Until(r.Start))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Report sends the source's counters every [ReportInterval] until [Finish]
+// is called, including while the worker drains. If the controller asks the
+// worker to stop, [stop] is called with its reason.
+func (w *Worker) Report(ctx context.Context, source results.Source, stop func(reason string)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		t := time.NewTicker(ReportInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				var r reportReply
+				req := &reportRequest{Worker: w.assignment.Worker, Snapshot: source.Snapshot()}
+				if err := post(ctx, w.client, w.url+reportPath, req, &r); err != nil {
+					utils.Outf("{{red}}report to controller failed:{{/}} %v\n", err)
+					continue
+				}
+				if r.Stop {
+					stop(r.Reason)
+				}
+			case <-w.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Finish stops reporting and sends the worker's final counters. [stopped]
+// is the snapshot taken when issuing stopped, [final] the one after the
+// drain.
+func (w *Worker) Finish(ctx context.Context, reason string, stopped, final *stats.Snapshot) error {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+	var r reportReply
+	return post(ctx, w.client, w.url+reportPath, &reportRequest{
+		Worker:     w.assignment.Worker,
+		Snapshot:   final,
+		Stopped:    stopped,
+		StopReason: reason,
+		Done:       true,
+	}, &r)
+}
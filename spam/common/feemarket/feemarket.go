@@ -0,0 +1,220 @@
+// Package feemarket drives a spammer through scripted load phases and records
+// how SEQ's unit prices respond, so the fee controller's reaction and
+// settling time can be plotted afterwards.
+package feemarket
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/utils"
+)
+
+// DefaultPhases is a short quiet period, a surge and a slow decay.
+const DefaultPhases = "quiet:30s:1,surge:60s:50,decay:2m:5"
+
+var csvHeader = []string{
+	"time",
+	"elapsed_s",
+	"phase",
+	"txs_per_account",
+	"bandwidth",
+	"compute",
+	"storage_read",
+	"storage_allocate",
+	"storage_write",
+	"offered_tps",
+	"confirmed_tps",
+}
+
+// Phase is a period of constant offered load.
+type Phase struct {
+	Name          string
+	Duration      time.Duration
+	TxsPerAccount int // per second
+}
+
+// ParsePhases parses a comma separated list of name:duration:txsPerAccount
+// entries, e.g. "quiet:30s:1,surge:1m:50".
+func ParsePhases(spec string) ([]Phase, error) {
+	var phases []Phase
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid phase %q: expected name:duration:txsPerAccount", entry)
+		}
+		d, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid duration for phase %q", err, parts[0])
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("phase %q must have a positive duration", parts[0])
+		}
+		rate, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid txsPerAccount for phase %q", err, parts[0])
+		}
+		if rate < 0 {
+			return nil, fmt.Errorf("phase %q must have a non-negative txsPerAccount", parts[0])
+		}
+		phases = append(phases, Phase{Name: parts[0], Duration: d, TxsPerAccount: rate})
+	}
+	return phases, nil
+}
+
+// Schedule tracks which phase is active.
+type Schedule struct {
+	phases []Phase
+
+	l     sync.RWMutex
+	start time.Time
+}
+
+func NewSchedule(phases []Phase) *Schedule {
+	return &Schedule{phases: phases}
+}
+
+// Start begins the first phase at [t].
+func (s *Schedule) Start(t time.Time) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.start = t
+}
+
+// Current returns the phase active at [t]. It returns false once every
+// phase has completed (or if the schedule was not started).
+func (s *Schedule) Current(t time.Time) (Phase, bool) {
+	s.l.RLock()
+	start := s.start
+	s.l.RUnlock()
+
+	if start.IsZero() {
+		return Phase{}, false
+	}
+	elapsed := t.Sub(start)
+	for _, phase := range s.phases {
+		if elapsed < phase.Duration {
+			return phase, true
+		}
+		elapsed -= phase.Duration
+	}
+	return Phase{}, false
+}
+
+// TxsPerAccount is the number of txs each account should send this second.
+func (s *Schedule) TxsPerAccount() int {
+	phase, ok := s.Current(time.Now())
+	if !ok {
+		return 0
+	}
+	return phase.TxsPerAccount
+}
+
+// Source is how the recorder reads the spammer's state.
+type Source struct {
+	UnitPrices func(context.Context) (fees.Dimensions, error)
+	Issued     func() uint64
+	Confirmed  func() uint64
+}
+
+// Recorder samples unit prices and load at a fixed interval.
+type Recorder struct {
+	schedule *Schedule
+	interval time.Duration
+	source   Source
+
+	f *os.File
+	w *csv.Writer
+}
+
+// NewRecorder writes samples to a CSV file at [path].
+func NewRecorder(path string, interval time.Duration, schedule *Schedule, source Source) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := csv.NewWriter(f)
+	if err := w.Write(csvHeader); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &Recorder{
+		schedule: schedule,
+		interval: interval,
+		source:   source,
+		f:        f,
+		w:        w,
+	}, nil
+}
+
+// Run starts the schedule and samples until every phase has completed or
+// [ctx] is done.
+func (r *Recorder) Run(ctx context.Context) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	start := time.Now()
+	r.schedule.Start(start)
+	last := start
+	lastIssued, lastConfirmed := r.source.Issued(), r.source.Confirmed()
+	current := ""
+	for {
+		select {
+		case now := <-t.C:
+			phase, ok := r.schedule.Current(now)
+			if !ok {
+				return
+			}
+			if phase.Name != current {
+				utils.Outf("{{yellow}}fee market phase:{{/}} %s {{yellow}}txs/account:{{/}} %d\n", phase.Name, phase.TxsPerAccount)
+				current = phase.Name
+			}
+			prices, err := r.source.UnitPrices(ctx)
+			if err != nil {
+				utils.Outf("{{orange}}failed to fetch unit prices:{{/}} %v\n", err)
+				continue
+			}
+			issued, confirmed := r.source.Issued(), r.source.Confirmed()
+			elapsed := now.Sub(last).Seconds()
+			record := []string{
+				now.Format(time.RFC3339Nano),
+				strconv.FormatFloat(now.Sub(start).Seconds(), 'f', 3, 64),
+				phase.Name,
+				strconv.Itoa(phase.TxsPerAccount),
+			}
+			for _, p := range prices {
+				record = append(record, strconv.FormatUint(p, 10))
+			}
+			record = append(
+				record,
+				strconv.FormatFloat(float64(issued-lastIssued)/elapsed, 'f', 2, 64),
+				strconv.FormatFloat(float64(confirmed-lastConfirmed)/elapsed, 'f', 2, 64),
+			)
+			if err := r.w.Write(record); err != nil {
+				utils.Outf("{{orange}}failed to write fee market sample:{{/}} %v\n", err)
+			}
+			r.w.Flush()
+			last, lastIssued, lastConfirmed = now, issued, confirmed
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close flushes and closes the output file.
+func (r *Recorder) Close() error {
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		_ = r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
@@ -0,0 +1,252 @@
+// Package reconcile compares the balances a spammer predicted for its
+// accounts with what the chain reports at the end of a run, and explains the
+// difference using the tx results seen during the run.
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/utils"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+type issuedTx struct {
+	from      codec.Address
+	maxFee    uint64
+	debit     uint64 // what the spammer subtracted from its local balance
+	transfers map[codec.Address]uint64
+}
+
+type account struct {
+	start uint64
+
+	included int
+	failed   int
+	dropped  int
+
+	feeRefund   int64 // max fee charged minus fee consumed
+	dropCharge  int64 // debits of txs that never executed
+	failedValue int64 // value debited for txs that failed on-chain
+	received    int64 // value received from other accounts
+	misestimate int64 // debit minus what an executed tx actually moved
+}
+
+// Ledger tracks every tx the spammer issued until it is resolved.
+type Ledger struct {
+	l        sync.Mutex
+	accounts map[codec.Address]*account
+	pending  map[ids.ID]*issuedTx
+}
+
+func New() *Ledger {
+	return &Ledger{
+		accounts: map[codec.Address]*account{},
+		pending:  map[ids.ID]*issuedTx{},
+	}
+}
+
+// Fund records the starting balance of an account.
+func (l *Ledger) Fund(addr codec.Address, amount uint64) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	l.accounts[addr] = &account{start: amount}
+}
+
+// Issued records a tx sent by [from]. [maxFee] is the fee the tx was
+// generated with and [debit] is what the spammer subtracted from its local
+// balance for it.
+func (l *Ledger) Issued(txID ids.ID, from codec.Address, maxFee uint64, debit uint64, transfers map[codec.Address]uint64) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	l.pending[txID] = &issuedTx{
+		from:      from,
+		maxFee:    maxFee,
+		debit:     debit,
+		transfers: transfers,
+	}
+}
+
+// Resolved records the outcome of a tx. A nil [result] means the tx never
+// executed (it expired or was rejected before execution).
+func (l *Ledger) Resolved(txID ids.ID, result *chain.Result) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	tx, ok := l.pending[txID]
+	if !ok {
+		return
+	}
+	delete(l.pending, txID)
+	acct, ok := l.accounts[tx.from]
+	if !ok {
+		return
+	}
+	if result == nil {
+		acct.dropped++
+		acct.dropCharge += int64(tx.debit)
+		return
+	}
+	acct.feeRefund += int64(tx.maxFee) - int64(result.Fee)
+	if !result.Success {
+		acct.failed++
+		acct.failedValue += int64(tx.debit) - int64(tx.maxFee)
+		return
+	}
+	acct.included++
+	moved := uint64(0)
+	for to, amount := range tx.transfers {
+		moved += amount
+		if recipient, ok := l.accounts[to]; ok {
+			recipient.received += int64(amount)
+		}
+	}
+	acct.misestimate += int64(tx.debit) - int64(tx.maxFee) - int64(moved)
+}
+
+// Entry is the reconciliation of a single account.
+type Entry struct {
+	Address   string `json:"address"`
+	Start     uint64 `json:"start"`
+	Predicted uint64 `json:"predicted"`
+	Actual    uint64 `json:"actual"`
+	Diff      int64  `json:"diff"`
+
+	// Causes of [Diff]
+	FeeRefund   int64 `json:"feeRefund"`
+	Dropped     int64 `json:"dropped"`
+	FailedValue int64 `json:"failedValue"`
+	Received    int64 `json:"received"`
+	Misestimate int64 `json:"misestimate"`
+	Pending     int64 `json:"pending"`
+	Unexplained int64 `json:"unexplained"`
+
+	IncludedTxs int `json:"includedTxs"`
+	FailedTxs   int `json:"failedTxs"`
+	DroppedTxs  int `json:"droppedTxs"`
+	PendingTxs  int `json:"pendingTxs"`
+
+	Flagged bool `json:"flagged"`
+}
+
+type Report struct {
+	Accounts []*Entry `json:"accounts"`
+	Flagged  int      `json:"flagged"`
+}
+
+// Reconcile queries the on-chain balance of every funded account and
+// compares it with [predicted].
+func (l *Ledger) Reconcile(
+	ctx context.Context,
+	hrp string,
+	predicted map[codec.Address]uint64,
+	balance func(context.Context, codec.Address) (uint64, error),
+) (*Report, error) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	pendingTxs := map[codec.Address]int{}
+	pendingCharge := map[codec.Address]int64{}
+	for _, tx := range l.pending {
+		pendingTxs[tx.from]++
+		pendingCharge[tx.from] += int64(tx.debit)
+	}
+	report := &Report{}
+	for addr, acct := range l.accounts {
+		actual, err := balance(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		saddr, err := codec.AddressBech32(hrp, addr)
+		if err != nil {
+			return nil, err
+		}
+		e := &Entry{
+			Address:     saddr,
+			Start:       acct.start,
+			Predicted:   predicted[addr],
+			Actual:      actual,
+			Diff:        int64(actual) - int64(predicted[addr]),
+			FeeRefund:   acct.feeRefund,
+			Dropped:     acct.dropCharge,
+			FailedValue: acct.failedValue,
+			Received:    acct.received,
+			Misestimate: acct.misestimate,
+			Pending:     pendingCharge[addr],
+			IncludedTxs: acct.included,
+			FailedTxs:   acct.failed,
+			DroppedTxs:  acct.dropped,
+			PendingTxs:  pendingTxs[addr],
+		}
+		e.Unexplained = e.Diff - e.FeeRefund - e.Dropped - e.FailedValue - e.Received - e.Misestimate
+		// Txs we never heard back about may or may not have executed, so we
+		// only flag drift they cannot account for.
+		if e.Unexplained != 0 && (e.PendingTxs == 0 || e.Unexplained < 0 || e.Unexplained > e.Pending) {
+			e.Flagged = true
+			report.Flagged++
+		}
+		report.Accounts = append(report.Accounts, e)
+	}
+	sort.Slice(report.Accounts, func(i, j int) bool {
+		return report.Accounts[i].Address < report.Accounts[j].Address
+	})
+	return report, nil
+}
+
+// Print writes the report to stdout.
+func (r *Report) Print() {
+	for _, e := range r.Accounts {
+		color := "green"
+		if e.Flagged {
+			color = "orange"
+		}
+		utils.Outf(
+			"{{%s}}%s{{/}} predicted=%d actual=%d diff=%d {{yellow}}fee refund:{{/}} %d {{yellow}}dropped:{{/}} %d {{yellow}}failed value:{{/}} %d {{yellow}}received:{{/}} %d {{yellow}}misestimate:{{/}} %d {{yellow}}pending:{{/}} %d {{yellow}}unexplained:{{/}} %d\n", //nolint:lll
+			color,
+			e.Address,
+			e.Predicted,
+			e.Actual,
+			e.Diff,
+			e.FeeRefund,
+			e.Dropped,
+			e.FailedValue,
+			e.Received,
+			e.Misestimate,
+			e.Pending,
+			e.Unexplained,
+		)
+	}
+	if r.Flagged > 0 {
+		utils.Outf("{{orange}}%d/%d accounts have unexplained drift{{/}}\n", r.Flagged, len(r.Accounts))
+	} else {
+		utils.Outf("{{green}}all %d accounts reconciled{{/}}\n", len(r.Accounts))
+	}
+}
+
+// Write stores the report as JSON at [path].
+func (r *Report) Write(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// Transfers returns the value each recipient receives from [acts].
+func Transfers(acts []chain.Action) map[codec.Address]uint64 {
+	transfers := map[codec.Address]uint64{}
+	for _, act := range acts {
+		if t, ok := act.(*actions.Transfer); ok && t.Asset == ids.Empty {
+			transfers[t.To] += t.Value
+		}
+	}
+	return transfers
+}
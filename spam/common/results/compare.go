@@ -0,0 +1,348 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/utils"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+var dimensions = []struct {
+	name      string
+	dimension fees.Dimension
+}{
+	{"bandwidth", fees.Bandwidth},
+	{"compute", fees.Compute},
+	{"storage(read)", fees.StorageRead},
+	{"storage(allocate)", fees.StorageAllocate},
+	{"storage(write)", fees.StorageWrite},
+}
+
+// Options decide when a difference counts as a regression.
+type Options struct {
+	// Alpha is the significance level of every test
+	Alpha float64
+	// Threshold is the smallest relative change worth flagging, e.g. 0.05
+	Threshold float64
+}
+
+// Metric is one number compared between a baseline and a run.
+type Metric struct {
+	Name string  `json:"name"`
+	Base float64 `json:"base"`
+	Run  float64 `json:"run"`
+	Unit string  `json:"unit"`
+	// Change is relative to the baseline, NaN if the baseline is 0
+	Change float64 `json:"change"`
+	// PValue is NaN if the metric can't be tested
+	PValue     float64 `json:"pValue"`
+	Test       string  `json:"test,omitempty"`
+	Regression bool    `json:"regression"`
+	Improved   bool    `json:"improved"`
+
+	higherIsBetter bool
+}
+
+type Comparison struct {
+	Base string `json:"base"`
+	Run  string `json:"run"`
+	// Notes describe differences in setup that make the comparison weaker
+	Notes   []string  `json:"notes"`
+	Metrics []*Metric `json:"metrics"`
+}
+
+// Compare compares [run] with [base].
+func Compare(base, run *Run, opts Options) *Comparison {
+	c := &Comparison{Base: base.ID, Run: run.ID, Notes: setupNotes(base, run)}
+
+	baseTPS, runTPS := series(base, confirmedTPS), series(run, confirmedTPS)
+	c.add(opts, &Metric{
+		Name:           "confirmed tps",
+		Base:           base.Summary.ConfirmedTPS,
+		Run:            run.Summary.ConfirmedTPS,
+		Unit:           "tx/s",
+		PValue:         welch(baseTPS, runTPS),
+		Test:           "welch t-test on per-second confirmed txs",
+		higherIsBetter: true,
+	})
+	c.add(opts, &Metric{
+		Name:           "issued tps",
+		Base:           base.Summary.IssuedTPS,
+		Run:            run.Summary.IssuedTPS,
+		Unit:           "tx/s",
+		PValue:         welch(series(base, issuedTPS), series(run, issuedTPS)),
+		Test:           "welch t-test on per-second issued txs",
+		higherIsBetter: true,
+	})
+	c.add(opts, &Metric{
+		Name:           "success rate",
+		Base:           base.Summary.SuccessRate * 100,
+		Run:            run.Summary.SuccessRate * 100,
+		Unit:           "%",
+		PValue:         twoProportions(base.Summary.Confirmed, attempts(base), run.Summary.Confirmed, attempts(run)),
+		Test:           "two-proportion z-test",
+		higherIsBetter: true,
+	})
+
+	// Percentiles have no test of their own; they regress only if the
+	// latency distribution as a whole shifted
+	latencyP := mannWhitney(base.Latency, run.Latency)
+	c.add(opts, &Metric{
+		Name:   "latency mean",
+		Base:   ms(base.Summary.Latency.Mean),
+		Run:    ms(run.Summary.Latency.Mean),
+		Unit:   "ms",
+		PValue: welch(series(base, meanLatency), series(run, meanLatency)),
+		Test:   "welch t-test on per-second mean latency",
+	})
+	for _, q := range []struct {
+		name      string
+		base, run time.Duration
+	}{
+		{"latency p50", base.Summary.Latency.P50, run.Summary.Latency.P50},
+		{"latency p90", base.Summary.Latency.P90, run.Summary.Latency.P90},
+		{"latency p99", base.Summary.Latency.P99, run.Summary.Latency.P99},
+	} {
+		c.add(opts, &Metric{
+			Name:   q.name,
+			Base:   ms(q.base),
+			Run:    ms(q.run),
+			Unit:   "ms",
+			PValue: latencyP,
+			Test:   "mann-whitney u-test on the latency histograms",
+		})
+	}
+
+	c.add(opts, &Metric{
+		Name:   "fee per tx",
+		Base:   perTx(base.Fee, base),
+		Run:    perTx(run.Fee, run),
+		Unit:   "fee",
+		PValue: welch(series(base, feePerTx), series(run, feePerTx)),
+		Test:   "welch t-test on per-second fee per confirmed tx",
+	})
+	for _, d := range dimensions {
+		// Units are fixed by the tx shape, so any change is reported as is
+		c.add(opts, &Metric{
+			Name:   "units per tx: " + d.name,
+			Base:   perTx(base.Units[d.dimension], base),
+			Run:    perTx(run.Units[d.dimension], run),
+			Unit:   "units",
+			PValue: math.NaN(),
+		})
+	}
+
+	categories := map[stats.Category]bool{}
+	for k := range base.Summary.Failures {
+		categories[k] = true
+	}
+	for k := range run.Summary.Failures {
+		categories[k] = true
+	}
+	names := make([]string, 0, len(categories))
+	for k := range categories {
+		names = append(names, string(k))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		k := stats.Category(name)
+		b, r := base.Summary.Failures[k], run.Summary.Failures[k]
+		c.add(opts, &Metric{
+			Name:   "failures: " + name,
+			Base:   rate(b, attempts(base)),
+			Run:    rate(r, attempts(run)),
+			Unit:   "%",
+			PValue: twoProportions(b, attempts(base), r, attempts(run)),
+			Test:   "two-proportion z-test",
+		})
+	}
+	return c
+}
+
+// add classifies [m]. A metric regressed if it got worse by at least the
+// threshold and the test says the change is unlikely to be noise. Failure
+// rates start near zero, so any significant increase counts.
+func (c *Comparison) add(opts Options, m *Metric) {
+	m.Change = math.NaN()
+	if m.Base != 0 {
+		m.Change = (m.Run - m.Base) / math.Abs(m.Base)
+	}
+	significant := !math.IsNaN(m.PValue) && m.PValue < opts.Alpha
+	large := math.Abs(m.Change) >= opts.Threshold || (math.IsNaN(m.Change) && m.Run != m.Base)
+	worse := m.Run < m.Base
+	if !m.higherIsBetter {
+		worse = m.Run > m.Base
+	}
+	if significant && large {
+		m.Regression = worse
+		m.Improved = !worse
+	}
+	c.Metrics = append(c.Metrics, m)
+}
+
+// Regressions returns every metric that regressed.
+func (c *Comparison) Regressions() []*Metric {
+	var regressions []*Metric
+	for _, m := range c.Metrics {
+		if m.Regression {
+			regressions = append(regressions, m)
+		}
+	}
+	return regressions
+}
+
+func (c *Comparison) Print() {
+	utils.Outf("{{cyan}}base:{{/}} %s {{cyan}}run:{{/}} %s\n", c.Base, c.Run)
+	for _, n := range c.Notes {
+		utils.Outf("{{yellow}}note:{{/}} %s\n", n)
+	}
+	for _, m := range c.Metrics {
+		change := "n/a"
+		if !math.IsNaN(m.Change) {
+			change = fmt.Sprintf("%+.2f%%", m.Change*100)
+		}
+		p := "-"
+		if !math.IsNaN(m.PValue) {
+			p = fmt.Sprintf("%.4f", m.PValue)
+		}
+		verdict := ""
+		switch {
+		case m.Regression:
+			verdict = "{{red}}REGRESSION{{/}}"
+		case m.Improved:
+			verdict = "{{green}}improved{{/}}"
+		}
+		utils.Outf(
+			"{{yellow}}%-26s{{/}} %12.3f -> %12.3f %-5s %9s p=%-7s %s\n",
+			m.Name,
+			m.Base,
+			m.Run,
+			m.Unit,
+			change,
+			p,
+			verdict,
+		)
+	}
+	if n := len(c.Regressions()); n > 0 {
+		utils.Outf("{{red}}%d regressions{{/}}\n", n)
+	} else {
+		utils.Outf("{{green}}no regressions{{/}}\n")
+	}
+}
+
+// Write stores the comparison as JSON at [path]. NaNs are written as null.
+func (c *Comparison) Write(path string) error {
+	type metric struct {
+		*Metric
+		Change *float64 `json:"change"`
+		PValue *float64 `json:"pValue"`
+	}
+	metrics := make([]metric, len(c.Metrics))
+	for i, m := range c.Metrics {
+		metrics[i] = metric{Metric: m, Change: finite(m.Change), PValue: finite(m.PValue)}
+	}
+	b, err := json.MarshalIndent(struct {
+		*Comparison
+		Metrics []metric `json:"metrics"`
+	}{c, metrics}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+func finite(f float64) *float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
+
+// setupNotes lists differences between the runs other than the code under
+// test.
+func setupNotes(base, run *Run) []string {
+	var notes []string
+	if base.Tool != run.Tool {
+		notes = append(notes, fmt.Sprintf("different tools: %s vs %s", base.Tool, run.Tool))
+	}
+	if bv, rv := base.SEQVersion(), run.SEQVersion(); bv != rv {
+		notes = append(notes, fmt.Sprintf("SEQ version: %s vs %s", bv, rv))
+	}
+	if base.Environment != nil && run.Environment != nil {
+		be, re := base.Environment, run.Environment
+		if be.ClusterSize != re.ClusterSize {
+			notes = append(notes, fmt.Sprintf("cluster size: %d vs %d", be.ClusterSize, re.ClusterSize))
+		}
+		if be.ValidityWindow != re.ValidityWindow {
+			notes = append(notes, fmt.Sprintf("validity window: %dms vs %dms", be.ValidityWindow, re.ValidityWindow))
+		}
+		if be.MaxBlockUnits != re.MaxBlockUnits {
+			notes = append(notes, fmt.Sprintf("max block units: %v vs %v", be.MaxBlockUnits, re.MaxBlockUnits))
+		}
+	}
+	names := make([]string, 0, len(run.Flags))
+	for name := range run.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if b, ok := base.Flags[name]; ok && b != run.Flags[name] {
+			notes = append(notes, fmt.Sprintf("--%s: %q vs %q", name, b, run.Flags[name]))
+		}
+	}
+	if len(base.Series) < 2 || len(run.Series) < 2 {
+		notes = append(notes, "too few per-second samples to test throughput and mean latency")
+	}
+	return notes
+}
+
+func series(r *Run, f func(Sample) (float64, bool)) []float64 {
+	values := make([]float64, 0, len(r.Series))
+	for _, s := range r.Series {
+		if v, ok := f(s); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func confirmedTPS(s Sample) (float64, bool) { return float64(s.Confirmed), true }
+
+func issuedTPS(s Sample) (float64, bool) { return float64(s.Issued), true }
+
+func meanLatency(s Sample) (float64, bool) { return ms(s.Latency), s.Confirmed > 0 }
+
+func feePerTx(s Sample) (float64, bool) {
+	return float64(s.Fee) / float64(s.Confirmed), s.Confirmed > 0
+}
+
+// attempts is every tx the run tried to send, as in the summary's success
+// rate.
+func attempts(r *Run) uint64 {
+	return r.Summary.Issued + r.Summary.Failures[stats.Unsent] + r.Summary.Failures[stats.Unroutable]
+}
+
+func perTx(total uint64, r *Run) float64 {
+	if r.Summary.Confirmed == 0 {
+		return 0
+	}
+	return float64(total) / float64(r.Summary.Confirmed)
+}
+
+func rate(n, of uint64) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of) * 100
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,139 @@
+package results
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+	"github.com/AnomalyFi/tools/spam/common/summary"
+)
+
+var testOptions = Options{Alpha: 0.05, Threshold: 0.05}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name           string
+		base, run      float64
+		pValue         float64
+		higherIsBetter bool
+		regression     bool
+		improved       bool
+	}{
+		{"worse and significant", 100, 90, 0.01, true, true, false},
+		{"better and significant", 100, 110, 0.01, true, false, true},
+		{"lower is better", 100, 110, 0.01, false, true, false},
+		{"not significant", 100, 50, 0.2, true, false, false},
+		{"below the threshold", 100, 97, 0.001, true, false, false},
+		{"untested", 100, 50, math.NaN(), true, false, false},
+		// Failure rates start at zero, where the change is undefined
+		{"from zero", 0, 2, 0.001, false, true, false},
+		{"still zero", 0, 0, 1, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Comparison{}
+			c.add(testOptions, &Metric{Base: tt.base, Run: tt.run, PValue: tt.pValue, higherIsBetter: tt.higherIsBetter})
+			m := c.Metrics[0]
+			if m.Regression != tt.regression || m.Improved != tt.improved {
+				t.Fatalf("got regression %t improved %t, want %t and %t", m.Regression, m.Improved, tt.regression, tt.improved)
+			}
+		})
+	}
+}
+
+// testRun confirms [confirmed] txs per second out of 100 issued, at the
+// same latency, and fails the rest with [failure].
+func testRun(id string, confirmed []uint64, latency time.Duration, failure stats.Category) *Run {
+	r := &Run{ID: id, Tool: "transfer", Latency: stats.NewHistogram()}
+	sum := &summary.Summary{Failures: map[stats.Category]uint64{}}
+	for i, c := range confirmed {
+		r.Series = append(r.Series, Sample{
+			Elapsed:   time.Duration(i+1) * time.Second,
+			Issued:    100,
+			Confirmed: c,
+			Latency:   latency,
+			Fee:       c * 10,
+		})
+		for range c {
+			r.Latency.Record(latency)
+		}
+		sum.Issued += 100
+		sum.Confirmed += c
+		sum.Failures[failure] += 100 - c
+		r.Fee += c * 10
+	}
+	seconds := float64(len(confirmed))
+	sum.SuccessRate = float64(sum.Confirmed) / float64(sum.Issued)
+	sum.IssuedTPS = float64(sum.Issued) / seconds
+	sum.ConfirmedTPS = float64(sum.Confirmed) / seconds
+	sum.Latency = summary.Latency{
+		Mean: latency,
+		P50:  r.Latency.Quantile(0.5),
+		P90:  r.Latency.Quantile(0.9),
+		P99:  r.Latency.Quantile(0.99),
+	}
+	r.Summary = sum
+	return r
+}
+
+func TestCompare(t *testing.T) {
+	steady := []uint64{90, 92, 88, 91, 89, 90, 92, 88, 91, 89}
+	tests := []struct {
+		name        string
+		base, run   *Run
+		regressions []string
+		improved    []string
+	}{
+		{
+			name: "same run",
+			base: testRun("a", steady, 200*time.Millisecond, stats.Expired),
+			run:  testRun("b", steady, 200*time.Millisecond, stats.Expired),
+		},
+		{
+			name: "fewer confirmed",
+			base: testRun("a", steady, 200*time.Millisecond, stats.Expired),
+			run:  testRun("b", []uint64{70, 72, 68, 71, 69, 70, 72, 68, 71, 69}, 200*time.Millisecond, stats.Expired),
+			regressions: []string{
+				"confirmed tps",
+				"success rate",
+				"failures: expired",
+			},
+		},
+		{
+			name:     "faster",
+			base:     testRun("a", steady, 400*time.Millisecond, stats.Expired),
+			run:      testRun("b", steady, 200*time.Millisecond, stats.Expired),
+			improved: []string{"latency mean", "latency p50", "latency p90", "latency p99"},
+		},
+		{
+			name: "new failure",
+			base: testRun("a", steady, 200*time.Millisecond, stats.Expired),
+			run:  testRun("b", steady, 200*time.Millisecond, stats.Rejected),
+			// The expired failures went away as the rejected ones appeared
+			regressions: []string{"failures: pre-execute failure"},
+			improved:    []string{"failures: expired"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compare(tt.base, tt.run, testOptions)
+			var regressions, improved []string
+			for _, m := range c.Metrics {
+				if m.Regression {
+					regressions = append(regressions, m.Name)
+				}
+				if m.Improved {
+					improved = append(improved, m.Name)
+				}
+			}
+			if !reflect.DeepEqual(regressions, tt.regressions) {
+				t.Errorf("got regressions %q, want %q", regressions, tt.regressions)
+			}
+			if !reflect.DeepEqual(improved, tt.improved) {
+				t.Errorf("got improvements %q, want %q", improved, tt.improved)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Package results keeps the configuration, environment and outcome of every
+// spam run in a JSON-lines file so runs can be compared with each other or
+// with a baseline later.
+package results
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+	"github.com/AnomalyFi/tools/spam/common/summary"
+)
+
+// Environment is what the run was pointed at.
+type Environment struct {
+	NetworkID   uint32   `json:"networkId"`
+	ChainID     ids.ID   `json:"chainId"`
+	URIs        []string `json:"uris"`
+	ClusterSize int      `json:"clusterSize"`
+
+	// Versions are reported by the first node
+	NodeVersion string            `json:"nodeVersion"`
+	GitCommit   string            `json:"gitCommit"`
+	VMVersions  map[string]string `json:"vmVersions"`
+
+	ValidityWindow int64           `json:"validityWindow"`
+	MaxBlockUnits  fees.Dimensions `json:"maxBlockUnits"`
+	// UnitPrices are the prices when the run started
+	UnitPrices fees.Dimensions `json:"unitPrices"`
+}
+
+// NewEnvironment records the chain [uris] serve and its rules at start.
+func NewEnvironment(uris []string, networkID uint32, chainID ids.ID, rules chain.Rules, unitPrices fees.Dimensions) *Environment {
+	return &Environment{
+		NetworkID:      networkID,
+		ChainID:        chainID,
+		URIs:           uris,
+		ClusterSize:    len(uris),
+		ValidityWindow: rules.GetValidityWindow(),
+		MaxBlockUnits:  rules.GetMaxBlockUnits(),
+		UnitPrices:     unitPrices,
+	}
+}
+
+// LoadVersions asks the first node for its avalanchego and VM versions.
+func (e *Environment) LoadVersions(ctx context.Context) error {
+	if len(e.URIs) == 0 {
+		return nil
+	}
+	nodeURL, _, _ := strings.Cut(e.URIs[0], "/ext/bc/")
+	reply, err := info.NewClient(nodeURL).GetNodeVersion(ctx)
+	if err != nil {
+		return err
+	}
+	e.NodeVersion = reply.Version
+	e.GitCommit = reply.GitCommit
+	e.VMVersions = reply.VMVersions
+	return nil
+}
+
+// Sample is what happened during one second of the run.
+type Sample struct {
+	Elapsed   time.Duration `json:"elapsed"`
+	Issued    uint64        `json:"issued"`
+	Confirmed uint64        `json:"confirmed"`
+	Errors    uint64        `json:"errors"`
+	// Latency is the mean confirmation latency, 0 if nothing confirmed
+	Latency time.Duration `json:"latency"`
+	Fee     uint64        `json:"fee"`
+}
+
+type Run struct {
+	ID    string            `json:"id"`
+	Tool  string            `json:"tool"`
+	Tags  []string          `json:"tags,omitempty"`
+	Start time.Time         `json:"start"`
+	Flags map[string]string `json:"flags"`
+
+	Environment *Environment     `json:"environment"`
+	Summary     *summary.Summary `json:"summary"`
+	Latency     *stats.Histogram `json:"latency"`
+	Fee         uint64           `json:"fee"`
+	Units       fees.Dimensions  `json:"units"`
+	Series      []Sample         `json:"series"`
+}
+
+// NewRun builds the record of a finished run started with [flags]. [final]
+// is the snapshot taken after the drain.
+func NewRun(
+	tool string,
+	tags []string,
+	flags map[string]string,
+	env *Environment,
+	sum *summary.Summary,
+	final *stats.Snapshot,
+	series []Sample,
+) *Run {
+	start := final.Time.Add(-final.Elapsed)
+	return &Run{
+		ID:          fmt.Sprintf("%s-%s", start.UTC().Format("20060102T150405"), tool),
+		Tool:        tool,
+		Tags:        tags,
+		Start:       start,
+		Flags:       flags,
+		Environment: env,
+		Summary:     sum,
+		Latency:     final.Latency,
+		Fee:         final.Fee,
+		Units:       final.Units,
+		Series:      series,
+	}
+}
+
+// Flags returns the value of every flag in [fs], set or not.
+func Flags(fs *flag.FlagSet) map[string]string {
+	flags := map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
+
+// HasTag reports whether the run was tagged [tag].
+func (r *Run) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SEQVersion is the version the chain's VM reported, or the node version if
+// it reported none.
+func (r *Run) SEQVersion() string {
+	if r.Environment == nil {
+		return "unknown"
+	}
+	versions := make([]string, 0, len(r.Environment.VMVersions))
+	for vm, v := range r.Environment.VMVersions {
+		versions = append(versions, vm+"@"+v)
+	}
+	if len(versions) == 0 {
+		if len(r.Environment.NodeVersion) == 0 {
+			return "unknown"
+		}
+		return r.Environment.NodeVersion
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ",")
+}
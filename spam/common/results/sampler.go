@@ -0,0 +1,62 @@
+package results
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+// Source is anything that counts a run, e.g. a [stats.Collector].
+type Source interface {
+	Snapshot() *stats.Snapshot
+}
+
+// Sampler records what the source saw every second, so runs can be compared
+// second by second instead of only on their totals.
+type Sampler struct {
+	source Source
+
+	l       sync.Mutex
+	samples []Sample
+}
+
+func NewSampler(source Source) *Sampler {
+	return &Sampler{source: source}
+}
+
+// Run samples until [ctx] is done. Only seconds spent issuing are recorded;
+// the drain would drag every per-second series down.
+func (s *Sampler) Run(ctx context.Context) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	prev := s.source.Snapshot()
+	for {
+		select {
+		case <-t.C:
+			current := s.source.Snapshot()
+			delta := current.Sub(prev)
+			s.l.Lock()
+			s.samples = append(s.samples, Sample{
+				Elapsed:   current.Elapsed,
+				Issued:    delta.Issued,
+				Confirmed: delta.Outcomes[stats.Confirmed],
+				Errors:    delta.Errors(),
+				Latency:   delta.Latency.Mean(),
+				Fee:       delta.Fee,
+			})
+			s.l.Unlock()
+			prev = current
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Sampler) Samples() []Sample {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	return append([]Sample(nil), s.samples...)
+}
@@ -0,0 +1,143 @@
+package results
+
+import (
+	"math"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+// welch runs Welch's t-test on two samples and returns the two-sided p-value.
+// It returns NaN if either sample has fewer than two values.
+func welch(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return math.NaN()
+	}
+	ma, va := meanVar(a)
+	mb, vb := meanVar(b)
+	sa, sb := va/float64(len(a)), vb/float64(len(b))
+	if sa+sb == 0 {
+		if ma == mb {
+			return 1
+		}
+		return 0
+	}
+	t := (ma - mb) / math.Sqrt(sa+sb)
+	df := (sa + sb) * (sa + sb) / (sa*sa/float64(len(a)-1) + sb*sb/float64(len(b)-1))
+	return studentTwoSided(t, df)
+}
+
+func meanVar(x []float64) (float64, float64) {
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	mean := sum / float64(len(x))
+	var ss float64
+	for _, v := range x {
+		ss += (v - mean) * (v - mean)
+	}
+	return mean, ss / float64(len(x)-1)
+}
+
+// studentTwoSided is P(|T| >= |t|) for Student's t with [df] degrees of
+// freedom.
+func studentTwoSided(t, df float64) float64 {
+	return incompleteBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// incompleteBeta is the regularized incomplete beta function I_x(a, b).
+func incompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges quickly below the mean
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+// betaFraction evaluates the continued fraction of the incomplete beta
+// function with the modified Lentz method.
+func betaFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, num := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
+
+// twoProportions tests whether [x1] of [n1] and [x2] of [n2] come from the
+// same rate and returns the two-sided p-value.
+func twoProportions(x1, n1, x2, n2 uint64) float64 {
+	if n1 == 0 || n2 == 0 {
+		return math.NaN()
+	}
+	p1, p2 := float64(x1)/float64(n1), float64(x2)/float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 1
+	}
+	return math.Erfc(math.Abs(p1-p2) / se / math.Sqrt2)
+}
+
+// mannWhitney tests whether two latency histograms come from the same
+// distribution and returns the two-sided p-value. Both histograms use the
+// same buckets, so samples in one bucket are ties.
+func mannWhitney(a, b *stats.Histogram) float64 {
+	if a == nil || b == nil || a.Count == 0 || b.Count == 0 || len(a.Counts) != len(b.Counts) {
+		return math.NaN()
+	}
+	na, nb := float64(a.Count), float64(b.Count)
+	n := na + nb
+	var u, below, ties float64
+	for i := range a.Counts {
+		ca, cb := float64(a.Counts[i]), float64(b.Counts[i])
+		u += ca*below + ca*cb/2
+		below += cb
+		t := ca + cb
+		ties += t*t*t - t
+	}
+	variance := na * nb / 12 * (n + 1 - ties/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (u - na*nb/2) / math.Sqrt(variance)
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
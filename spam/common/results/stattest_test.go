@@ -0,0 +1,172 @@
+package results
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+// The reference p-values come from an implementation independent of this
+// one: Simpson integration of the t density, exact binomial sums for the
+// incomplete beta and ranks of the expanded samples for Mann-Whitney. They
+// agree with the rounded values Wikipedia publishes for its Welch examples.
+const tolerance = 1e-9
+
+func TestWelch(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{
+			// Wikipedia's Welch's t-test example 1: t = -2.46, df = 24.99
+			name: "equal sizes",
+			a:    []float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4},
+			b:    []float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4},
+			want: 0.021378001462862,
+		},
+		{
+			// Example 2: t = -1.57, df = 9.90
+			name: "unequal sizes and variances",
+			a:    []float64{17.2, 20.9, 22.6, 18.1, 21.7, 21.4, 23.5, 24.2, 14.7, 21.8},
+			b: []float64{
+				21.5, 22.8, 21.0, 23.0, 21.6, 23.6, 22.5, 20.7, 23.4, 21.8,
+				20.7, 21.7, 21.5, 22.5, 23.6, 21.5, 22.5, 23.5, 21.5, 21.8,
+			},
+			want: 0.148841696605326,
+		},
+		{
+			name: "constant and equal",
+			a:    []float64{3, 3, 3},
+			b:    []float64{3, 3},
+			want: 1,
+		},
+		{
+			name: "constant and different",
+			a:    []float64{3, 3, 3},
+			b:    []float64{4, 4},
+			want: 0,
+		},
+		{
+			name: "too few samples",
+			a:    []float64{1},
+			b:    []float64{1, 2, 3},
+			want: math.NaN(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkP(t, welch(tt.a, tt.b), tt.want)
+			// The test is two-sided
+			checkP(t, welch(tt.b, tt.a), tt.want)
+		})
+	}
+}
+
+func TestStudentTwoSided(t *testing.T) {
+	tests := []struct {
+		t, df float64
+		want  float64
+	}{
+		// Cauchy: 1 - 2/pi * atan(t)
+		{1, 1, 0.5},
+		{math.Sqrt(3), 1, 1.0 / 3},
+		// 1 - t/sqrt(2 + t^2)
+		{1, 2, 1 - 1/math.Sqrt(3)},
+		{1, 3, 0.391002218955771},
+		{2, 10, 0.073388034770746},
+		{-2, 10, 0.073388034770746},
+		{0, 10, 1},
+	}
+	for _, tt := range tests {
+		checkP(t, studentTwoSided(tt.t, tt.df), tt.want)
+	}
+}
+
+func TestIncompleteBeta(t *testing.T) {
+	tests := []struct {
+		a, b, x float64
+		want    float64
+	}{
+		{1, 1, 0.3, 0.3},
+		{2, 3, 0.4, 0.5248},
+		{5, 5, 0.3, 0.09880866},
+		{5, 5, 0.5, 0.5},
+		{10, 2, 0.9, 0.6973568802},
+		{1, 7, 0.05, 0.30166270390625},
+		{30, 20, 0.55, 0.232888606786569},
+		{2, 3, 0, 0},
+		{2, 3, 1, 1},
+	}
+	for _, tt := range tests {
+		checkP(t, incompleteBeta(tt.a, tt.b, tt.x), tt.want)
+	}
+}
+
+func TestTwoProportions(t *testing.T) {
+	tests := []struct {
+		name           string
+		x1, n1, x2, n2 uint64
+		want           float64
+	}{
+		// X-squared = 4.8
+		{"moderate rates", 45, 100, 30, 100, 0.028459736916311},
+		// X-squared = 13.32
+		{"rates near one", 980, 1000, 950, 1000, 0.000262105510999},
+		{"same rate", 5, 20, 5, 20, 1},
+		{"both perfect", 10, 10, 20, 20, 1},
+		{"empty", 0, 0, 5, 10, math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkP(t, twoProportions(tt.x1, tt.n1, tt.x2, tt.n2), tt.want)
+		})
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []uint64
+		want float64
+	}{
+		// U = 14 of 100 pairs, with ties in every bucket
+		{"shifted", []uint64{3, 5, 2, 0}, []uint64{0, 2, 5, 3}, 0.004329533699229},
+		{"similar", []uint64{10, 20, 15, 5, 0}, []uint64{8, 22, 14, 6, 0}, 0.770615833195524},
+		// Every sample of [a] is below every sample of [b]
+		{"disjoint", []uint64{1, 1, 1, 1, 1, 0, 0, 0, 0, 0}, []uint64{0, 0, 0, 0, 0, 1, 1, 1, 1, 1}, 0.009023438818080},
+		{"one bucket", []uint64{0, 4, 0}, []uint64{0, 6, 0}, 1},
+		{"empty", []uint64{0, 0}, []uint64{1, 0}, math.NaN()},
+		{"different buckets", []uint64{1}, []uint64{1, 0}, math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := histogram(tt.a), histogram(tt.b)
+			checkP(t, mannWhitney(a, b), tt.want)
+			checkP(t, mannWhitney(b, a), tt.want)
+		})
+	}
+}
+
+func histogram(counts []uint64) *stats.Histogram {
+	h := &stats.Histogram{Counts: counts}
+	for _, c := range counts {
+		h.Count += c
+	}
+	return h
+}
+
+func checkP(t *testing.T, got, want float64) {
+	t.Helper()
+
+	if math.IsNaN(want) {
+		if !math.IsNaN(got) {
+			t.Errorf("got %v, want NaN", got)
+		}
+		return
+	}
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("got %.15f, want %.15f", got, want)
+	}
+}
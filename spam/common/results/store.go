@@ -0,0 +1,109 @@
+package results
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultStore is where the spammers append runs unless told otherwise.
+const DefaultStore = "spam-results.jsonl"
+
+var ErrNoRuns = errors.New("no runs recorded")
+
+// Load reads every run in the store at [path], oldest first.
+func Load(path string) ([]*Run, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []*Run
+	scanner := bufio.NewScanner(f)
+	// Runs carry their per-second series, so lines can be long
+	scanner.Buffer(make([]byte, 0, 1<<20), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var r Run
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		runs = append(runs, &r)
+	}
+	return runs, scanner.Err()
+}
+
+// Append adds [r] to the store at [path], creating it if needed. If another
+// run already has the same ID a suffix is added to keep IDs unique.
+func Append(path string, r *Run) error {
+	runs, err := Load(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	taken := make(map[string]bool, len(runs))
+	for _, o := range runs {
+		taken[o.ID] = true
+	}
+	id := r.ID
+	for i := 2; taken[r.ID]; i++ {
+		r.ID = id + "-" + strconv.Itoa(i)
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Find resolves [ref] against [runs]: "latest" is the newest run,
+// "tag:<name>" the newest run tagged <name>, anything else a run ID or an
+// unambiguous prefix of one.
+func Find(runs []*Run, ref string) (*Run, error) {
+	if len(runs) == 0 {
+		return nil, ErrNoRuns
+	}
+	switch {
+	case ref == "latest":
+		return runs[len(runs)-1], nil
+	case strings.HasPrefix(ref, "tag:"):
+		tag := strings.TrimPrefix(ref, "tag:")
+		for i := len(runs) - 1; i >= 0; i-- {
+			if runs[i].HasTag(tag) {
+				return runs[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no run tagged %q", tag)
+	}
+	var match *Run
+	for _, r := range runs {
+		if r.ID == ref {
+			return r, nil
+		}
+		if strings.HasPrefix(r.ID, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("%q matches %s and %s", ref, match.ID, r.ID)
+			}
+			match = r
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no run %q", ref)
+	}
+	return match, nil
+}
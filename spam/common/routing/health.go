@@ -0,0 +1,207 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/utils"
+)
+
+// latencyDecay is the weight given to a new latency sample.
+const latencyDecay = 0.2
+
+type State int
+
+const (
+	// Closed nodes receive traffic.
+	Closed State = iota
+	// Open nodes have failed too often and receive no traffic.
+	Open
+	// HalfOpen nodes have cooled down and are waiting for a successful probe.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "healthy"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Probe checks whether the node at [uri] is usable.
+type Probe func(ctx context.Context, uri string) error
+
+type node struct {
+	state    State
+	failures int
+	openedAt time.Time
+	latency  float64 // EWMA of tx confirmation latency in ms
+
+	trips int
+}
+
+// Health is a circuit breaker per node URI. It is fed by background probes
+// and by the outcome of txs sent to each node.
+type Health struct {
+	uris      []string
+	threshold int
+	cooldown  time.Duration
+
+	l     sync.Mutex
+	nodes []*node
+}
+
+// NewHealth opens a node's circuit after [threshold] consecutive failures
+// and lets probes close it again once [cooldown] has passed.
+func NewHealth(uris []string, threshold int, cooldown time.Duration) *Health {
+	nodes := make([]*node, len(uris))
+	for i := range nodes {
+		nodes[i] = &node{}
+	}
+	return &Health{
+		uris:      uris,
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		nodes:     nodes,
+	}
+}
+
+// Start probes every node each [interval] until [ctx] is done.
+func (h *Health) Start(ctx context.Context, interval time.Duration, probe Probe) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				for i, uri := range h.uris {
+					pctx, cancel := context.WithTimeout(ctx, interval)
+					err := probe(pctx, uri)
+					cancel()
+					if err != nil {
+						h.Failure(i, err)
+						continue
+					}
+					h.probeSucceeded(i)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Healthy returns true if traffic may be sent to node [uri].
+func (h *Health) Healthy(uri int) bool {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	n := h.nodes[uri]
+	if n.state == Open && time.Since(n.openedAt) >= h.cooldown {
+		n.state = HalfOpen
+	}
+	return n.state == Closed
+}
+
+// Success records a tx that made it through node [uri].
+func (h *Health) Success(uri int) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	n := h.nodes[uri]
+	if n.state == Closed {
+		n.failures = 0
+	}
+}
+
+// Observe records the confirmation latency of a tx sent through node [uri].
+func (h *Health) Observe(uri int, latency time.Duration) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	n := h.nodes[uri]
+	ms := float64(latency.Milliseconds())
+	if n.latency == 0 {
+		n.latency = ms
+		return
+	}
+	n.latency = latencyDecay*ms + (1-latencyDecay)*n.latency
+}
+
+// Latency is the smoothed tx confirmation latency of node [uri]. It is zero
+// until the first sample arrives.
+func (h *Health) Latency(uri int) time.Duration {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	return time.Duration(h.nodes[uri].latency) * time.Millisecond
+}
+
+// Failure records a failed probe or tx against node [uri].
+func (h *Health) Failure(uri int, err error) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	n := h.nodes[uri]
+	n.failures++
+	switch n.state {
+	case Closed:
+		if n.failures < h.threshold {
+			return
+		}
+	case HalfOpen:
+	default:
+		return
+	}
+	n.state = Open
+	n.openedAt = time.Now()
+	n.trips++
+	utils.Outf("{{orange}}node unhealthy, no longer sending to it:{{/}} %d {{orange}}error:{{/}} %v\n", uri, err)
+}
+
+func (h *Health) probeSucceeded(uri int) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	n := h.nodes[uri]
+	if n.state == Open && time.Since(n.openedAt) >= h.cooldown {
+		n.state = HalfOpen
+	}
+	switch n.state {
+	case Closed:
+		n.failures = 0
+	case HalfOpen:
+		n.state = Closed
+		n.failures = 0
+		utils.Outf("{{green}}node recovered, sending to it again:{{/}} %d\n", uri)
+	}
+}
+
+// State returns the breaker state of node [uri] and how often it tripped.
+func (h *Health) State(uri int) (State, int) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	n := h.nodes[uri]
+	return n.state, n.trips
+}
+
+func (h *Health) String() string {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	parts := make([]string, len(h.nodes))
+	for i, n := range h.nodes {
+		parts[i] = fmt.Sprintf("%d=%s(trips=%d latency=%dms)", i, n.state, n.trips, int64(n.latency))
+	}
+	return strings.Join(parts, " ")
+}
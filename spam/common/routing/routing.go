@@ -0,0 +1,175 @@
+// Package routing decides which issuer each tx is sent through and keeps
+// unhealthy nodes out of rotation.
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrNoHealthyIssuer = errors.New("no healthy issuer")
+
+type Strategy string
+
+const (
+	// Sticky sends all of an account's txs through the same issuer and only
+	// moves the account when that issuer's node becomes unhealthy.
+	Sticky Strategy = "sticky"
+	// RoundRobin rotates through issuers on every tx.
+	RoundRobin Strategy = "round-robin"
+	// LeastOutstanding picks the issuer with the fewest unconfirmed txs.
+	LeastOutstanding Strategy = "least-outstanding"
+	// LatencyWeighted picks issuers at random, weighted by the inverse of
+	// their node's tx confirmation latency.
+	LatencyWeighted Strategy = "latency-weighted"
+)
+
+var Strategies = []Strategy{Sticky, RoundRobin, LeastOutstanding, LatencyWeighted}
+
+// Issuer is a connection to a node that txs can be sent through.
+type Issuer interface {
+	// URI is the index of the node the issuer is connected to.
+	URI() int
+	// Outstanding is the number of txs sent but not yet confirmed.
+	Outstanding() int
+}
+
+type Router struct {
+	strategy Strategy
+	issuers  []Issuer
+	health   *Health
+
+	next atomic.Uint64
+
+	l      sync.Mutex
+	rng    *rand.Rand
+	sticky map[int]int
+}
+
+func New(strategy Strategy, issuers []Issuer, health *Health) (*Router, error) {
+	valid := false
+	for _, s := range Strategies {
+		valid = valid || s == strategy
+	}
+	if !valid {
+		return nil, fmt.Errorf("unknown issuer strategy %q (expected one of %v)", strategy, Strategies)
+	}
+	return &Router{
+		strategy: strategy,
+		issuers:  issuers,
+		health:   health,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		sticky:   map[int]int{},
+	}, nil
+}
+
+// Next returns the index of the issuer [account] should send its next tx
+// through.
+func (r *Router) Next(account int) (int, error) {
+	switch r.strategy {
+	case RoundRobin:
+		for range r.issuers {
+			i := int(r.next.Add(1)-1) % len(r.issuers)
+			if r.healthy(i) {
+				return i, nil
+			}
+		}
+		return 0, ErrNoHealthyIssuer
+	case LeastOutstanding:
+		best, bestOutstanding := -1, 0
+		for _, i := range r.shuffled() {
+			if !r.healthy(i) {
+				continue
+			}
+			outstanding := r.issuers[i].Outstanding()
+			if best < 0 || outstanding < bestOutstanding {
+				best, bestOutstanding = i, outstanding
+			}
+		}
+		if best < 0 {
+			return 0, ErrNoHealthyIssuer
+		}
+		return best, nil
+	case LatencyWeighted:
+		return r.weighted()
+	default:
+		r.l.Lock()
+		i, ok := r.sticky[account]
+		r.l.Unlock()
+		if ok && r.healthy(i) {
+			return i, nil
+		}
+		for _, i := range r.shuffled() {
+			if r.healthy(i) {
+				r.l.Lock()
+				r.sticky[account] = i
+				r.l.Unlock()
+				return i, nil
+			}
+		}
+		return 0, ErrNoHealthyIssuer
+	}
+}
+
+func (r *Router) healthy(i int) bool {
+	return r.health.Healthy(r.issuers[i].URI())
+}
+
+func (r *Router) shuffled() []int {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	return r.rng.Perm(len(r.issuers))
+}
+
+func (r *Router) weighted() (int, error) {
+	weights := make([]float64, len(r.issuers))
+	var total float64
+	var known, unknown int
+	for i, issuer := range r.issuers {
+		if !r.healthy(i) {
+			continue
+		}
+		latency := r.health.Latency(issuer.URI())
+		if latency <= 0 {
+			unknown++
+			continue
+		}
+		weights[i] = 1 / float64(latency.Milliseconds()+1)
+		total += weights[i]
+		known++
+	}
+	if known+unknown == 0 {
+		return 0, ErrNoHealthyIssuer
+	}
+	// Nodes without samples get the mean weight so they are tried.
+	mean := 1.0
+	if known > 0 {
+		mean = total / float64(known)
+	}
+	for i := range r.issuers {
+		if weights[i] == 0 && r.healthy(i) && r.health.Latency(r.issuers[i].URI()) <= 0 {
+			weights[i] = mean
+			total += mean
+		}
+	}
+	r.l.Lock()
+	pick := r.rng.Float64() * total
+	r.l.Unlock()
+	last := -1
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		last = i
+		if pick < w {
+			return i, nil
+		}
+		pick -= w
+	}
+	return last, nil
+}
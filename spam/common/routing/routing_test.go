@@ -0,0 +1,224 @@
+package routing
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type fakeIssuer struct {
+	uri         int
+	outstanding int
+}
+
+func (f *fakeIssuer) URI() int         { return f.uri }
+func (f *fakeIssuer) Outstanding() int { return f.outstanding }
+
+var errDown = errors.New("down")
+
+// newRouter routes over one issuer per node, with the given outstanding
+// txs, and opens the circuits of the [down] nodes.
+func newRouter(t *testing.T, strategy Strategy, outstanding []int, down ...int) (*Router, *Health) {
+	t.Helper()
+
+	uris := make([]string, len(outstanding))
+	issuers := make([]Issuer, len(outstanding))
+	for i := range issuers {
+		issuers[i] = &fakeIssuer{uri: i, outstanding: outstanding[i]}
+	}
+	h := NewHealth(uris, 1, time.Hour)
+	for _, i := range down {
+		h.Failure(i, errDown)
+	}
+	r, err := New(strategy, issuers, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.rng = rand.New(rand.NewSource(1)) //nolint:gosec
+	return r, h
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    Strategy
+		outstanding []int
+		down        []int
+		// want is the issuers picked by consecutive calls for account 0
+		want []int
+	}{
+		{
+			name:        "round-robin",
+			strategy:    RoundRobin,
+			outstanding: []int{0, 0, 0},
+			want:        []int{0, 1, 2, 0, 1, 2},
+		},
+		{
+			name:        "round-robin skips unhealthy",
+			strategy:    RoundRobin,
+			outstanding: []int{0, 0, 0},
+			down:        []int{1},
+			want:        []int{0, 2, 0, 2},
+		},
+		{
+			name:        "least-outstanding",
+			strategy:    LeastOutstanding,
+			outstanding: []int{5, 1, 3},
+			want:        []int{1, 1, 1},
+		},
+		{
+			name:        "least-outstanding skips unhealthy",
+			strategy:    LeastOutstanding,
+			outstanding: []int{5, 1, 3},
+			down:        []int{1},
+			want:        []int{2, 2, 2},
+		},
+		{
+			name:        "sticky with one healthy",
+			strategy:    Sticky,
+			outstanding: []int{0, 0, 0},
+			down:        []int{0, 2},
+			want:        []int{1, 1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newRouter(t, tt.strategy, tt.outstanding, tt.down...)
+			for n, want := range tt.want {
+				got, err := r.Next(0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Fatalf("call %d picked issuer %d, want %d", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextNoHealthyIssuer(t *testing.T) {
+	for _, strategy := range Strategies {
+		t.Run(string(strategy), func(t *testing.T) {
+			r, _ := newRouter(t, strategy, []int{0, 0}, 0, 1)
+			if _, err := r.Next(0); !errors.Is(err, ErrNoHealthyIssuer) {
+				t.Fatalf("got %v, want %v", err, ErrNoHealthyIssuer)
+			}
+		})
+	}
+}
+
+func TestStickyMovesOffUnhealthy(t *testing.T) {
+	r, h := newRouter(t, Sticky, []int{0, 0, 0})
+	first, err := r.Next(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		if i, _ := r.Next(7); i != first {
+			t.Fatalf("account moved from issuer %d to %d while healthy", first, i)
+		}
+	}
+	h.Failure(first, errDown)
+	moved, err := r.Next(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved == first {
+		t.Fatalf("account stayed on unhealthy issuer %d", first)
+	}
+	for range 5 {
+		if i, _ := r.Next(7); i != moved {
+			t.Fatalf("account moved from issuer %d to %d while healthy", moved, i)
+		}
+	}
+}
+
+func TestLatencyWeighted(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		down      []int
+		// want is the share of picks per issuer
+		want []float64
+	}{
+		{
+			name:      "inverse latency",
+			latencies: []time.Duration{9 * time.Millisecond, 99 * time.Millisecond},
+			// 1/10 against 1/100
+			want: []float64{10.0 / 11, 1.0 / 11},
+		},
+		{
+			name:      "unsampled gets the mean weight",
+			latencies: []time.Duration{9 * time.Millisecond, 99 * time.Millisecond, 0},
+			// 1/10, 1/100 and their mean 11/200
+			want: []float64{20.0 / 33, 2.0 / 33, 11.0 / 33},
+		},
+		{
+			name:      "none sampled",
+			latencies: []time.Duration{0, 0},
+			want:      []float64{0.5, 0.5},
+		},
+		{
+			name:      "unhealthy gets nothing",
+			latencies: []time.Duration{9 * time.Millisecond, 99 * time.Millisecond},
+			down:      []int{0},
+			want:      []float64{0, 1},
+		},
+	}
+	const picks = 20_000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, h := newRouter(t, LatencyWeighted, make([]int, len(tt.latencies)), tt.down...)
+			for i, latency := range tt.latencies {
+				if latency > 0 {
+					h.Observe(i, latency)
+				}
+			}
+			counts := make([]int, len(tt.latencies))
+			for range picks {
+				i, err := r.Next(0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				counts[i]++
+			}
+			for i, want := range tt.want {
+				if got := float64(counts[i]) / picks; math.Abs(got-want) > 0.01 {
+					t.Errorf("issuer %d got %.3f of picks, want %.3f", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthBreaker(t *testing.T) {
+	h := NewHealth([]string{"a"}, 2, 0)
+	h.Failure(0, errDown)
+	if !h.Healthy(0) {
+		t.Fatal("opened below the threshold")
+	}
+	h.Success(0)
+	h.Failure(0, errDown)
+	if !h.Healthy(0) {
+		t.Fatal("a success didn't reset the failures")
+	}
+	h.Failure(0, errDown)
+	if state, trips := h.State(0); state != Open || trips != 1 {
+		t.Fatalf("got %s after %d trips, want %s after 1", state, trips, Open)
+	}
+	// The cooldown passed, so the node waits for a probe
+	if h.Healthy(0) {
+		t.Fatal("half-open node took traffic")
+	}
+	h.Failure(0, errDown)
+	if state, trips := h.State(0); state != Open || trips != 2 {
+		t.Fatalf("got %s after %d trips, want %s after 2", state, trips, Open)
+	}
+	h.probeSucceeded(0)
+	if !h.Healthy(0) {
+		t.Fatal("a successful probe didn't close the circuit")
+	}
+}
@@ -0,0 +1,1127 @@
+// Package runner runs a spammer: it funds accounts, issues the txs of a
+// [Generator] from them and reports on the run. Every spammer shares it and
+// only brings its generator.
+package runner
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/hypersdk/crypto/ed25519"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/utils"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/pubsub"
+	"github.com/AnomalyFi/hypersdk/rpc"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	"github.com/AnomalyFi/nodekit-seq/auth"
+	"github.com/AnomalyFi/nodekit-seq/consts"
+	trpc "github.com/AnomalyFi/nodekit-seq/rpc"
+	"github.com/AnomalyFi/tools/common/config"
+	"github.com/AnomalyFi/tools/common/credentials"
+	"github.com/AnomalyFi/tools/spam/common/blocks"
+	"github.com/AnomalyFi/tools/spam/common/chaos"
+	"github.com/AnomalyFi/tools/spam/common/dashboard"
+	"github.com/AnomalyFi/tools/spam/common/distributed"
+	"github.com/AnomalyFi/tools/spam/common/feemarket"
+	"github.com/AnomalyFi/tools/spam/common/reconcile"
+	"github.com/AnomalyFi/tools/spam/common/results"
+	"github.com/AnomalyFi/tools/spam/common/routing"
+	"github.com/AnomalyFi/tools/spam/common/stats"
+	"github.com/AnomalyFi/tools/spam/common/summary"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var (
+	ErrTxFailed = errors.New("tx failed on-chain")
+)
+
+const (
+	defaultRange          = 32
+	issuerShutdownTimeout = 60 * time.Second
+	// defaultNetwork is spammed without --uris or --network
+	defaultNetwork = "devnet"
+)
+
+const (
+	decimals              = 9
+	maxTxBacklog          = 500
+	maxFee                = -1
+	randomRecipient       = false
+	numClients            = 1
+	MillisecondsPerSecond = 1000 // 1000ms = 1 sec
+)
+
+// Generator is what sets a spammer apart: the txs it sends.
+type Generator struct {
+	// Name names the spammer's flags, dashboard and stored runs
+	Name string
+	// Accounts and TxsPerAccount (per second) are the load of a run that
+	// isn't distributed
+	Accounts      int
+	TxsPerAccount int
+	// Node is the node the balance and the network are looked up on, or the
+	// last one if there are fewer
+	Node int
+	// Estimate returns the actions of a tx from [from] whose max units every
+	// tx pays for
+	Estimate func(from codec.Address) []chain.Action
+	// Next returns the next tx of an account to [recipient], which is the
+	// [v]th of the account to it this round; [rng] is the account's own.
+	Next func(rng *rand.Rand, parser chain.Parser, factory chain.AuthFactory, recipient codec.Address, v int) (*Tx, error)
+}
+
+// Tx is what a [Generator] sends next: actions the runner makes a tx of, or
+// Raw bytes, e.g. an ill-formed tx, it submits without tracking them.
+type Tx struct {
+	Actions []chain.Action
+	Raw     []byte
+}
+
+// run is one run of a spammer: its flags and the counters its issuers share.
+type run struct {
+	g  Generator
+	fs *flag.FlagSet
+
+	blockStats    *bool
+	blockStatsCSV *string
+
+	feeMarketOut      *string
+	feeMarketPhases   *string
+	feeMarketInterval *time.Duration
+
+	reconcileBalances *bool
+	reconcileOut      *string
+
+	issuerStrategy   *string
+	healthInterval   *time.Duration
+	breakerThreshold *int
+	breakerCooldown  *time.Duration
+
+	chaosSpec     *string
+	chaosEndpoint *string
+	chaosTail     *time.Duration
+	chaosWindow   *time.Duration
+	chaosRecovery *float64
+	chaosOut      *string
+
+	showDashboard *bool
+
+	urisOverride *string
+	networkName  *string
+
+	keySpec     *string
+	allowDevKey *bool
+
+	controllerAddr    *string
+	workerOf          *string
+	workers           *int
+	distAccounts      *int
+	distTxsPerAccount *int
+
+	duration     *time.Duration
+	maxTxs       *int64
+	drainTimeout *time.Duration
+	summaryOut   *string
+	resultsOut   *string
+	resultsTags  *string
+
+	sloSuccessRate *float64
+	sloMinTPS      *float64
+	sloMaxP50      *time.Duration
+	sloMaxP99      *time.Duration
+	sloMaxFailures *int64
+
+	issuerWg sync.WaitGroup
+	exiting  sync.Once
+
+	l            sync.Mutex
+	confirmedTxs uint64
+	totalTxs     uint64
+
+	inflight atomic.Int64
+	sent     atomic.Int64
+
+	ledger    *reconcile.Ledger
+	health    *routing.Health
+	collector *stats.Collector
+}
+
+// newRun registers the flags of a run of [g], named after it.
+func newRun(g Generator) *run {
+	fs := flag.NewFlagSet(g.Name, flag.ContinueOnError)
+	r := &run{g: g, fs: fs}
+	r.blockStats = fs.Bool("blocks", false, "subscribe to accepted blocks and report inclusion stats")
+	r.blockStatsCSV = fs.String("blocks-csv", "", "write per-block stats to this CSV file (implies --blocks)")
+
+	r.feeMarketOut = fs.String("feemarket", "", "run the fee market experiment and write samples to this CSV file")
+	r.feeMarketPhases = fs.String("feemarket-phases", feemarket.DefaultPhases, "experiment load phases as name:duration:txsPerAccount,...")
+	r.feeMarketInterval = fs.Duration("feemarket-interval", time.Second, "how often the experiment samples unit prices and load")
+
+	r.reconcileBalances = fs.Bool("reconcile", false, "compare every account's on-chain balance with the predicted balance at the end of the run")
+	r.reconcileOut = fs.String("reconcile-out", "", "write the reconciliation report to this JSON file (implies --reconcile)")
+
+	r.issuerStrategy = fs.String("issuer-strategy", string(routing.Sticky), "how txs are routed to issuers: sticky, round-robin, least-outstanding or latency-weighted")
+	r.healthInterval = fs.Duration("health-interval", 2*time.Second, "how often every node is probed")
+	r.breakerThreshold = fs.Int("breaker-threshold", 3, "consecutive failures before a node stops receiving txs")
+	r.breakerCooldown = fs.Duration("breaker-cooldown", 10*time.Second, "how long an unhealthy node is left alone before probes may restore it")
+
+	r.chaosSpec = fs.String("chaos", "", "fault schedule as offset:action:node,... (actions: stop, restart, pause, resume); loads the cluster from ANR")
+	r.chaosEndpoint = fs.String("chaos-anr", "0.0.0.0:12352", "avalanche-network-runner endpoint used to discover and fault nodes")
+	r.chaosTail = fs.Duration("chaos-tail", time.Minute, "how long to keep running after the last fault")
+	r.chaosWindow = fs.Duration("chaos-window", 10*time.Second, "window before and after each fault that is compared")
+	r.chaosRecovery = fs.Float64("chaos-recovery", 0.9, "share of the pre-fault throughput that counts as recovered")
+	r.chaosOut = fs.String("chaos-out", "", "write the chaos report to this JSON file")
+
+	r.showDashboard = fs.Bool("dashboard", false, "show a full-screen live dashboard instead of printing stats lines")
+
+	r.urisOverride = fs.String("uris", "", "comma separated chain URIs to spam instead of the devnet, e.g. the ones printed by mock-seq")
+	r.networkName = config.NetworkFlag(fs)
+
+	r.keySpec, r.allowDevKey = credentials.Flags(fs)
+
+	r.controllerAddr = fs.String("controller", "", "coordinate a distributed run from this address instead of spamming, e.g. 127.0.0.1:7700")
+	r.workerOf = fs.String("worker", "", "spam as a worker of the controller at this address")
+	r.workers = fs.Int("workers", 2, "number of workers the controller waits for")
+	r.distAccounts = fs.Int("accounts", 0, "accounts the controller splits between workers (default "+strconv.Itoa(g.Accounts)+" per worker)")
+	r.distTxsPerAccount = fs.Int("txs-per-account", g.TxsPerAccount, "txs each account of a distributed run sends per second")
+
+	r.duration = fs.Duration("duration", 0, "stop issuing after this long (0 runs until interrupted)")
+	r.maxTxs = fs.Int64("max-txs", 0, "stop issuing once this many txs have been sent (0 for no limit)")
+	r.drainTimeout = fs.Duration("drain-timeout", issuerShutdownTimeout, "how long to wait for outstanding txs to confirm or expire once issuing stops")
+	r.summaryOut = fs.String("summary-out", "", "write the final summary to this JSON file")
+	r.resultsOut = fs.String("results", results.DefaultStore, "append the run's flags, environment and results to this JSON-lines store (empty to disable)")
+	r.resultsTags = fs.String("tags", "", "comma separated tags to store with the run, e.g. baseline")
+
+	r.sloSuccessRate = fs.Float64("slo-success-rate", 0, "exit non-zero if the share of confirmed txs is below this, e.g. 0.99")
+	r.sloMinTPS = fs.Float64("slo-min-tps", 0, "exit non-zero if confirmed txs per second are below this")
+	r.sloMaxP50 = fs.Duration("slo-max-p50", 0, "exit non-zero if the median confirmation latency is above this")
+	r.sloMaxP99 = fs.Duration("slo-max-p99", 0, "exit non-zero if the p99 confirmation latency is above this")
+	r.sloMaxFailures = fs.Int64("slo-max-failures", -1, "exit non-zero if more txs than this fail, expire or are still outstanding after the drain")
+
+	return r
+}
+
+type PrivateKey struct {
+	Address codec.Address
+	Bytes   []byte
+}
+
+type txIssuer struct {
+	c *rpc.JSONRPCClient
+	d *rpc.WebSocketClient
+
+	l              sync.Mutex
+	uri            int
+	outstandingTxs int
+	pending        map[ids.ID]time.Time
+	// reconnects and errors are shown on the dashboard
+	reconnects int
+	errors     int
+}
+
+func (i *txIssuer) URI() int {
+	return i.uri
+}
+
+func (i *txIssuer) socket() *rpc.WebSocketClient {
+	i.l.Lock()
+	defer i.l.Unlock()
+	return i.d
+}
+
+// settle gives up on the txs pending on the closed socket of [i], which
+// will never report their results. They stay pending in the ledger, as their
+// outcome is unknown. The caller holds [i.l].
+func (r *run) settle(i *txIssuer) {
+	for range i.pending {
+		r.collector.Resolved(stats.Unknown, 0)
+	}
+	r.inflight.Add(-int64(len(i.pending)))
+	i.outstandingTxs -= len(i.pending)
+	i.pending = map[ids.ID]time.Time{}
+}
+
+func (i *txIssuer) Outstanding() int {
+	i.l.Lock()
+	defer i.l.Unlock()
+	return i.outstandingTxs
+}
+
+// Main runs the spammer of [g] with the command line [args], without the
+// program name, and exits non-zero if the run failed an SLO.
+func Main(g Generator, args []string) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	go func() {
+		// A second interrupt exits without draining
+		<-ctx.Done()
+		cancel()
+	}()
+	sum, err := Run(ctx, g, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		panic(err)
+	}
+	if !sum.Passed {
+		os.Exit(1)
+	}
+}
+
+// Run runs the spammer of [g] with the command line [args] and returns its
+// summary. Cancelling [ctx] aborts the setup; once txs are being issued it
+// stops issuing instead, and the run drains and reports as usual.
+func Run(ctx context.Context, g Generator, args []string) (*summary.Summary, error) {
+	r := newRun(g)
+	if err := r.fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// chain: the devnet unless another network or URIs are asked for
+	var (
+		uris    []string
+		chainID ids.ID
+		hrp     = "token"
+	)
+	switch {
+	case len(*r.urisOverride) > 0 && len(*r.networkName) > 0:
+		return nil, errors.New("--uris and --network are exclusive")
+	case len(*r.urisOverride) > 0:
+		uris = strings.Split(*r.urisOverride, ",")
+		var err error
+		_, _, chainID, err = rpc.NewJSONRPCClient(uris[0]).Network(ctx)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		name := *r.networkName
+		if len(name) == 0 {
+			name = defaultNetwork
+		}
+		target, err := config.ResolveNetwork(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		uris, chainID = target.URIs, target.ChainID
+		if len(target.HRP) > 0 {
+			hrp = target.HRP
+		}
+	}
+
+	var (
+		chaosANR *chaos.ANR
+		faults   []chaos.Fault
+	)
+	if len(*r.chaosSpec) > 0 {
+		var err error
+		faults, err = chaos.ParseSchedule(*r.chaosSpec)
+		if err != nil {
+			return nil, err
+		}
+		chaosANR, err = chaos.NewANR(*r.chaosEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		defer chaosANR.Close()
+		// Faults are only meaningful against the cluster we inject them into
+		cluster, err := chaosANR.Cluster(ctx)
+		if err != nil {
+			return nil, err
+		}
+		uris, chainID = cluster.URIs, cluster.ChainID
+		for i, uri := range uris {
+			utils.Outf("{{yellow}}uri %d:{{/}} %s {{yellow}}node:{{/}} %s\n", i, uri, cluster.Nodes[i])
+		}
+	}
+
+	// root private key, with all the funds:
+	rootKey, err := credentials.Load(*r.keySpec, credentials.ED25519)
+	if err != nil {
+		return nil, err
+	}
+	if err := rootKey.CheckNetwork(uris, *r.allowDevKey); err != nil {
+		return nil, err
+	}
+	priv, err := rootKey.ED25519()
+	if err != nil {
+		return nil, err
+	}
+	factory := auth.NewED25519Factory(priv)
+	address := auth.NewED25519Address(priv.PublicKey())
+	sddr, _ := codec.AddressBech32(hrp, address)
+	node := uris[min(g.Node, len(uris)-1)]
+	cli := rpc.NewJSONRPCClient(node)
+	networkID, _, _, err := cli.Network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tclient, _, err := createClient(node, networkID, chainID)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := lookupBalance(tclient, sddr)
+	if err != nil {
+		return nil, err
+	}
+	actions := g.Estimate(address)
+	parser, err := tclient.Parser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	maxUnits, err := chain.EstimateUnits(parser.Rules(time.Now().UnixMilli()), actions, factory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Distribute funds to accounts:
+	unitPrices, err := cli.UnitPrices(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	var env *results.Environment
+	if len(*r.resultsOut) > 0 && len(*r.workerOf) == 0 {
+		env = results.NewEnvironment(uris, networkID, chainID, parser.Rules(time.Now().UnixMilli()), unitPrices)
+		if err := env.LoadVersions(ctx); err != nil {
+			utils.Outf("{{red}}could not fetch the node version:{{/}} %v\n", err)
+		}
+	}
+	if len(*r.controllerAddr) > 0 {
+		plan := distributed.Plan{
+			Workers:       *r.workers,
+			Accounts:      *r.distAccounts,
+			TxsPerAccount: *r.distTxsPerAccount,
+			Budget:        balance,
+		}
+		if plan.Accounts == 0 {
+			plan.Accounts = g.Accounts * plan.Workers
+		}
+		controller, err := distributed.NewController(*r.controllerAddr, plan)
+		if err != nil {
+			return nil, err
+		}
+		// The controller handles interrupts itself
+		report, err := controller.Run(context.WithoutCancel(ctx), *r.duration)
+		if err != nil {
+			return nil, err
+		}
+		report.Print()
+		sum := summary.New(report.StopReason, report.Stopped, report.Final)
+		if err := r.finish(sum, env, report.Final, report.Series); err != nil {
+			return nil, err
+		}
+		return sum, nil
+	}
+	accountCount, accountRate := g.Accounts, g.TxsPerAccount
+	var worker *distributed.Worker
+	if len(*r.workerOf) > 0 {
+		worker, err = distributed.Join(ctx, *r.workerOf)
+		if err != nil {
+			return nil, err
+		}
+		a := worker.Assignment()
+		accountCount, accountRate = a.Accounts, a.TxsPerAccount
+		balance = min(balance, a.Budget)
+		utils.Outf(
+			"{{yellow}}worker:{{/}} %d/%d {{yellow}}accounts:{{/}} %d {{yellow}}txs per account:{{/}} %d/s {{yellow}}budget:{{/}} %s SEQ\n",
+			a.Worker+1,
+			a.Workers,
+			accountCount,
+			accountRate,
+			utils.FormatBalance(balance, decimals),
+		)
+	}
+
+	feePerTx, err := fees.MulSum(unitPrices, maxUnits)
+	if err != nil {
+		return nil, err
+	}
+	witholding := feePerTx * uint64(accountCount)
+	if balance < witholding {
+		return nil, fmt.Errorf("insufficient funds (have=%d need=%d)", balance, witholding)
+	}
+	distAmount := (balance - witholding) / uint64(accountCount)
+	utils.Outf(
+		"{{yellow}}distributing funds to each account:{{/}} %s %s\n",
+		utils.FormatBalance(distAmount, decimals),
+		"SEQ",
+	)
+	accounts := make([]*PrivateKey, accountCount)
+	dcli, err := rpc.NewWebSocketClient(uris[0], rpc.DefaultHandshakeTimeout, pubsub.MaxPendingMessages, pubsub.MaxReadMessageSize) // we write the max read
+	if err != nil {
+		return nil, err
+	}
+	funds := map[codec.Address]uint64{}
+	var fundsL sync.Mutex
+	if *r.reconcileBalances || len(*r.reconcileOut) > 0 {
+		r.ledger = reconcile.New()
+	}
+	for i := 0; i < accountCount; i++ {
+		// Create account
+		pk, err := createAccount()
+		if err != nil {
+			return nil, err
+		}
+		accounts[i] = pk
+
+		// Send funds
+		_, tx, err := cli.GenerateTransactionManual(parser, Transfer(pk.Address, distAmount), factory, feePerTx)
+		if err != nil {
+			return nil, err
+		}
+		if err := dcli.RegisterTx(tx); err != nil {
+			return nil, fmt.Errorf("%w: failed to register tx", err)
+		}
+		funds[pk.Address] = distAmount
+		if r.ledger != nil {
+			r.ledger.Fund(pk.Address, distAmount)
+		}
+	}
+
+	for i := 0; i < accountCount; i++ {
+		_, dErr, result, err := dcli.ListenTx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if dErr != nil {
+			return nil, dErr
+		}
+		if !result.Success {
+			// Should never happen
+			return nil, fmt.Errorf("%w: %s", ErrTxFailed, result.Error)
+		}
+	}
+	utils.Outf("{{yellow}}distributed funds to %d accounts{{/}}\n", accountCount)
+	// Kickoff txs
+	clients := []*txIssuer{}
+	for i := 0; i < len(uris); i++ {
+		for j := 0; j < numClients; j++ {
+			cli := rpc.NewJSONRPCClient(uris[i])
+			dcli, err := rpc.NewWebSocketClient(uris[i], rpc.DefaultHandshakeTimeout, pubsub.MaxPendingMessages, pubsub.MaxReadMessageSize) // we write the max read
+			if err != nil {
+				return nil, err
+			}
+			clients = append(clients, &txIssuer{c: cli, d: dcli, uri: i, pending: map[ids.ID]time.Time{}})
+		}
+	}
+	// confirm txs (track failure rate)
+	unitPrices, err = clients[0].c.UnitPrices(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	PrintUnitPrices(unitPrices)
+	// From here on [ctx] only stops issuing: the drain and the reports still
+	// talk to the nodes
+	interrupted := ctx.Done()
+	ctx = context.WithoutCancel(ctx)
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.health = routing.NewHealth(uris, *r.breakerThreshold, *r.breakerCooldown)
+	r.health.Start(cctx, *r.healthInterval, func(ctx context.Context, uri string) error {
+		_, err := rpc.NewJSONRPCClient(uri).Ping(ctx)
+		return err
+	})
+	issuers := make([]routing.Issuer, len(clients))
+	for i, client := range clients {
+		issuers[i] = client
+	}
+	router, err := routing.New(routing.Strategy(*r.issuerStrategy), issuers, r.health)
+	if err != nil {
+		return nil, err
+	}
+	if worker != nil {
+		// Every worker starts issuing at the same time
+		if err := worker.Ready(ctx); err != nil {
+			return nil, err
+		}
+	}
+	r.collector = stats.NewCollector()
+	sampler := results.NewSampler(r.collector)
+	go sampler.Run(cctx)
+	stopReason := "completed"
+	var stopped *stats.Snapshot
+	stop := func(reason string) {
+		r.exiting.Do(func() {
+			utils.Outf("{{yellow}}stopping:{{/}} %s\n", reason)
+			stopReason = reason
+			stopped = r.collector.Snapshot()
+			cancel()
+		})
+	}
+	if *r.duration > 0 {
+		timer := time.AfterFunc(*r.duration, func() { stop("duration reached") })
+		defer timer.Stop()
+	}
+	if worker != nil {
+		worker.Report(ctx, r.collector, stop)
+	}
+	var blockListener *blocks.Listener
+	if *r.blockStats || len(*r.blockStatsCSV) > 0 {
+		blockListener, err = blocks.New(uris[0], parser, *r.blockStatsCSV)
+		if err != nil {
+			return nil, err
+		}
+		if err := blockListener.Start(cctx); err != nil {
+			return nil, err
+		}
+	}
+	txsPerAccount := func() int { return accountRate }
+	var feeRecorder *feemarket.Recorder
+	if len(*r.feeMarketOut) > 0 {
+		phases, err := feemarket.ParsePhases(*r.feeMarketPhases)
+		if err != nil {
+			return nil, err
+		}
+		schedule := feemarket.NewSchedule(phases)
+		feeRecorder, err = feemarket.NewRecorder(*r.feeMarketOut, *r.feeMarketInterval, schedule, feemarket.Source{
+			UnitPrices: func(ctx context.Context) (fees.Dimensions, error) {
+				return clients[0].c.UnitPrices(ctx, false)
+			},
+			Issued: func() uint64 {
+				return uint64(r.sent.Load())
+			},
+			Confirmed: func() uint64 {
+				r.l.Lock()
+				defer r.l.Unlock()
+				return r.confirmedTxs
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		txsPerAccount = schedule.TxsPerAccount
+		go func() {
+			feeRecorder.Run(cctx)
+			stop("fee market experiment finished")
+		}()
+	}
+	for _, client := range clients {
+		r.startIssuer(cctx, client)
+	}
+
+	// log stats
+	t := time.NewTicker(1 * time.Second) // ensure no duplicates created
+	defer t.Stop()
+	var psent int64
+	var board *dashboard.Dashboard
+	if *r.showDashboard {
+		board, err = dashboard.Start(g.Name + " spam")
+		if err != nil {
+			return nil, err
+		}
+		defer board.Close()
+	}
+	go func() {
+		prev := r.collector.Snapshot()
+		for {
+			select {
+			case <-t.C:
+				if board != nil {
+					if prices, err := clients[0].c.UnitPrices(ctx, false); err == nil {
+						unitPrices = prices
+					}
+					current := r.collector.Snapshot()
+					board.Update(&dashboard.Frame{
+						Delta:      current.Sub(prev),
+						Total:      current,
+						Inflight:   r.inflight.Load(),
+						UnitPrices: unitPrices,
+						Nodes:      r.nodeRows(clients, uris),
+					})
+					prev = current
+					continue
+				}
+				current := r.sent.Load()
+				// Asked outside the lock the issuers' listeners take
+				prices, err := clients[0].c.UnitPrices(ctx, false)
+				if err != nil {
+					continue
+				}
+				unitPrices = prices
+				r.l.Lock()
+				if r.totalTxs > 0 {
+					utils.Outf(
+						"{{yellow}}txs seen:{{/}} %d {{yellow}}success rate:{{/}} %.2f%% {{yellow}}inflight:{{/}} %d {{yellow}}issued/s:{{/}} %d {{yellow}}unit prices:{{/}} [%s]\n", //nolint:lll
+						r.totalTxs,
+						float64(r.confirmedTxs)/float64(r.totalTxs)*100,
+						r.inflight.Load(),
+						current-psent,
+						ParseDimensions(unitPrices),
+					)
+				}
+				r.l.Unlock()
+				if blockListener != nil {
+					utils.Outf("{{yellow}}blocks:{{/}} %s\n", blockListener.Report())
+				}
+				psent = current
+			case <-cctx.Done():
+				return
+			}
+		}
+	}()
+
+	var chaosRunner *chaos.Runner
+	if chaosANR != nil {
+		chaosRunner = chaos.NewRunner(chaosANR, faults, r.collector)
+		go func() {
+			chaosRunner.Run(cctx, *r.chaosTail)
+			stop("chaos schedule finished")
+		}()
+	}
+
+	// broadcast txs
+	eg, gctx := errgroup.WithContext(ctx)
+	for ri := 0; ri < accountCount; ri++ {
+		i := ri
+		eg.Go(func() error {
+			t := time.NewTimer(0) // ensure no duplicates created
+			defer t.Stop()
+			factory, err := getFactory(accounts[i])
+			if err != nil {
+				return err
+			}
+			fundsL.Lock()
+			balance := funds[accounts[i].Address]
+			fundsL.Unlock()
+			defer func() {
+				fundsL.Lock()
+				funds[accounts[i].Address] = balance
+				fundsL.Unlock()
+			}()
+			rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+			ut := time.Now().Unix()
+			for {
+				select {
+				case <-t.C:
+					// Ensure we aren't too backlogged
+					if r.inflight.Load() > int64(maxTxBacklog) {
+						t.Reset(1 * time.Second)
+						continue
+					}
+
+					// Select tx time
+					//
+					// Needed to prevent duplicates if called within the same
+					// unix second.
+					nextTime := time.Now().Unix()
+					if nextTime <= ut {
+						nextTime = ut + 1
+					}
+					ut = nextTime
+					tm := &timeModifier{nextTime*MillisecondsPerSecond + parser.Rules(nextTime).GetValidityWindow() - 5*MillisecondsPerSecond}
+
+					// Send transaction
+					start := time.Now()
+					selected := map[codec.Address]int{}
+					for k, n := 0, txsPerAccount(); k < n; k++ {
+						if *r.maxTxs > 0 && r.sent.Load() >= *r.maxTxs {
+							stop("max txs sent")
+							break
+						}
+						issuerIndex, err := router.Next(i)
+						if err != nil {
+							utils.Outf("{{orange}}failed to select issuer:{{/}} %v\n", err)
+							r.collector.Error(stats.Unroutable)
+							break
+						}
+						issuer := clients[issuerIndex]
+						recipient, err := getNextRecipient(i, accounts)
+						if err != nil {
+							utils.Outf("{{orange}}failed to get next recipient:{{/}} %v\n", err)
+							return err
+						}
+						v := selected[recipient] + 1
+						selected[recipient] = v
+						next, err := g.Next(rng, parser, factory, recipient, v)
+						if err != nil {
+							utils.Outf("{{orange}}failed to generate tx:{{/}} %v\n", err)
+							continue
+						}
+						if len(next.Raw) > 0 {
+							txID, err := issuer.c.SubmitTx(ctx, next.Raw)
+							if err != nil {
+								utils.Outf("{{orange}}failed to submit raw tx:{{/}} %v\n", err)
+								continue
+							}
+							utils.Outf("{{yellow}}submitted raw tx:{{/}} %s\n", txID)
+							continue
+						}
+						fee, err := fees.MulSum(unitPrices, maxUnits)
+						if err != nil {
+							utils.Outf("{{orange}}failed to estimate max fee:{{/}} %v\n", err)
+							return err
+						}
+
+						_, tx, err := issuer.c.GenerateTransactionManual(parser, next.Actions, factory, fee, tm)
+						if err != nil {
+							utils.Outf("{{orange}}failed to generate tx:{{/}} %v\n", err)
+							continue
+						}
+						if err := issuer.d.RegisterTx(tx); err != nil {
+							r.health.Failure(issuer.uri, err)
+							r.collector.Error(stats.Unsent)
+							issuer.l.Lock()
+							issuer.errors++
+							if issuer.d.Closed() {
+								// recreate issuer
+								utils.Outf("{{orange}}re-creating issuer:{{/}} %d {{orange}}uri:{{/}} %d\n", issuerIndex, issuer.uri)
+								dcli, err := rpc.NewWebSocketClient(uris[issuer.uri], rpc.DefaultHandshakeTimeout, pubsub.MaxPendingMessages, pubsub.MaxReadMessageSize) // we write the max read
+								if err != nil {
+									// The node stays out of rotation until a
+									// health probe restores it.
+									utils.Outf("{{orange}}could not re-create closed issuer:{{/}} %v\n", err)
+									issuer.l.Unlock()
+									continue
+								}
+								r.settle(issuer)
+								issuer.d = dcli
+								issuer.reconnects++
+								go r.listen(cctx, issuer, dcli)
+								utils.Outf("{{green}}re-created closed issuer:{{/}} %d\n", issuerIndex)
+							}
+							issuer.l.Unlock()
+							continue
+						}
+						balance -= (fee + uint64(v))
+						if blockListener != nil {
+							blockListener.Issued(tx.ID())
+						}
+						if r.ledger != nil {
+							r.ledger.Issued(tx.ID(), accounts[i].Address, fee, fee+uint64(v), reconcile.Transfers(tx.Actions))
+						}
+						issuer.l.Lock()
+						issuer.outstandingTxs++
+						issuer.pending[tx.ID()] = time.Now()
+						issuer.l.Unlock()
+						r.inflight.Add(1)
+						r.sent.Add(1)
+						r.collector.Issued()
+					}
+
+					// Determine how long to sleep
+					dur := time.Since(start)
+					sleep := max(float64(MillisecondsPerSecond-dur.Milliseconds()), 0)
+					t.Reset(time.Duration(sleep) * time.Millisecond)
+				case <-gctx.Done():
+					return gctx.Err()
+				case <-cctx.Done():
+					return nil
+				case <-interrupted:
+					stop("interrupted")
+					return nil
+				}
+			}
+		})
+	}
+	broadcastErr := eg.Wait()
+	if broadcastErr != nil {
+		utils.Outf("{{red}}broadcast failed:{{/}} %v\n", broadcastErr)
+		stop("broadcast failed")
+	}
+	stop("completed")
+
+	// Wait for outstanding txs to be confirmed or expire
+	utils.Outf("{{yellow}}draining outstanding txs:{{/}} %d\n", r.inflight.Load())
+	r.issuerWg.Wait()
+	if board != nil {
+		if err := board.Close(); err != nil {
+			return nil, err
+		}
+	}
+	final := r.collector.Snapshot()
+	if worker != nil {
+		if err := worker.Finish(ctx, stopReason, stopped, final); err != nil {
+			utils.Outf("{{red}}final report to controller failed:{{/}} %v\n", err)
+		}
+	}
+	if blockListener != nil {
+		utils.Outf("{{yellow}}block totals:{{/}} %s\n", blockListener.Totals())
+		if err := blockListener.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if feeRecorder != nil {
+		if err := feeRecorder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if chaosRunner != nil {
+		report := chaosRunner.Report(*r.chaosWindow, *r.chaosRecovery)
+		report.Print()
+		if len(*r.chaosOut) > 0 {
+			if err := report.Write(*r.chaosOut); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if r.ledger != nil {
+		report, err := r.ledger.Reconcile(ctx, hrp, funds, func(ctx context.Context, addr codec.Address) (uint64, error) {
+			saddr, err := codec.AddressBech32(hrp, addr)
+			if err != nil {
+				return 0, err
+			}
+			return tclient.Balance(ctx, saddr, ids.Empty)
+		})
+		if err != nil {
+			return nil, err
+		}
+		report.Print()
+		if len(*r.reconcileOut) > 0 {
+			if err := report.Write(*r.reconcileOut); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sum := summary.New(stopReason, stopped, final)
+	if err := r.finish(sum, env, final, sampler.Samples()); err != nil {
+		return nil, err
+	}
+	if broadcastErr != nil {
+		return sum, fmt.Errorf("%w: broadcast failed", broadcastErr)
+	}
+	return sum, nil
+}
+
+// finish checks the summary against the SLOs, prints and writes it and
+// stores the run if [env] is set.
+func (r *run) finish(sum *summary.Summary, env *results.Environment, final *stats.Snapshot, series []results.Sample) error {
+	summary.SLO{
+		MinSuccessRate: *r.sloSuccessRate,
+		MinTPS:         *r.sloMinTPS,
+		MaxP50:         *r.sloMaxP50,
+		MaxP99:         *r.sloMaxP99,
+		MaxFailures:    *r.sloMaxFailures,
+	}.Check(sum)
+	sum.Print()
+	if len(*r.summaryOut) > 0 {
+		if err := sum.Write(*r.summaryOut); err != nil {
+			return err
+		}
+	}
+	if env != nil {
+		var tags []string
+		if len(*r.resultsTags) > 0 {
+			tags = strings.Split(*r.resultsTags, ",")
+		}
+		run := results.NewRun(r.g.Name, tags, results.Flags(r.fs), env, sum, final, series)
+		if err := results.Append(*r.resultsOut, run); err != nil {
+			return err
+		}
+		utils.Outf("{{yellow}}saved run:{{/}} %s {{yellow}}to:{{/}} %s\n", run.ID, *r.resultsOut)
+	}
+	return nil
+}
+
+// nodeRows describes every issuer for the dashboard.
+func (r *run) nodeRows(clients []*txIssuer, uris []string) []dashboard.Node {
+	nodes := make([]dashboard.Node, len(clients))
+	for i, c := range clients {
+		state, _ := r.health.State(c.uri)
+		c.l.Lock()
+		nodes[i] = dashboard.Node{
+			URI:         uris[c.uri],
+			State:       state.String(),
+			Outstanding: c.outstandingTxs,
+			Reconnects:  c.reconnects,
+			Errors:      c.errors,
+		}
+		c.l.Unlock()
+	}
+	return nodes
+}
+
+func createClient(uri string, networkID uint32, chainID ids.ID) (*trpc.JSONRPCClient, *rpc.WebSocketClient, error) {
+	tclient := trpc.NewJSONRPCClient(uri, networkID, chainID)
+	sc, err := rpc.NewWebSocketClient(uri, rpc.DefaultHandshakeTimeout, pubsub.MaxPendingMessages, pubsub.MaxReadMessageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	sclient := sc
+	return tclient, sclient, nil
+}
+
+func getFactory(priv *PrivateKey) (chain.AuthFactory, error) {
+	return auth.NewED25519Factory(ed25519.PrivateKey(priv.Bytes)), nil
+}
+
+func createAccount() (*PrivateKey, error) { // createAccount
+	p, err := ed25519.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{
+		Address: auth.NewED25519Address(p.PublicKey()),
+		Bytes:   p[:],
+	}, nil
+}
+
+func lookupBalance(tclient *trpc.JSONRPCClient, address string) (uint64, error) {
+	balance, err := tclient.Balance(context.TODO(), address, ids.Empty)
+	if err != nil {
+		return 0, err
+	}
+	utils.Outf(
+		"{{cyan}}address:{{/}} %s {{cyan}}balance:{{/}} %s %s\n",
+		address,
+		utils.FormatBalance(balance, consts.Decimals),
+		consts.Symbol,
+	)
+	return balance, err
+}
+
+func PrintUnitPrices(d fees.Dimensions) {
+	utils.Outf(
+		"{{cyan}}unit prices{{/}} {{yellow}}bandwidth:{{/}} %d {{yellow}}compute:{{/}} %d {{yellow}}storage(read):{{/}} %d {{yellow}}storage(allocate):{{/}} %d {{yellow}}storage(write):{{/}} %d\n",
+		d[fees.Bandwidth],
+		d[fees.Compute],
+		d[fees.StorageRead],
+		d[fees.StorageAllocate],
+		d[fees.StorageWrite],
+	)
+}
+
+func ParseDimensions(d fees.Dimensions) string {
+	return fmt.Sprintf(
+		"bandwidth=%d compute=%d storage(read)=%d storage(allocate)=%d storage(write)=%d",
+		d[fees.Bandwidth],
+		d[fees.Compute],
+		d[fees.StorageRead],
+		d[fees.StorageAllocate],
+		d[fees.StorageWrite],
+	)
+}
+
+type timeModifier struct {
+	Timestamp int64
+}
+
+func (t *timeModifier) Base(b *chain.Base) {
+	b.Timestamp = t.Timestamp
+}
+
+// startIssuer listens for the results of [issuer] and, once [cctx] is done,
+// waits up to the drain timeout for its outstanding txs before closing it.
+func (r *run) startIssuer(cctx context.Context, issuer *txIssuer) {
+	r.issuerWg.Add(1)
+	go r.listen(cctx, issuer, issuer.d)
+	go func() {
+		defer func() {
+			_ = issuer.socket().Close()
+			r.issuerWg.Done()
+		}()
+
+		<-cctx.Done()
+		start := time.Now()
+		for time.Since(start) < *r.drainTimeout {
+			if issuer.socket().Closed() {
+				return
+			}
+			issuer.l.Lock()
+			outstanding := issuer.outstandingTxs
+			issuer.l.Unlock()
+			if outstanding == 0 {
+				return
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+		utils.Outf("{{orange}}issuer shutdown timeout{{/}}\n")
+	}()
+}
+
+// listen records the results [d] delivers for [issuer] until it closes.
+func (r *run) listen(cctx context.Context, issuer *txIssuer, d *rpc.WebSocketClient) {
+	for {
+		txID, dErr, result, err := d.ListenTx(context.TODO())
+		if err != nil {
+			issuer.l.Lock()
+			if cctx.Err() == nil && issuer.d == d {
+				r.health.Failure(issuer.uri, err)
+				issuer.errors++
+			}
+			issuer.l.Unlock()
+			return
+		}
+		issuer.l.Lock()
+		issued, ok := issuer.pending[txID]
+		if !ok && issuer.d != d {
+			// Settled as unknown when [d] was replaced
+			issuer.l.Unlock()
+			continue
+		}
+		issuer.outstandingTxs--
+		if result == nil || !result.Success {
+			issuer.errors++
+		}
+		delete(issuer.pending, txID)
+		issuer.l.Unlock()
+		r.inflight.Add(-1)
+		if r.ledger != nil {
+			r.ledger.Resolved(txID, result)
+		}
+		var latency time.Duration
+		if ok {
+			latency = time.Since(issued)
+		}
+		r.collector.Resolved(stats.Classify(dErr, result), latency)
+		if result != nil {
+			r.collector.Consumed(result)
+			r.health.Success(issuer.uri)
+			if ok {
+				r.health.Observe(issuer.uri, latency)
+			}
+		}
+		r.l.Lock()
+		if result != nil {
+			if result.Success {
+				r.confirmedTxs++
+			} else {
+				utils.Outf("{{orange}}on-chain tx failure:{{/}} %s %t\n", string(result.Error), result.Success)
+			}
+		} else {
+			// We can't error match here because we receive it over the wire.
+			if !strings.Contains(dErr.Error(), rpc.ErrExpired.Error()) {
+				utils.Outf("{{orange}}pre-execute tx failure:{{/}} %v\n", dErr)
+			}
+		}
+		r.totalTxs++
+		r.l.Unlock()
+	}
+}
+
+func getNextRecipient(self int, keys []*PrivateKey) (codec.Address, error) {
+	// Select item from array
+	index := rand.Int() % len(keys)
+	if index == self {
+		index++
+		if index == len(keys) {
+			index = 0
+		}
+	}
+	return keys[index].Address, nil
+}
+
+// Transfer returns a transfer of [amount] to [addr], which is also how the
+// runner funds the accounts.
+func Transfer(addr codec.Address, amount uint64) []chain.Action {
+	return []chain.Action{&actions.Transfer{
+		To:    addr,
+		Asset: ids.Empty,
+		Value: amount,
+	}}
+}
@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// Buckets grow by [bucketGrowth] from [bucketMin] so every recorded
+	// latency is within 5% of its bucket's upper bound.
+	bucketMin    = time.Millisecond
+	bucketGrowth = 1.05
+	numBuckets   = 300 // ~38 minutes
+)
+
+var bounds = func() []time.Duration {
+	b := make([]time.Duration, numBuckets)
+	v := float64(bucketMin)
+	for i := range b {
+		b[i] = time.Duration(v)
+		v *= bucketGrowth
+	}
+	return b
+}()
+
+// Histogram is a fixed-bucket latency histogram. Histograms from different
+// processes can be merged because every histogram uses the same buckets.
+type Histogram struct {
+	Counts []uint64      `json:"counts"`
+	Count  uint64        `json:"count"`
+	Sum    time.Duration `json:"sum"`
+	Min    time.Duration `json:"min"`
+	Max    time.Duration `json:"max"`
+}
+
+func NewHistogram() *Histogram {
+	return &Histogram{Counts: make([]uint64, numBuckets+1)}
+}
+
+func bucket(d time.Duration) int {
+	if d <= bucketMin {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(bucketMin)) / math.Log(bucketGrowth)))
+	return min(i, numBuckets)
+}
+
+func (h *Histogram) Record(d time.Duration) {
+	h.Counts[bucket(d)]++
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+	h.Max = max(h.Max, d)
+	h.Count++
+	h.Sum += d
+}
+
+// Merge adds every sample in [o] to [h].
+func (h *Histogram) Merge(o *Histogram) {
+	if o == nil || o.Count == 0 {
+		return
+	}
+	for i, c := range o.Counts {
+		h.Counts[i] += c
+	}
+	if h.Count == 0 || o.Min < h.Min {
+		h.Min = o.Min
+	}
+	h.Max = max(h.Max, o.Max)
+	h.Count += o.Count
+	h.Sum += o.Sum
+}
+
+// Sub returns the samples recorded in [h] but not in the earlier snapshot
+// [o]. Min and max are only approximate for the difference.
+func (h *Histogram) Sub(o *Histogram) *Histogram {
+	d := h.Clone()
+	if o == nil {
+		return d
+	}
+	for i, c := range o.Counts {
+		d.Counts[i] -= c
+	}
+	d.Count -= o.Count
+	d.Sum -= o.Sum
+	if d.Count == 0 {
+		d.Min, d.Max = 0, 0
+	}
+	return d
+}
+
+func (h *Histogram) Clone() *Histogram {
+	c := *h
+	c.Counts = append([]uint64(nil), h.Counts...)
+	return &c
+}
+
+func (h *Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile returns an upper bound for the [q]th quantile, for q in [0, 1].
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(q * float64(h.Count)))
+	if target == 0 {
+		target = 1
+	}
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen < target {
+			continue
+		}
+		if i >= numBuckets {
+			return h.Max
+		}
+		return min(bounds[i], h.Max)
+	}
+	return h.Max
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func record(samples ...time.Duration) *Histogram {
+	h := NewHistogram()
+	for _, d := range samples {
+		h.Record(d)
+	}
+	return h
+}
+
+// spread is one sample every millisecond from 1ms to [n]ms.
+func spread(n int) []time.Duration {
+	samples := make([]time.Duration, n)
+	for i := range samples {
+		samples[i] = time.Duration(i+1) * time.Millisecond
+	}
+	return samples
+}
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		q       float64
+		// exact is the sample at the quantile. The histogram only keeps its
+		// bucket, so it may answer up to 5% above it, but never above the max.
+		exact time.Duration
+	}{
+		{"p50", spread(100), 0.5, 50 * time.Millisecond},
+		{"p90", spread(100), 0.9, 90 * time.Millisecond},
+		{"p99", spread(100), 0.99, 99 * time.Millisecond},
+		{"p100", spread(100), 1, 100 * time.Millisecond},
+		{"p0", spread(100), 0, time.Millisecond},
+		{"p50 of an even count", spread(10), 0.5, 5 * time.Millisecond},
+		{"p99 of a few", spread(10), 0.99, 10 * time.Millisecond},
+		{"one sample", []time.Duration{37 * time.Millisecond}, 0.5, 37 * time.Millisecond},
+		{"below the first bucket", []time.Duration{500 * time.Microsecond, 500 * time.Microsecond}, 0.5, 500 * time.Microsecond},
+		{"past the last bucket", []time.Duration{time.Second, 2 * time.Hour}, 0.99, 2 * time.Hour},
+		{
+			name:    "skewed",
+			samples: append(spread(98), 10*time.Second, 20*time.Second),
+			q:       0.99,
+			exact:   10 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := record(tt.samples...)
+			got := h.Quantile(tt.q)
+			upper := min(tt.exact+tt.exact/20, h.Max)
+			if got < tt.exact || got > upper {
+				t.Fatalf("got %s, want between %s and %s", got, tt.exact, upper)
+			}
+		})
+	}
+}
+
+func TestQuantileEmpty(t *testing.T) {
+	if got := NewHistogram().Quantile(0.5); got != 0 {
+		t.Fatalf("got %s, want 0", got)
+	}
+}
+
+func TestMergeSub(t *testing.T) {
+	all := record(spread(100)...)
+	low, high := record(spread(50)...), record(spread(100)[50:]...)
+	merged := NewHistogram()
+	merged.Merge(low)
+	merged.Merge(high)
+	if merged.Count != all.Count || merged.Sum != all.Sum || merged.Min != all.Min || merged.Max != all.Max {
+		t.Fatalf("merged %+v, want %+v", merged, all)
+	}
+	for _, q := range []float64{0, 0.5, 0.9, 0.99, 1} {
+		if got, want := merged.Quantile(q), all.Quantile(q); got != want {
+			t.Errorf("merged q%v is %s, want %s", q, got, want)
+		}
+	}
+	d := all.Sub(low)
+	if d.Count != high.Count || d.Sum != high.Sum {
+		t.Fatalf("difference has %d samples summing to %s, want %d and %s", d.Count, d.Sum, high.Count, high.Sum)
+	}
+	if got, want := d.Quantile(0.5), high.Quantile(0.5); got != want {
+		t.Errorf("difference p50 is %s, want %s", got, want)
+	}
+}
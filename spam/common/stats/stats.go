@@ -0,0 +1,223 @@
+// Package stats counts what happens to the txs a spammer issues: how many
+// were sent, how each one ended and how long confirmation took.
+package stats
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/fees"
+	"github.com/AnomalyFi/hypersdk/rpc"
+)
+
+// Category is the outcome of a tx, or the reason it could not be sent.
+type Category string
+
+const (
+	Confirmed Category = "confirmed"
+	// Failed txs were executed but did not succeed.
+	Failed Category = "on-chain failure"
+	// Expired txs were not included before their validity window passed.
+	Expired Category = "expired"
+	// Rejected txs were dropped by the node before execution.
+	Rejected Category = "pre-execute failure"
+	// Unsent txs could not be registered with an issuer.
+	Unsent Category = "register failure"
+	// Unroutable txs were not sent because no node was healthy.
+	Unroutable Category = "no healthy issuer"
+	// Unknown txs were issued on a socket that closed before their result
+	// arrived.
+	Unknown Category = "unknown outcome"
+)
+
+// Classify maps the result of ListenTx to a category.
+func Classify(dErr error, result *chain.Result) Category {
+	switch {
+	case result != nil && result.Success:
+		return Confirmed
+	case result != nil:
+		return Failed
+	case dErr != nil && strings.Contains(dErr.Error(), rpc.ErrExpired.Error()):
+		// We can't error match here because we receive it over the wire.
+		return Expired
+	default:
+		return Rejected
+	}
+}
+
+// Collector is safe for concurrent use.
+type Collector struct {
+	l        sync.Mutex
+	start    time.Time
+	issued   uint64
+	outcomes map[Category]uint64
+	latency  *Histogram
+	fee      uint64
+	units    fees.Dimensions
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		start:    time.Now(),
+		outcomes: map[Category]uint64{},
+		latency:  NewHistogram(),
+	}
+}
+
+// Issued records a tx that was handed to an issuer.
+func (c *Collector) Issued() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.issued++
+}
+
+// Resolved records how an issued tx ended. [latency] is the time from issue
+// to result and is only recorded for confirmed txs; pass 0 if it is unknown.
+func (c *Collector) Resolved(category Category, latency time.Duration) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.outcomes[category]++
+	if category == Confirmed && latency > 0 {
+		c.latency.Record(latency)
+	}
+}
+
+// Consumed records the fee and units an executed tx paid for.
+func (c *Collector) Consumed(result *chain.Result) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.fee += result.Fee
+	for i := range c.units {
+		c.units[i] += result.Units[i]
+	}
+}
+
+// Error records a tx that could not be issued.
+func (c *Collector) Error(category Category) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.outcomes[category]++
+}
+
+// Snapshot is a copy of the cumulative counters at a point in time.
+type Snapshot struct {
+	Time     time.Time           `json:"time"`
+	Elapsed  time.Duration       `json:"elapsed"`
+	Issued   uint64              `json:"issued"`
+	Outcomes map[Category]uint64 `json:"outcomes"`
+	Latency  *Histogram          `json:"latency"`
+	// Fee and Units are consumed by executed txs
+	Fee   uint64          `json:"fee"`
+	Units fees.Dimensions `json:"units"`
+}
+
+func (c *Collector) Snapshot() *Snapshot {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	now := time.Now()
+	outcomes := make(map[Category]uint64, len(c.outcomes))
+	for k, v := range c.outcomes {
+		outcomes[k] = v
+	}
+	return &Snapshot{
+		Time:     now,
+		Elapsed:  now.Sub(c.start),
+		Issued:   c.issued,
+		Outcomes: outcomes,
+		Latency:  c.latency.Clone(),
+		Fee:      c.fee,
+		Units:    c.units,
+	}
+}
+
+// Sub returns what happened between the earlier snapshot [o] and [s].
+func (s *Snapshot) Sub(o *Snapshot) *Snapshot {
+	outcomes := make(map[Category]uint64, len(s.Outcomes))
+	for k, v := range s.Outcomes {
+		if d := v - o.Outcomes[k]; d > 0 {
+			outcomes[k] = d
+		}
+	}
+	var units fees.Dimensions
+	for i := range units {
+		units[i] = s.Units[i] - o.Units[i]
+	}
+	return &Snapshot{
+		Time:     s.Time,
+		Elapsed:  s.Time.Sub(o.Time),
+		Issued:   s.Issued - o.Issued,
+		Outcomes: outcomes,
+		Latency:  s.Latency.Sub(o.Latency),
+		Fee:      s.Fee - o.Fee,
+		Units:    units,
+	}
+}
+
+// Merge adds up snapshots taken by different collectors over the same
+// period, e.g. by several spam processes. Time and Elapsed are the latest of
+// them.
+func Merge(snapshots ...*Snapshot) *Snapshot {
+	m := &Snapshot{
+		Outcomes: map[Category]uint64{},
+		Latency:  NewHistogram(),
+	}
+	for _, s := range snapshots {
+		if s == nil {
+			continue
+		}
+		if s.Time.After(m.Time) {
+			m.Time = s.Time
+		}
+		m.Elapsed = max(m.Elapsed, s.Elapsed)
+		m.Issued += s.Issued
+		for k, v := range s.Outcomes {
+			m.Outcomes[k] += v
+		}
+		m.Latency.Merge(s.Latency)
+		m.Fee += s.Fee
+		for i := range m.Units {
+			m.Units[i] += s.Units[i]
+		}
+	}
+	return m
+}
+
+// Resolved is the number of issued txs that have an outcome.
+func (s *Snapshot) Resolved() uint64 {
+	return s.Outcomes[Confirmed] + s.Outcomes[Failed] + s.Outcomes[Expired] + s.Outcomes[Rejected]
+}
+
+// Errors is the number of txs that did not confirm, including those that
+// could not be sent.
+func (s *Snapshot) Errors() uint64 {
+	var errs uint64
+	for k, v := range s.Outcomes {
+		if k != Confirmed {
+			errs += v
+		}
+	}
+	return errs
+}
+
+// ConfirmedPerSecond is the confirmed throughput over the snapshot.
+func (s *Snapshot) ConfirmedPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Outcomes[Confirmed]) / s.Elapsed.Seconds()
+}
+
+// IssuedPerSecond is the offered load over the snapshot.
+func (s *Snapshot) IssuedPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Issued) / s.Elapsed.Seconds()
+}
@@ -0,0 +1,180 @@
+// Package summary reports how a spam run went once it has drained and checks
+// the result against service level objectives.
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/utils"
+
+	"github.com/AnomalyFi/tools/spam/common/stats"
+)
+
+type Latency struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+type Summary struct {
+	StopReason string `json:"stopReason"`
+	// Issuing is the time from the first tx until issuing stopped
+	Issuing time.Duration `json:"issuing"`
+	// Duration includes draining outstanding txs
+	Duration time.Duration `json:"duration"`
+
+	Issued    uint64 `json:"issued"`
+	Confirmed uint64 `json:"confirmed"`
+	// Outstanding txs were issued but had no result when the drain ended
+	Outstanding uint64 `json:"outstanding"`
+	// Failures are txs that did not confirm, by category
+	Failures map[stats.Category]uint64 `json:"failures"`
+
+	// SuccessRate is confirmed txs over every tx we tried to send
+	SuccessRate  float64 `json:"successRate"`
+	IssuedTPS    float64 `json:"issuedTps"`
+	ConfirmedTPS float64 `json:"confirmedTps"`
+	Latency      Latency `json:"latency"`
+
+	Violations []string `json:"violations"`
+	Passed     bool     `json:"passed"`
+
+	checked bool
+}
+
+// New summarizes a run from the snapshot taken when issuing stopped and the
+// one taken after the drain.
+func New(reason string, stopped, final *stats.Snapshot) *Summary {
+	s := &Summary{
+		StopReason: reason,
+		Issuing:    stopped.Elapsed,
+		Duration:   final.Elapsed,
+		Issued:     final.Issued,
+		Confirmed:  final.Outcomes[stats.Confirmed],
+		Failures:   map[stats.Category]uint64{},
+		Latency: Latency{
+			Mean: final.Latency.Mean(),
+			P50:  final.Latency.Quantile(0.5),
+			P90:  final.Latency.Quantile(0.9),
+			P99:  final.Latency.Quantile(0.99),
+			Max:  final.Latency.Max,
+		},
+		Passed: true,
+	}
+	if resolved := final.Resolved(); resolved < final.Issued {
+		s.Outstanding = final.Issued - resolved
+	}
+	for k, v := range final.Outcomes {
+		if k != stats.Confirmed {
+			s.Failures[k] = v
+		}
+	}
+	if attempts := s.Issued + s.Failures[stats.Unsent] + s.Failures[stats.Unroutable]; attempts > 0 {
+		s.SuccessRate = float64(s.Confirmed) / float64(attempts)
+	}
+	if s.Issuing > 0 {
+		s.IssuedTPS = float64(s.Issued) / s.Issuing.Seconds()
+	}
+	if s.Duration > 0 {
+		s.ConfirmedTPS = float64(s.Confirmed) / s.Duration.Seconds()
+	}
+	return s
+}
+
+// FailureCount is every tx that did not confirm, including outstanding ones.
+func (s *Summary) FailureCount() uint64 {
+	n := s.Outstanding
+	for _, v := range s.Failures {
+		n += v
+	}
+	return n
+}
+
+// SLO is a set of assertions a run must meet. Zero values disable a check.
+type SLO struct {
+	MinSuccessRate float64
+	MinTPS         float64
+	MaxP50         time.Duration
+	MaxP99         time.Duration
+	// MaxFailures is ignored if negative
+	MaxFailures int64
+}
+
+// Check records every violated objective on [s].
+func (o SLO) Check(s *Summary) {
+	s.checked = o.MinSuccessRate > 0 || o.MinTPS > 0 || o.MaxP50 > 0 || o.MaxP99 > 0 || o.MaxFailures >= 0
+	if o.MinSuccessRate > 0 && s.SuccessRate < o.MinSuccessRate {
+		s.violate("success rate %.2f%% below %.2f%%", s.SuccessRate*100, o.MinSuccessRate*100)
+	}
+	if o.MinTPS > 0 && s.ConfirmedTPS < o.MinTPS {
+		s.violate("confirmed tps %.2f below %.2f", s.ConfirmedTPS, o.MinTPS)
+	}
+	if o.MaxP50 > 0 && s.Latency.P50 > o.MaxP50 {
+		s.violate("latency p50 %s above %s", s.Latency.P50, o.MaxP50)
+	}
+	if o.MaxP99 > 0 && s.Latency.P99 > o.MaxP99 {
+		s.violate("latency p99 %s above %s", s.Latency.P99, o.MaxP99)
+	}
+	if o.MaxFailures >= 0 && s.FailureCount() > uint64(o.MaxFailures) {
+		s.violate("%d failed txs, more than %d", s.FailureCount(), o.MaxFailures)
+	}
+}
+
+func (s *Summary) violate(format string, args ...any) {
+	s.Violations = append(s.Violations, fmt.Sprintf(format, args...))
+	s.Passed = false
+}
+
+func (s *Summary) Print() {
+	utils.Outf(
+		"{{cyan}}summary:{{/}} {{yellow}}stopped:{{/}} %s {{yellow}}issuing:{{/}} %s {{yellow}}total:{{/}} %s\n",
+		s.StopReason,
+		s.Issuing.Round(time.Millisecond),
+		s.Duration.Round(time.Millisecond),
+	)
+	utils.Outf(
+		"{{cyan}}summary:{{/}} {{yellow}}issued:{{/}} %d {{yellow}}confirmed:{{/}} %d {{yellow}}outstanding:{{/}} %d {{yellow}}success rate:{{/}} %.2f%% {{yellow}}issued/s:{{/}} %.2f {{yellow}}confirmed/s:{{/}} %.2f\n", //nolint:lll
+		s.Issued,
+		s.Confirmed,
+		s.Outstanding,
+		s.SuccessRate*100,
+		s.IssuedTPS,
+		s.ConfirmedTPS,
+	)
+	utils.Outf(
+		"{{cyan}}summary:{{/}} {{yellow}}latency mean:{{/}} %s {{yellow}}p50:{{/}} %s {{yellow}}p90:{{/}} %s {{yellow}}p99:{{/}} %s {{yellow}}max:{{/}} %s\n",
+		s.Latency.Mean,
+		s.Latency.P50,
+		s.Latency.P90,
+		s.Latency.P99,
+		s.Latency.Max,
+	)
+	failures := make([]string, 0, len(s.Failures))
+	for k, v := range s.Failures {
+		failures = append(failures, fmt.Sprintf("%s=%d", k, v))
+	}
+	sort.Strings(failures)
+	utils.Outf("{{cyan}}summary:{{/}} {{yellow}}failures:{{/}} [%s]\n", strings.Join(failures, " "))
+	for _, v := range s.Violations {
+		utils.Outf("{{red}}SLO violated:{{/}} %s\n", v)
+	}
+	if s.checked && s.Passed {
+		utils.Outf("{{green}}all SLOs met{{/}}\n")
+	}
+}
+
+// Write stores the summary as JSON at [path].
+func (s *Summary) Write(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
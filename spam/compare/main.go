@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AnomalyFi/hypersdk/utils"
+
+	"github.com/AnomalyFi/tools/spam/common/results"
+)
+
+var (
+	store     = flag.String("store", results.DefaultStore, "JSON-lines store the spammers wrote runs to")
+	baseline  = flag.String("baseline", "", "compare against the newest run with this tag instead of a second run")
+	alpha     = flag.Float64("alpha", 0.05, "significance level; differences with a higher p-value are treated as noise")
+	threshold = flag.Float64("threshold", 0.05, "smallest relative change flagged as a regression, e.g. 0.05 for 5%")
+	list      = flag.Bool("list", false, "list the stored runs and exit")
+	out       = flag.String("out", "", "write the comparison to this JSON file")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <base> <run>\n       %s [flags] --baseline <tag> [run]\n", os.Args[0], os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "runs are IDs, unambiguous ID prefixes, latest or tag:<name>; run defaults to latest")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	runs, err := results.Load(*store)
+	if err != nil {
+		panic(err)
+	}
+	if *list {
+		for _, r := range runs {
+			utils.Outf(
+				"{{yellow}}%s{{/}} %s {{yellow}}seq:{{/}} %s {{yellow}}nodes:{{/}} %d {{yellow}}confirmed/s:{{/}} %.2f {{yellow}}p99:{{/}} %s {{yellow}}tags:{{/}} [%s]\n",
+				r.ID,
+				r.Tool,
+				r.SEQVersion(),
+				r.Environment.ClusterSize,
+				r.Summary.ConfirmedTPS,
+				r.Summary.Latency.P99,
+				strings.Join(r.Tags, " "),
+			)
+		}
+		return
+	}
+
+	args := flag.Args()
+	var baseRef, runRef string
+	switch {
+	case len(*baseline) > 0 && len(args) <= 1:
+		baseRef, runRef = "tag:"+*baseline, "latest"
+		if len(args) == 1 {
+			runRef = args[0]
+		}
+	case len(*baseline) == 0 && len(args) == 2:
+		baseRef, runRef = args[0], args[1]
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	base, err := results.Find(runs, baseRef)
+	if err != nil {
+		panic(err)
+	}
+	run, err := results.Find(runs, runRef)
+	if err != nil {
+		panic(err)
+	}
+	if base == run {
+		panic(fmt.Errorf("%s is compared with itself", run.ID))
+	}
+
+	c := results.Compare(base, run, results.Options{Alpha: *alpha, Threshold: *threshold})
+	c.Print()
+	if len(*out) > 0 {
+		if err := c.Write(*out); err != nil {
+			panic(err)
+		}
+	}
+	if len(c.Regressions()) > 0 {
+		os.Exit(1)
+	}
+}
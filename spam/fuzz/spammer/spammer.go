@@ -0,0 +1,280 @@
+// Package spammer is the fuzz spammer, importable so it can also be run as
+// a subcommand of seq-tools.
+package spammer
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/codec"
+	hconsts "github.com/AnomalyFi/hypersdk/consts"
+	"github.com/AnomalyFi/hypersdk/state"
+	"github.com/AnomalyFi/hypersdk/utils"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	"github.com/AnomalyFi/tools/spam/common/runner"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var (
+	ErrMisalignedTime = errors.New("misaligned time")
+	ErrInvalidActor   = errors.New("invalid actor")
+	ErrInvalidSponsor = errors.New("invalid sponsor")
+	ErrInvalidObject  = errors.New("invalid object")
+)
+
+// Generator sends random mixes of transfers and sequencer messages, and an
+// ill-formed tx every 4 txs on average.
+var Generator = runner.Generator{
+	Name:          "fuzz",
+	Accounts:      10,
+	TxsPerAccount: 100,
+	Node:          0,
+	Estimate: func(from codec.Address) []chain.Action {
+		return runner.Transfer(from, 0)
+	},
+	Next: func(rng *rand.Rand, parser chain.Parser, factory chain.AuthFactory, recipient codec.Address, v int) (*runner.Tx, error) {
+		randomValue := rng.Int63()
+		// every 4 txs send a ill formed data
+		if randomValue%4 == 0 {
+			tx, err := GenerateIllFormedTx(parser, factory)
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to generate illformed tx", err)
+			}
+			return &runner.Tx{Raw: tx.bytes}, nil
+		}
+		return &runner.Tx{Actions: generateRandomActions(recipient, randomValue, v)}, nil
+	},
+}
+
+// Main runs the spammer with the command line [args], without the program
+// name.
+func Main(args []string) {
+	runner.Main(Generator, args)
+}
+
+func getSequencerMessage(addr codec.Address, chainID []byte, dataLen int64) []chain.Action {
+	data := make([]byte, dataLen)
+	_, err := rand.Read(data)
+	if err != nil {
+		fmt.Println("error getting random data", err)
+	}
+	return []chain.Action{&actions.SequencerMsg{
+		ChainId:     chainID,
+		Data:        data,
+		FromAddress: addr,
+		RelayerID:   int(dataLen % 10),
+	}}
+}
+
+func generateRandomActions(recipient codec.Address, randomValue int64, v int) []chain.Action {
+	actionsPerTx := randomValue % 16 // max actions per tx is 16
+	actions := make([]chain.Action, 0, actionsPerTx)
+	randomNumSet := []uint64{0, 1, 1, 0, 1, 0, 1, 1, 0, 1, 0, 1, 0, 0, 1, 1}
+	chainIDs := [][]byte{[]byte("nkit"), []byte("everest"), []byte("combator"), []byte("marinedrive")}
+	for i := 0; i < int(actionsPerTx); i++ {
+		if randomNumSet[i] == 0 {
+			actions = append(actions, runner.Transfer(recipient, uint64(v+i))...)
+		} else {
+			actions = append(actions, getSequencerMessage(recipient, chainIDs[i%4], randomValue%1_200_000)...)
+		}
+	}
+	return actions
+}
+
+func GenerateIllFormedTx(
+	parser chain.Parser,
+	authFactory chain.AuthFactory,
+) (*Transaction, error) {
+	now := time.Now().UnixMilli()
+	rules := parser.Rules(now)
+	base := &chain.Base{
+		Timestamp: utils.UnixRMilli(now, rules.GetValidityWindow()),
+		ChainID:   rules.ChainID(),
+		MaxFee:    1 << 15,
+	}
+	tx := NewTx(base, []chain.Action{})
+	randomData := make([]byte, 10000)
+	rand.Read(randomData)
+	tx.digest = randomData
+	actionRegistry, authRegistry := parser.Registry()
+	txs, err := tx.Sign(authFactory, actionRegistry, authRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to sign transaction", err)
+	}
+	return txs, nil
+}
+
+type Transaction struct {
+	Base *chain.Base `json:"base"`
+
+	Actions []chain.Action `json:"actions"`
+	Auth    chain.Auth     `json:"auth"`
+
+	digest    []byte
+	bytes     []byte
+	size      int
+	id        ids.ID
+	stateKeys state.Keys
+}
+
+func NewTx(base *chain.Base, actions []chain.Action) *Transaction {
+	return &Transaction{
+		Base:    base,
+		Actions: actions,
+	}
+}
+
+func (t *Transaction) Digest() ([]byte, error) {
+	if len(t.digest) > 0 {
+		return t.digest, nil
+	}
+	size := t.Base.Size() + hconsts.Uint8Len
+	for _, action := range t.Actions {
+		size += hconsts.ByteLen + action.Size()
+	}
+	p := codec.NewWriter(size, hconsts.NetworkSizeLimit)
+	t.Base.Marshal(p)
+	p.PackByte(uint8(len(t.Actions)))
+	for _, action := range t.Actions {
+		p.PackByte(action.GetTypeID())
+		action.Marshal(p)
+	}
+	return p.Bytes(), p.Err()
+}
+
+func (t *Transaction) Sign(
+	factory chain.AuthFactory,
+	actionRegistry chain.ActionRegistry,
+	authRegistry chain.AuthRegistry,
+) (*Transaction, error) {
+	msg, err := t.Digest()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := factory.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	t.Auth = auth
+
+	// Ensure transaction is fully initialized and correct by reloading it from
+	// bytes
+	size := len(msg) + hconsts.ByteLen + t.Auth.Size()
+	p := codec.NewWriter(size, hconsts.NetworkSizeLimit)
+	if err := t.Marshal(p); err != nil {
+		return nil, err
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	p = codec.NewReader(p.Bytes(), hconsts.MaxInt)
+	return UnmarshalTx(p, actionRegistry, authRegistry)
+}
+
+func (t *Transaction) Marshal(p *codec.Packer) error {
+	if len(t.bytes) > 0 {
+		p.PackFixedBytes(t.bytes)
+		return p.Err()
+	}
+
+	return t.marshalActions(p)
+}
+
+func (t *Transaction) marshalActions(p *codec.Packer) error {
+	t.Base.Marshal(p)
+	p.PackByte(uint8(len(t.Actions)))
+	for _, action := range t.Actions {
+		actionID := action.GetTypeID()
+		p.PackByte(actionID)
+		action.Marshal(p)
+	}
+	authID := t.Auth.GetTypeID()
+	p.PackByte(authID)
+	t.Auth.Marshal(p)
+	return p.Err()
+}
+
+func UnmarshalTx(
+	p *codec.Packer,
+	actionRegistry *codec.TypeParser[chain.Action, bool],
+	authRegistry *codec.TypeParser[chain.Auth, bool],
+) (*Transaction, error) {
+	start := p.Offset()
+	base, err := UnmarshalBase(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal base", err)
+	}
+	actions, err := unmarshalActions(p, actionRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal actions", err)
+	}
+	digest := p.Offset()
+	authType := p.UnpackByte()
+	unmarshalAuth, ok := authRegistry.LookupIndex(authType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d is unknown auth type", ErrInvalidObject, authType)
+	}
+	auth, err := unmarshalAuth(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal auth", err)
+	}
+	if actorType := auth.Actor()[0]; actorType != authType {
+		return nil, fmt.Errorf("%w: actorType (%d) did not match authType (%d)", ErrInvalidActor, actorType, authType)
+	}
+	if sponsorType := auth.Sponsor()[0]; sponsorType != authType {
+		return nil, fmt.Errorf("%w: sponsorType (%d) did not match authType (%d)", ErrInvalidSponsor, sponsorType, authType)
+	}
+
+	var tx Transaction
+	tx.Base = base
+	tx.Actions = actions
+	tx.Auth = auth
+	if err := p.Err(); err != nil {
+		return nil, p.Err()
+	}
+	codecBytes := p.Bytes()
+	tx.digest = codecBytes[start:digest]
+	tx.bytes = codecBytes[start:p.Offset()] // ensure errors handled before grabbing memory
+	tx.size = len(tx.bytes)
+	tx.id = utils.ToID(tx.bytes)
+	return &tx, nil
+}
+
+func unmarshalActions(
+	p *codec.Packer,
+	actionRegistry *codec.TypeParser[chain.Action, bool],
+) ([]chain.Action, error) {
+	actionCount := p.UnpackByte()
+	if actionCount == 0 {
+		return nil, fmt.Errorf("%w: no actions", ErrInvalidObject)
+	}
+	actions := []chain.Action{}
+	for i := uint8(0); i < actionCount; i++ {
+		actionType := p.UnpackByte()
+		unmarshalAction, ok := actionRegistry.LookupIndex(actionType)
+		if !ok {
+			return nil, fmt.Errorf("%w: %d is unknown action type", ErrInvalidObject, actionType)
+		}
+		action, err := unmarshalAction(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not unmarshal action", err)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func UnmarshalBase(p *codec.Packer) (*chain.Base, error) {
+	var base chain.Base
+	base.Timestamp = p.UnpackInt64(true)
+	if base.Timestamp%hconsts.MillisecondsPerSecond != 0 {
+		// TODO: make this modulus configurable
+		return nil, fmt.Errorf("%w: timestamp=%d", ErrMisalignedTime, base.Timestamp)
+	}
+	p.UnpackID(true, &base.ChainID)
+	base.MaxFee = p.UnpackUint64(true)
+	return &base, p.Err()
+}
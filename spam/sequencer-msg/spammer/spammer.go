@@ -0,0 +1,50 @@
+// Package spammer is the sequencer-msg spammer, importable so it can also be run as
+// a subcommand of seq-tools.
+package spammer
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/nodekit-seq/actions"
+	"github.com/AnomalyFi/tools/spam/common/runner"
+)
+
+var chainIDs = [][]byte{[]byte("nkit"), []byte("everest"), []byte("combator"), []byte("marinedrive")}
+
+// Generator sends sequencer messages of random data to random chains.
+var Generator = runner.Generator{
+	Name:          "sequencer-msg",
+	Accounts:      1,
+	TxsPerAccount: 1,
+	Node:          1,
+	Estimate: func(from codec.Address) []chain.Action {
+		return getSequencerMessage(from, chainIDs[0], 300)
+	},
+	Next: func(rng *rand.Rand, _ chain.Parser, _ chain.AuthFactory, recipient codec.Address, _ int) (*runner.Tx, error) {
+		return &runner.Tx{Actions: getSequencerMessage(recipient, chainIDs[rng.Int()%len(chainIDs)], rng.Int63()/1_200_000)}, nil
+	},
+}
+
+// Main runs the spammer with the command line [args], without the program
+// name.
+func Main(args []string) {
+	runner.Main(Generator, args)
+}
+
+// @todo
+func getSequencerMessage(addr codec.Address, chainID []byte, dataLen int64) []chain.Action {
+	data := make([]byte, dataLen)
+	_, err := rand.Read(data)
+	if err != nil {
+		fmt.Println("error getting random data", err)
+	}
+	return []chain.Action{&actions.SequencerMsg{
+		ChainId:     chainID,
+		Data:        data,
+		FromAddress: addr,
+		RelayerID:   int(dataLen % 10),
+	}}
+}
@@ -0,0 +1,32 @@
+// Package spammer is the transfer spammer, importable so it can also be run as
+// a subcommand of seq-tools.
+package spammer
+
+import (
+	"math/rand"
+
+	"github.com/AnomalyFi/hypersdk/chain"
+	"github.com/AnomalyFi/hypersdk/codec"
+	"github.com/AnomalyFi/tools/spam/common/runner"
+)
+
+// Generator sends transfers between the accounts, of one more than the
+// number of transfers to the recipient so far in the round.
+var Generator = runner.Generator{
+	Name:          "transfer",
+	Accounts:      1,
+	TxsPerAccount: 1,
+	Node:          1,
+	Estimate: func(from codec.Address) []chain.Action {
+		return runner.Transfer(from, 0)
+	},
+	Next: func(_ *rand.Rand, _ chain.Parser, _ chain.AuthFactory, recipient codec.Address, v int) (*runner.Tx, error) {
+		return &runner.Tx{Actions: runner.Transfer(recipient, uint64(v))}, nil
+	},
+}
+
+// Main runs the spammer with the command line [args], without the program
+// name.
+func Main(args []string) {
+	runner.Main(Generator, args)
+}